@@ -0,0 +1,15 @@
+package json
+
+import (
+	"github.com/goccy/go-json/internal/runtime"
+)
+
+// SetCodeCacheLimit limits the number of the compiled codes cached for the types whose addresses are out of
+// the range analyzed at startup, and for every combination of the types and the encode or decode options,
+// like FieldNaming and FieldQuery. When a cache reaches the limit, storing a new code evicts an arbitrary one,
+// which is compiled again on the next use. n <= 0 removes the limit, which is the default.
+//
+// The codes of the other types are cached in the arrays indexed by the type addresses, which have the fixed sizes.
+func SetCodeCacheLimit(n int) {
+	runtime.SetCodeCacheLimit(n)
+}
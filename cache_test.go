@@ -0,0 +1,65 @@
+package json_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/goccy/go-json"
+)
+
+type cacheUser struct {
+	UserID int
+	Name   string
+}
+
+func TestCodeCacheLimit(t *testing.T) {
+	json.SetCodeCacheLimit(1)
+	defer json.SetCodeCacheLimit(0)
+
+	policies := []*json.NamingPolicy{json.SnakeCase, json.KebabCase, json.NewNamingPolicy(strings.ToUpper)}
+	expected := []string{
+		`{"user_id":1,"name":"a"}`,
+		`{"user-id":1,"name":"a"}`,
+		`{"USERID":1,"NAME":"a"}`,
+	}
+	for round := 0; round < 2; round++ {
+		for i, policy := range policies {
+			b, err := json.MarshalWithOption(cacheUser{UserID: 1, Name: "a"}, json.FieldNaming(policy))
+			assertErr(t, err)
+			assertEq(t, "encode", expected[i], string(b))
+
+			var v cacheUser
+			assertErr(t, json.UnmarshalWithOption(b, &v, json.DecodeFieldNaming(policy)))
+			assertEq(t, "decode", cacheUser{UserID: 1, Name: "a"}, v)
+		}
+	}
+}
+
+func TestConcurrentCompile(t *testing.T) {
+	type concurrentCompile struct {
+		A []int           `json:"a"`
+		B map[string]bool `json:"b"`
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			b, err := json.Marshal(&concurrentCompile{A: []int{1}})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			var v concurrentCompile
+			if err := json.Unmarshal(b, &v); err != nil {
+				t.Error(err)
+				return
+			}
+			if string(b) != `{"a":[1],"b":null}` || len(v.A) != 1 {
+				t.Errorf("unexpected result %s", b)
+			}
+		}()
+	}
+	wg.Wait()
+}
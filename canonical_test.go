@@ -0,0 +1,160 @@
+package json_test
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/goccy/go-json"
+)
+
+type canonicalEmbedded struct {
+	Z int `json:"z"`
+	B int `json:"b"`
+}
+
+type canonicalMarshaler struct{}
+
+func (canonicalMarshaler) MarshalJSON() ([]byte, error) {
+	return []byte(`{ "y": 1.50, "x": "\u0041" }`), nil
+}
+
+func TestCanonical(t *testing.T) {
+	t.Run("struct keys", func(t *testing.T) {
+		type T struct {
+			C string `json:"c"`
+			A int    `json:"a,omitempty"`
+			canonicalEmbedded
+			Nested struct {
+				Y bool `json:"y"`
+				X bool `json:"x"`
+			} `json:"nested"`
+			E []int `json:"\u00f6"`
+			D int   `json:"\ufb33"`
+		}
+		b, err := json.MarshalWithOption(T{C: "c", E: []int{1}}, json.Canonical())
+		assertErr(t, err)
+		assertEq(t, "struct", "{\"b\":0,\"c\":\"c\",\"nested\":{\"x\":false,\"y\":false},\"z\":0,\"\u00f6\":[1],\"\ufb33\":0}", string(b))
+	})
+	t.Run("map keys", func(t *testing.T) {
+		v := map[string]interface{}{
+			"\u20ac":     1,
+			"\ufb33":     3,
+			"\U0001F600": 2,
+			"b":          map[string]int{"y": 1, "x": 2},
+			"a":          nil,
+		}
+		b, err := json.MarshalWithOption(v, json.Canonical())
+		assertErr(t, err)
+		assertEq(t, "map", "{\"a\":null,\"b\":{\"x\":2,\"y\":1},\"€\":1,\"😀\":2,\"\ufb33\":3}", string(b))
+	})
+	t.Run("numbers", func(t *testing.T) {
+		v := []interface{}{
+			1e21, 1e20, 1e-7, 0.000001, math.Copysign(0, -1), 333333333.33333329,
+			float32(0.1), 4.50, -1.5e-10, uint64(math.MaxUint64), json.Number("1.0e2"),
+		}
+		b, err := json.MarshalWithOption(v, json.Canonical())
+		assertErr(t, err)
+		assertEq(t, "numbers", `[1e+21,100000000000000000000,1e-7,0.000001,0,333333333.3333333,0.1,4.5,-1.5e-10,18446744073709552000,100]`, string(b))
+	})
+	t.Run("integers beyond 2^53", func(t *testing.T) {
+		type T struct {
+			I   int64  `json:"i"`
+			N   int64  `json:"n"`
+			U   uint64 `json:"u"`
+			Max int64  `json:"max"`
+			S   int64  `json:"s,string"`
+		}
+		v := []interface{}{
+			T{I: 9007199254740993, N: -9007199254740993, U: 9007199254740993, Max: 9007199254740992, S: 9007199254740993},
+			json.Number("9007199254740993"), json.Number("-0"),
+		}
+		b, err := json.MarshalWithOption(v, json.Canonical())
+		assertErr(t, err)
+		assertEq(t, "integers", `[{"i":9007199254740992,"max":9007199254740992,"n":-9007199254740992,"s":"9007199254740993","u":9007199254740992},9007199254740992,0]`, string(b))
+
+		b, err = json.Canonicalize([]byte(`[9007199254740993, 18446744073709551615]`))
+		assertErr(t, err)
+		assertEq(t, "canonicalized integers", `[9007199254740992,18446744073709552000]`, string(b))
+	})
+	t.Run("flush threshold", func(t *testing.T) {
+		type E struct {
+			Zed   string `json:"zed"`
+			Alpha int    `json:"alpha"`
+		}
+		type W struct {
+			Zed   []E `json:"zed"`
+			Alpha int `json:"alpha"`
+		}
+		v := W{Zed: make([]E, 100), Alpha: 1}
+		for i := range v.Zed {
+			v.Zed[i] = E{Zed: "z", Alpha: i}
+		}
+		expected, err := json.MarshalWithOption(v, json.Canonical())
+		assertErr(t, err)
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetFlushThreshold(256)
+		assertErr(t, enc.EncodeWithOption(v, json.Canonical()))
+		assertEq(t, "flushed", string(expected)+"\n", buf.String())
+		assertEq(t, "sorted", `{"alpha":1,"zed":[{"alpha":0,"zed":"z"},`, buf.String()[:len(`{"alpha":1,"zed":[{"alpha":0,"zed":"z"},`)])
+	})
+	t.Run("strings", func(t *testing.T) {
+		b, err := json.MarshalWithOption("<\u2028>\x01\t\"\\/é", json.Canonical())
+		assertErr(t, err)
+		assertEq(t, "string", "\"<\u2028>\\u0001\\t\\\"\\\\/é\"", string(b))
+	})
+	t.Run("marshaler", func(t *testing.T) {
+		b, err := json.MarshalWithOption(map[string]interface{}{"m": canonicalMarshaler{}}, json.Canonical())
+		assertErr(t, err)
+		assertEq(t, "marshaler", `{"m":{"x":"A","y":1.5}}`, string(b))
+	})
+	t.Run("indent is ignored", func(t *testing.T) {
+		b, err := json.MarshalIndentWithOption(map[string]int{"b": 1, "a": 2}, "", "  ", json.Canonical())
+		assertErr(t, err)
+		assertEq(t, "indent", `{"a":2,"b":1}`, string(b))
+	})
+	t.Run("same as Canonicalize", func(t *testing.T) {
+		v := map[string]interface{}{
+			"numbers":  []float64{1e30, 4.5, 2e-3, 0.1 + 0.2},
+			"string":   "\u20ac$\u000f\nA'B\"\\\\\"/",
+			"literals": []interface{}{nil, true, false},
+		}
+		marshaled, err := json.Marshal(v)
+		assertErr(t, err)
+		expected, err := json.Canonicalize(marshaled)
+		assertErr(t, err)
+		got, err := json.MarshalWithOption(v, json.Canonical())
+		assertErr(t, err)
+		assertEq(t, "canonical", string(expected), string(got))
+	})
+}
+
+func TestCanonicalize(t *testing.T) {
+	t.Run("rfc8785 sample", func(t *testing.T) {
+		src := `{
+  "numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+  "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+  "literals": [null, true, false]
+}`
+		b, err := json.Canonicalize([]byte(src))
+		assertErr(t, err)
+		assertEq(t, "sample", `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`, string(b))
+	})
+	t.Run("utf16 ordering", func(t *testing.T) {
+		src := `{"\u20ac":"Euro Sign","\r":"Carriage Return","\ufb33":"Hebrew Letter Dalet With Dagesh","1":"One","\ud83d\ude00":"Emoji: Grinning Face","\u0080":"Control","\u00f6":"Latin Small Letter O With Diaeresis"}`
+		b, err := json.Canonicalize([]byte(src))
+		assertErr(t, err)
+		assertEq(t, "order", "{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"ö\":\"Latin Small Letter O With Diaeresis\",\"€\":\"Euro Sign\",\"😀\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}", string(b))
+	})
+	t.Run("duplicate key", func(t *testing.T) {
+		if _, err := json.Canonicalize([]byte(`{"a":1,"a":2}`)); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+	t.Run("invalid json", func(t *testing.T) {
+		if _, err := json.Canonicalize([]byte(`{"a":}`)); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
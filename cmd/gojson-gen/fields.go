@@ -0,0 +1,208 @@
+package main
+
+import (
+	"go/types"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// field is a struct field encoded as an object member.
+type field struct {
+	key       string
+	tagged    bool
+	index     []int
+	path      []*types.Var // the embedded fields to the field, followed by the field itself
+	typ       types.Type
+	omitEmpty bool
+	omitZero  bool
+	quoted    bool
+	noNil     bool
+	// unsupported is the tag option that the generated code can't follow.
+	unsupported string
+}
+
+// typeFields returns the fields of the struct type encoded as the object members
+// in the order of the struct, resolving the embedded fields by the rules of encoding/json.
+func typeFields(t types.Type) []*field {
+	type entry struct {
+		typ   types.Type
+		index []int
+		path  []*types.Var
+	}
+	var (
+		current   []entry
+		next      = []entry{{typ: t}}
+		count     = map[types.Type]int{}
+		nextCount = map[types.Type]int{}
+		visited   = map[types.Type]bool{}
+		fields    []*field
+	)
+	for len(next) > 0 {
+		current, next = next, current[:0]
+		count, nextCount = nextCount, map[types.Type]int{}
+		for _, f := range current {
+			if visited[f.typ] {
+				continue
+			}
+			visited[f.typ] = true
+			st := f.typ.Underlying().(*types.Struct)
+			for i := 0; i < st.NumFields(); i++ {
+				sf := st.Field(i)
+				if sf.Anonymous() {
+					if !sf.Exported() && !isStruct(indirect(sf.Type())) {
+						continue
+					}
+				} else if !sf.Exported() {
+					continue
+				}
+				tag := reflect.StructTag(st.Tag(i)).Get("json")
+				if tag == "-" {
+					continue
+				}
+				opts := strings.Split(tag, ",")
+				key := opts[0]
+				if !isValidTag(key) {
+					key = ""
+				}
+				index := append(append([]int{}, f.index...), i)
+				path := append(append([]*types.Var{}, f.path...), sf)
+				ft := sf.Type()
+				if _, ok := ft.(*types.Pointer); ok {
+					ft = indirect(ft)
+				}
+				if key != "" || !sf.Anonymous() || !isStruct(ft) {
+					fd := &field{
+						key:    key,
+						tagged: key != "",
+						index:  index,
+						path:   path,
+						typ:    sf.Type(),
+					}
+					if fd.key == "" {
+						fd.key = sf.Name()
+					}
+					for _, opt := range opts[1:] {
+						switch opt {
+						case "omitempty":
+							fd.omitEmpty = true
+						case "omitzero":
+							fd.omitZero = true
+						case "string":
+							fd.quoted = isQuotable(ft)
+						case "nonil":
+							fd.noNil = true
+						default:
+							if strings.HasPrefix(opt, "precision=") || strings.HasPrefix(opt, "fmt=") {
+								fd.unsupported = opt
+							}
+						}
+					}
+					fields = append(fields, fd)
+					if count[f.typ] > 1 {
+						// the same type is embedded twice at the same depth, so the field annihilates itself.
+						fields = append(fields, fields[len(fields)-1])
+					}
+					continue
+				}
+				nextCount[ft]++
+				if nextCount[ft] == 1 {
+					next = append(next, entry{typ: ft, index: index, path: path})
+				}
+			}
+		}
+	}
+
+	sort.Slice(fields, func(i, j int) bool {
+		x := fields
+		if x[i].key != x[j].key {
+			return x[i].key < x[j].key
+		}
+		if len(x[i].index) != len(x[j].index) {
+			return len(x[i].index) < len(x[j].index)
+		}
+		if x[i].tagged != x[j].tagged {
+			return x[i].tagged
+		}
+		return lessIndex(x[i].index, x[j].index)
+	})
+	out := fields[:0]
+	for advance, i := 0, 0; i < len(fields); i += advance {
+		fi := fields[i]
+		for advance = 1; i+advance < len(fields); advance++ {
+			if fields[i+advance].key != fi.key {
+				break
+			}
+		}
+		if advance == 1 {
+			out = append(out, fi)
+			continue
+		}
+		if dominant, ok := dominantField(fields[i : i+advance]); ok {
+			out = append(out, dominant)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return lessIndex(out[i].index, out[j].index)
+	})
+	return out
+}
+
+// dominantField returns the field hiding the other fields of the same key.
+func dominantField(fields []*field) (*field, bool) {
+	if len(fields) > 1 && len(fields[0].index) == len(fields[1].index) && fields[0].tagged == fields[1].tagged {
+		return nil, false
+	}
+	return fields[0], true
+}
+
+func lessIndex(x, y []int) bool {
+	for k, xik := range x {
+		if k >= len(y) {
+			return false
+		}
+		if xik != y[k] {
+			return xik < y[k]
+		}
+	}
+	return len(x) < len(y)
+}
+
+func indirect(t types.Type) types.Type {
+	if ptr, ok := t.(*types.Pointer); ok {
+		return ptr.Elem()
+	}
+	return t
+}
+
+func isStruct(t types.Type) bool {
+	_, ok := t.Underlying().(*types.Struct)
+	return ok
+}
+
+// isQuotable reports whether the "string" option of the tag applies to the type.
+func isQuotable(t types.Type) bool {
+	basic, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		return false
+	}
+	return basic.Info()&(types.IsBoolean|types.IsInteger|types.IsFloat|types.IsString) != 0
+}
+
+func isValidTag(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		switch {
+		case strings.ContainsRune("!#$%&()*+-./:<=>?@[]^_{|}~ ", c):
+			// Backslash and quote chars are reserved, but
+			// otherwise any punctuation chars are allowed
+			// in a tag name.
+		case !unicode.IsLetter(c) && !unicode.IsDigit(c):
+			return false
+		}
+	}
+	return true
+}
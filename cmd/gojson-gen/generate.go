@@ -0,0 +1,1039 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/types"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/goccy/go-json"
+)
+
+type generator struct {
+	pkg     *types.Package
+	types   []*types.Named
+	listed  map[*types.Named]bool
+	imports map[string]string // path to name
+
+	// the state of the method being generated.
+	usesErr bool
+	loopNum int
+}
+
+func newGenerator(pkg *types.Package, named []*types.Named) *generator {
+	listed := map[*types.Named]bool{}
+	for _, typ := range named {
+		listed[typ] = true
+	}
+	return &generator{
+		pkg:    pkg,
+		types:  named,
+		listed: listed,
+		imports: map[string]string{
+			"bytes":                    "bytes",
+			"encoding/base64":          "base64",
+			"math":                     "math",
+			"reflect":                  "reflect",
+			"strconv":                  "strconv",
+			"strings":                  "strings",
+			"github.com/goccy/go-json": "json",
+		},
+	}
+}
+
+var fileTmpl = template.Must(template.New("").Parse(`// Code generated by gojson-gen. DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+{{- range .StdImports }}
+	{{ . }}
+{{- end }}
+{{ range .Imports }}
+	{{ . }}
+{{- end }}
+)
+{{ range .Methods }}
+{{ . }}
+{{ end }}
+func gojsonAppendString(b []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c < 0x20 || c >= 0x80 || c == '"' || c == '\\' || c == '<' || c == '>' || c == '&' {
+			bb, _ := json.Marshal(s)
+			return append(b, bb...)
+		}
+	}
+	b = append(b, '"')
+	b = append(b, s...)
+	return append(b, '"')
+}
+
+func gojsonAppendQuotedString(b []byte, s string) []byte {
+	return gojsonAppendString(b, string(gojsonAppendString(nil, s)))
+}
+
+func gojsonAppendFloat(b []byte, f float64, bits int) ([]byte, error) {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return gojsonAppendMarshal(b, f)
+	}
+	abs := math.Abs(f)
+	format := byte('f')
+	if abs != 0 {
+		if bits == 64 && (abs < 1e-6 || abs >= 1e21) || bits == 32 && (float32(abs) < 1e-6 || float32(abs) >= 1e21) {
+			format = 'e'
+		}
+	}
+	return strconv.AppendFloat(b, f, format, -1, bits), nil
+}
+
+func gojsonAppendBytes(b []byte, v []byte) []byte {
+	if v == nil {
+		return append(b, "null"...)
+	}
+	b = append(b, '"')
+	b = append(b, base64.StdEncoding.EncodeToString(v)...)
+	return append(b, '"')
+}
+
+func gojsonAppendMarshal(b []byte, v interface{}) ([]byte, error) {
+	bb, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return append(b, bb...), nil
+}
+
+func gojsonIsNull(data []byte) bool {
+	return string(bytes.TrimSpace(data)) == "null"
+}
+
+func gojsonSpace(data []byte, i int) int {
+	for i < len(data) && (data[i] == ' ' || data[i] == '\t' || data[i] == '\n' || data[i] == '\r') {
+		i++
+	}
+	return i
+}
+
+func gojsonIs(data []byte, i int, c byte) bool {
+	return i < len(data) && data[i] == c
+}
+
+func gojsonIsNullAt(data []byte, i int) bool {
+	return bytes.HasPrefix(data[i:], []byte("null"))
+}
+
+// gojsonError returns the error of Unmarshal for the invalid document,
+// or the type error of the value at data[i] for v.
+func gojsonError(data []byte, i int, v interface{}) error {
+	var any interface{}
+	if err := json.Unmarshal(data, &any); err != nil {
+		return err
+	}
+	kind := "number"
+	switch data[i] {
+	case '{':
+		kind = "object"
+	case '[':
+		kind = "array"
+	case '"':
+		kind = "string"
+	case 't', 'f':
+		kind = "bool"
+	}
+	return &json.UnmarshalTypeError{Value: kind, Type: reflect.TypeOf(v).Elem(), Offset: int64(i)}
+}
+
+// gojsonEnter reads the white spaces and open at data[i], and returns the index after open.
+func gojsonEnter(data []byte, i int, open byte) (int, bool) {
+	i = gojsonSpace(data, i)
+	if !gojsonIs(data, i, open) {
+		return i, false
+	}
+	return i + 1, true
+}
+
+// gojsonMore reads the comma or end after the previous value of the array or the object,
+// and returns the index of the next value, or the index after end.
+func gojsonMore(data []byte, i int, end byte, first bool) (int, bool, error) {
+	i = gojsonSpace(data, i)
+	if gojsonIs(data, i, end) {
+		return i + 1, false, nil
+	}
+	if first {
+		return i, true, nil
+	}
+	if !gojsonIs(data, i, ',') {
+		return 0, false, gojsonError(data, i, nil)
+	}
+	return gojsonSpace(data, i+1), true, nil
+}
+
+// gojsonKey reads the key and the colon, and returns the index of the value.
+func gojsonKey(data []byte, i int) (string, int, error) {
+	key, end, ok := gojsonString(data, i)
+	if !ok {
+		end = gojsonValueEnd(data, i)
+		if !gojsonIs(data, i, '"') || json.Unmarshal(data[i:end], &key) != nil {
+			return "", 0, gojsonError(data, i, nil)
+		}
+	}
+	end = gojsonSpace(data, end)
+	if !gojsonIs(data, end, ':') {
+		return "", 0, gojsonError(data, end, nil)
+	}
+	return key, gojsonSpace(data, end+1), nil
+}
+
+// gojsonEnd checks that only the white spaces follow the value.
+func gojsonEnd(data []byte, i int, v interface{}) error {
+	if gojsonSpace(data, i) < len(data) {
+		return gojsonError(data, i, v)
+	}
+	return nil
+}
+
+// gojsonValueEnd returns the end of the value at data[i] by the strings and the brackets.
+// The value is checked by Unmarshal or json.Valid.
+func gojsonValueEnd(data []byte, i int) int {
+	depth := 0
+	for ; i < len(data); i++ {
+		switch data[i] {
+		case '"':
+			for i++; i < len(data) && data[i] != '"'; i++ {
+				if data[i] == '\\' {
+					i++
+				}
+			}
+			if i >= len(data) {
+				return len(data)
+			}
+		case '{', '[':
+			depth++
+			continue
+		case '}', ']':
+			depth--
+			if depth < 0 {
+				return i
+			}
+		case ',', ':', ' ', '\t', '\n', '\r':
+			if depth == 0 {
+				return i
+			}
+			continue
+		default:
+			continue
+		}
+		if depth == 0 {
+			return i + 1
+		}
+	}
+	return len(data)
+}
+
+// gojsonSkip skips the value of the unknown key.
+func gojsonSkip(data []byte, i int) (int, error) {
+	end := gojsonValueEnd(data, i)
+	if !json.Valid(data[i:end]) {
+		return 0, gojsonError(data, i, nil)
+	}
+	return end, nil
+}
+
+// gojsonDecodeValue decodes the value at data[i] by Unmarshal.
+func gojsonDecodeValue(data []byte, i int, v interface{}) (int, error) {
+	end := gojsonValueEnd(data, i)
+	return end, json.Unmarshal(data[i:end], v)
+}
+
+// gojsonDecodeWith decodes the value at data[i] by the generated UnmarshalJSON of u.
+func gojsonDecodeWith(data []byte, i int, u json.Unmarshaler) (int, error) {
+	end := gojsonValueEnd(data, i)
+	return end, u.UnmarshalJSON(data[i:end])
+}
+
+func gojsonDecodeQuoted(data []byte, i int, v interface{}) (int, error) {
+	end := gojsonValueEnd(data, i)
+	var s *string
+	if err := json.Unmarshal(data[i:end], &s); err != nil || s == nil {
+		return end, err
+	}
+	return end, json.Unmarshal([]byte(*s), v)
+}
+
+// gojsonInt reads the integer at data[i] that fits in bits.
+// The other values, like null and the errors, are decoded by gojsonDecodeValue instead.
+func gojsonInt(data []byte, i, bits int) (int64, int, bool) {
+	j := i
+	if gojsonIs(data, j, '-') {
+		j++
+	}
+	n, end, ok := gojsonUint(data, j, 63)
+	if !ok {
+		return 0, 0, false
+	}
+	v := int64(n)
+	if j > i {
+		v = -v
+	}
+	if bits < 64 && (v < -1<<uint(bits-1) || v >= 1<<uint(bits-1)) {
+		return 0, 0, false
+	}
+	return v, end, true
+}
+
+func gojsonUint(data []byte, i, bits int) (uint64, int, bool) {
+	var n uint64
+	j := i
+	for ; j < len(data) && '0' <= data[j] && data[j] <= '9'; j++ {
+		n = n*10 + uint64(data[j]-'0')
+	}
+	digits := j - i
+	if digits == 0 || digits > 18 || digits > 1 && data[i] == '0' || gojsonIs(data, j, '.') || gojsonIs(data, j, 'e') || gojsonIs(data, j, 'E') {
+		return 0, 0, false
+	}
+	if bits < 64 && n >= 1<<uint(bits) {
+		return 0, 0, false
+	}
+	return n, j, true
+}
+
+func gojsonFloat(data []byte, i int) (float64, int, bool) {
+	end := gojsonNumberEnd(data, i)
+	if end < 0 {
+		return 0, 0, false
+	}
+	f, err := strconv.ParseFloat(string(data[i:end]), 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return f, end, true
+}
+
+// gojsonNumberEnd returns the end of the number at data[i] by the grammar of JSON, or -1.
+func gojsonNumberEnd(data []byte, i int) int {
+	digits := func() int {
+		start := i
+		for i < len(data) && '0' <= data[i] && data[i] <= '9' {
+			i++
+		}
+		return i - start
+	}
+	if gojsonIs(data, i, '-') {
+		i++
+	}
+	if gojsonIs(data, i, '0') {
+		i++
+	} else if digits() == 0 {
+		return -1
+	}
+	if gojsonIs(data, i, '.') {
+		i++
+		if digits() == 0 {
+			return -1
+		}
+	}
+	if gojsonIs(data, i, 'e') || gojsonIs(data, i, 'E') {
+		i++
+		if gojsonIs(data, i, '+') || gojsonIs(data, i, '-') {
+			i++
+		}
+		if digits() == 0 {
+			return -1
+		}
+	}
+	return i
+}
+
+func gojsonBool(data []byte, i int) (bool, int, bool) {
+	if bytes.HasPrefix(data[i:], []byte("true")) {
+		return true, i + 4, true
+	}
+	if bytes.HasPrefix(data[i:], []byte("false")) {
+		return false, i + 5, true
+	}
+	return false, 0, false
+}
+
+// gojsonString reads the string of the printable ASCII characters without escapes at data[i].
+func gojsonString(data []byte, i int) (string, int, bool) {
+	if !gojsonIs(data, i, '"') {
+		return "", 0, false
+	}
+	for j := i + 1; j < len(data); j++ {
+		switch c := data[j]; {
+		case c == '"':
+			return string(data[i+1 : j]), j + 1, true
+		case c < 0x20 || c >= 0x80 || c == '\\':
+			return "", 0, false
+		}
+	}
+	return "", 0, false
+}
+`))
+
+func (g *generator) generate() ([]byte, error) {
+	var methods []string
+	for _, typ := range g.types {
+		fields := typeFields(typ)
+		for _, f := range fields {
+			if f.unsupported != "" {
+				return nil, fmt.Errorf("%s.%s: tag option %q is not supported", typ.Obj().Name(), f.path[len(f.path)-1].Name(), f.unsupported)
+			}
+		}
+		marshal, err := g.marshalMethods(typ, fields)
+		if err != nil {
+			return nil, err
+		}
+		methods = append(methods, marshal, g.unmarshalMethod(typ, fields))
+	}
+	var stdImports, imports []string
+	for path, name := range g.imports {
+		spec := strconv.Quote(path)
+		if name != pathBase(path) {
+			spec = name + " " + spec
+		}
+		if strings.Contains(strings.SplitN(path, "/", 2)[0], ".") {
+			imports = append(imports, spec)
+		} else {
+			stdImports = append(stdImports, spec)
+		}
+	}
+	sort.Strings(stdImports)
+	sort.Strings(imports)
+	var buf bytes.Buffer
+	if err := fileTmpl.Execute(&buf, map[string]interface{}{
+		"Package":    g.pkg.Name(),
+		"StdImports": stdImports,
+		"Imports":    imports,
+		"Methods":    methods,
+	}); err != nil {
+		return nil, err
+	}
+	return formatSource(buf.Bytes())
+}
+
+func formatSource(src []byte) ([]byte, error) {
+	formatted, err := format.Source(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format the generated code: %v\n%s", err, src)
+	}
+	return formatted, nil
+}
+
+func pathBase(path string) string {
+	if name := path[strings.LastIndex(path, "/")+1:]; name != "go-json" {
+		return name
+	}
+	return "json"
+}
+
+// qualifier names the packages in the generated code, and imports them.
+func (g *generator) qualifier(pkg *types.Package) string {
+	if pkg == g.pkg {
+		return ""
+	}
+	if name, exists := g.imports[pkg.Path()]; exists {
+		return name
+	}
+	name := pkg.Name()
+	for _, used := range g.imports {
+		if used == name {
+			name = fmt.Sprintf("%s%d", pkg.Name(), len(g.imports))
+			break
+		}
+	}
+	g.imports[pkg.Path()] = name
+	return name
+}
+
+func (g *generator) typeString(t types.Type) string {
+	return types.TypeString(t, g.qualifier)
+}
+
+func (g *generator) marshalMethods(typ *types.Named, fields []*field) (string, error) {
+	g.usesErr = false
+	g.loopNum = 0
+	name := typ.Obj().Name()
+	var body bytes.Buffer
+	body.WriteString("b = append(b, '{')\n")
+	for _, f := range fields {
+		closes := 0
+		for _, ptr := range g.embeddedPointers(f) {
+			fmt.Fprintf(&body, "if %s != nil {\n", ptr)
+			closes++
+		}
+		expr := g.fieldExpr(f)
+		if cond := g.omitCondition(expr, f); cond != "" {
+			fmt.Fprintf(&body, "if !(%s) {\n", cond)
+			closes++
+		}
+		key, err := json.Marshal(f.key)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&body, "b = append(b, %s...)\n", strconv.Quote(string(key)+":"))
+		g.encode(&body, expr, f.typ, f.quoted, f.noNil)
+		body.WriteString("b = append(b, ',')\n")
+		body.WriteString(strings.Repeat("}\n", closes))
+	}
+	body.WriteString(closeWith('}'))
+	body.WriteString("return b, nil\n")
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// MarshalJSON implements json.Marshaler.\n")
+	fmt.Fprintf(&buf, "func (v %s) MarshalJSON() ([]byte, error) {\n\treturn v.AppendJSON(nil)\n}\n\n", name)
+	fmt.Fprintf(&buf, "// AppendJSON appends the JSON encoding of v to b.\n")
+	fmt.Fprintf(&buf, "func (v *%s) AppendJSON(b []byte) ([]byte, error) {\n", name)
+	if g.usesErr {
+		buf.WriteString("var err error\n")
+	}
+	buf.Write(body.Bytes())
+	buf.WriteString("}\n")
+	return buf.String(), nil
+}
+
+// closeWith replaces the last comma by c, or appends c to the empty object or array.
+func closeWith(c byte) string {
+	return fmt.Sprintf("if b[len(b)-1] == ',' {\nb[len(b)-1] = '%c'\n} else {\nb = append(b, '%c')\n}\n", c, c)
+}
+
+func (g *generator) fieldExpr(f *field) string {
+	names := make([]string, 0, len(f.path)+1)
+	names = append(names, "v")
+	for _, v := range f.path {
+		names = append(names, v.Name())
+	}
+	return strings.Join(names, ".")
+}
+
+// embeddedPointers returns the expressions of the embedded pointers to the field.
+func (g *generator) embeddedPointers(f *field) []string {
+	var ptrs []string
+	expr := "v"
+	for _, v := range f.path[:len(f.path)-1] {
+		expr += "." + v.Name()
+		if _, ok := v.Type().(*types.Pointer); ok {
+			ptrs = append(ptrs, expr)
+		}
+	}
+	return ptrs
+}
+
+func (g *generator) omitCondition(expr string, f *field) string {
+	var conds []string
+	if f.omitZero {
+		conds = append(conds, g.isZero(expr, f.typ))
+	}
+	if f.omitEmpty {
+		if cond := isEmpty(expr, f.typ); cond != "" {
+			conds = append(conds, cond)
+		}
+	}
+	return strings.Join(conds, " || ")
+}
+
+func isEmpty(expr string, t types.Type) string {
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case u.Info()&types.IsBoolean != 0:
+			return "!" + expr
+		case u.Info()&types.IsString != 0:
+			return fmt.Sprintf("len(%s) == 0", expr)
+		case u.Info()&types.IsNumeric != 0:
+			return expr + " == 0"
+		}
+	case *types.Pointer, *types.Interface:
+		return expr + " == nil"
+	case *types.Slice, *types.Map, *types.Array:
+		return fmt.Sprintf("len(%s) == 0", expr)
+	}
+	return ""
+}
+
+func (g *generator) isZero(expr string, t types.Type) string {
+	if _, isPtr := t.Underlying().(*types.Pointer); isPtr && hasMethod(t, "IsZero", false) {
+		// the method of the element type can't be called for nil.
+		return fmt.Sprintf("(%s == nil || %s.IsZero())", expr, expr)
+	}
+	if hasMethod(t, "IsZero", false) {
+		return expr + ".IsZero()"
+	}
+	if hasMethod(t, "IsZero", true) {
+		return fmt.Sprintf("(&%s).IsZero()", expr)
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		if cond := isEmpty(expr, t); cond != "" && u.Info()&types.IsString == 0 {
+			return cond
+		}
+		if u.Info()&types.IsString != 0 {
+			return expr + ` == ""`
+		}
+	case *types.Pointer, *types.Interface, *types.Slice, *types.Map:
+		return expr + " == nil"
+	}
+	g.imports["reflect"] = "reflect"
+	return fmt.Sprintf("reflect.ValueOf(%s).IsZero()", expr)
+}
+
+// hasMethod reports whether the method set of t, or *t if ptr is true, has the method of the name.
+func hasMethod(t types.Type, name string, ptr bool) bool {
+	if ptr {
+		if _, isPtr := t.(*types.Pointer); isPtr {
+			return false
+		}
+		t = types.NewPointer(t)
+	}
+	sel := types.NewMethodSet(t).Lookup(nil, name)
+	if sel == nil {
+		// unexported methods are looked up with the package.
+		return false
+	}
+	return true
+}
+
+func isMarshaler(t types.Type) bool {
+	for _, name := range []string{"MarshalJSON", "MarshalText"} {
+		if hasMethod(t, name, false) || hasMethod(t, name, true) {
+			return true
+		}
+	}
+	return false
+}
+
+// encode writes the statements appending the JSON encoding of expr to b.
+// expr must be addressable.
+func (g *generator) encode(w *bytes.Buffer, expr string, t types.Type, quoted, noNil bool) {
+	if named, ok := t.(*types.Named); ok && g.listed[named] {
+		g.usesErr = true
+		fmt.Fprintf(w, "if b, err = %s.AppendJSON(b); err != nil {\nreturn nil, err\n}\n", expr)
+		return
+	}
+	if isMarshaler(t) {
+		g.fallback(w, expr)
+		return
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		g.encodeBasic(w, expr, u, quoted)
+	case *types.Pointer:
+		if _, ok := t.(*types.Named); ok {
+			g.fallback(w, expr)
+			return
+		}
+		fmt.Fprintf(w, "if %s == nil {\nb = append(b, \"null\"...)\n} else {\n", expr)
+		g.encode(w, "(*"+expr+")", u.Elem(), quoted, false)
+		w.WriteString("}\n")
+	case *types.Slice:
+		null := "null"
+		if noNil {
+			null = "[]"
+		}
+		if isByteSlice(u) {
+			// nonil doesn't apply to the base64 string.
+			fmt.Fprintf(w, "b = gojsonAppendBytes(b, []byte(%s))\n", expr)
+			return
+		}
+		fmt.Fprintf(w, "if %s == nil {\nb = append(b, %q...)\n} else {\n", expr, null)
+		g.encodeElems(w, expr, u.Elem())
+		w.WriteString("}\n")
+	case *types.Array:
+		g.encodeElems(w, expr, u.Elem())
+	case *types.Map:
+		if noNil {
+			fmt.Fprintf(w, "if %s == nil {\nb = append(b, \"{}\"...)\n} else {\n", expr)
+			g.fallback(w, expr)
+			w.WriteString("}\n")
+			return
+		}
+		g.fallback(w, expr)
+	default:
+		g.fallback(w, expr)
+	}
+}
+
+func (g *generator) encodeBasic(w *bytes.Buffer, expr string, t *types.Basic, quoted bool) {
+	var stmt string
+	switch {
+	case t.Info()&types.IsBoolean != 0:
+		stmt = fmt.Sprintf("b = strconv.AppendBool(b, bool(%s))\n", expr)
+	case t.Info()&types.IsInteger != 0 && t.Info()&types.IsUnsigned != 0:
+		stmt = fmt.Sprintf("b = strconv.AppendUint(b, uint64(%s), 10)\n", expr)
+	case t.Info()&types.IsInteger != 0:
+		stmt = fmt.Sprintf("b = strconv.AppendInt(b, int64(%s), 10)\n", expr)
+	case t.Kind() == types.Float32 || t.Kind() == types.Float64:
+		bits := 64
+		if t.Kind() == types.Float32 {
+			bits = 32
+		}
+		g.usesErr = true
+		stmt = fmt.Sprintf("if b, err = gojsonAppendFloat(b, float64(%s), %d); err != nil {\nreturn nil, err\n}\n", expr, bits)
+	case t.Info()&types.IsString != 0:
+		if quoted {
+			fmt.Fprintf(w, "b = gojsonAppendQuotedString(b, string(%s))\n", expr)
+		} else {
+			fmt.Fprintf(w, "b = gojsonAppendString(b, string(%s))\n", expr)
+		}
+		return
+	default:
+		g.fallback(w, expr)
+		return
+	}
+	if quoted {
+		fmt.Fprintf(w, "b = append(b, '\"')\n%sb = append(b, '\"')\n", stmt)
+		return
+	}
+	w.WriteString(stmt)
+}
+
+func (g *generator) encodeElems(w *bytes.Buffer, expr string, elem types.Type) {
+	idx := fmt.Sprintf("i%d", g.loopNum)
+	g.loopNum++
+	fmt.Fprintf(w, "b = append(b, '[')\nfor %s := range %s {\n", idx, expr)
+	g.encode(w, fmt.Sprintf("%s[%s]", expr, idx), elem, false, false)
+	w.WriteString("b = append(b, ',')\n}\n")
+	w.WriteString(closeWith(']'))
+}
+
+func (g *generator) fallback(w *bytes.Buffer, expr string) {
+	g.usesErr = true
+	fmt.Fprintf(w, "if b, err = gojsonAppendMarshal(b, %s); err != nil {\nreturn nil, err\n}\n", expr)
+}
+
+// isByteSlice reports whether the slice is encoded as a base64 string like encoding/json.
+func isByteSlice(t *types.Slice) bool {
+	basic, ok := t.Elem().Underlying().(*types.Basic)
+	return ok && basic.Kind() == types.Uint8 && !isMarshaler(t.Elem())
+}
+
+func (g *generator) unmarshalMethod(typ *types.Named, fields []*field) string {
+	g.loopNum = 0
+	var buf bytes.Buffer
+	name := typ.Obj().Name()
+	fmt.Fprintf(&buf, "// UnmarshalJSON implements json.Unmarshaler.\n")
+	fmt.Fprintf(&buf, "func (v *%s) UnmarshalJSON(data []byte) error {\n", name)
+	buf.WriteString(`if gojsonIsNull(data) {
+return nil
+}
+i, ok := gojsonEnter(data, 0, '{')
+if !ok {
+return gojsonError(data, i, v)
+}
+var (
+more bool
+key string
+err error
+)
+for first := true; ; first = false {
+if i, more, err = gojsonMore(data, i, '}', first); err != nil {
+return err
+}
+if !more {
+break
+}
+if key, i, err = gojsonKey(data, i); err != nil {
+return err
+}
+switch key {
+`)
+	for _, f := range fields {
+		fmt.Fprintf(&buf, "case %s:\n", strconv.Quote(f.key))
+		g.decodeField(&buf, f)
+	}
+	// the keys are matched case-insensitively like go-json if they don't match exactly.
+	buf.WriteString("default:\nswitch strings.ToLower(key) {\n")
+	lowerKeys := map[string]bool{}
+	for _, f := range fields {
+		lower := strings.ToLower(f.key)
+		if lowerKeys[lower] {
+			continue
+		}
+		lowerKeys[lower] = true
+		fmt.Fprintf(&buf, "case %s:\n", strconv.Quote(lower))
+		g.decodeField(&buf, f)
+	}
+	buf.WriteString("default:\nif i, err = gojsonSkip(data, i); err != nil {\nreturn err\n}\n}\n}\n")
+	buf.WriteString("}\nreturn gojsonEnd(data, i, v)\n}\n")
+	return buf.String()
+}
+
+func (g *generator) decodeField(w *bytes.Buffer, f *field) {
+	expr := "v"
+	for _, v := range f.path[:len(f.path)-1] {
+		expr += "." + v.Name()
+		if ptr, ok := v.Type().(*types.Pointer); ok {
+			fmt.Fprintf(w, "if %s == nil {\n%s = new(%s)\n}\n", expr, expr, g.typeString(ptr.Elem()))
+		}
+	}
+	if f.quoted {
+		fmt.Fprintf(w, "if i, err = gojsonDecodeQuoted(data, i, &%s); err != nil {\nreturn err\n}\n", g.fieldExpr(f))
+		return
+	}
+	g.decode(w, g.fieldExpr(f), f.typ)
+}
+
+func isUnmarshaler(t types.Type) bool {
+	for _, name := range []string{"UnmarshalJSON", "UnmarshalText"} {
+		if hasMethod(t, name, false) || hasMethod(t, name, true) {
+			return true
+		}
+	}
+	return false
+}
+
+// decode writes the statements decoding the value at data[i] to expr, and moving i to the end of the value.
+// The scalars, the slices, the arrays and the pointers to them are decoded directly, the generated types
+// by their UnmarshalJSON, and the other values, like null of the scalars and the errors, by Unmarshal.
+// expr must be addressable.
+func (g *generator) decode(w *bytes.Buffer, expr string, t types.Type) {
+	if named, ok := t.(*types.Named); ok && g.listed[named] {
+		fmt.Fprintf(w, "if i, err = gojsonDecodeWith(data, i, &%s); err != nil {\nreturn err\n}\n", expr)
+		return
+	}
+	if isUnmarshaler(t) {
+		g.decodeFallback(w, expr)
+		return
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		g.decodeBasic(w, expr, t, u)
+	case *types.Pointer:
+		if _, ok := t.(*types.Named); ok {
+			g.decodeFallback(w, expr)
+			return
+		}
+		fmt.Fprintf(w, "if gojsonIsNullAt(data, i) {\n%s = nil\ni += 4\n} else {\n", expr)
+		fmt.Fprintf(w, "if %s == nil {\n%s = new(%s)\n}\n", expr, expr, g.typeString(u.Elem()))
+		g.decode(w, "(*"+expr+")", u.Elem())
+		w.WriteString("}\n")
+	case *types.Slice:
+		if isByteSlice(u) {
+			g.decodeFallback(w, expr)
+			return
+		}
+		n := g.loopNum
+		g.loopNum++
+		fmt.Fprintf(w, "if gojsonIsNullAt(data, i) {\n%s = nil\ni += 4\n} else if !gojsonIs(data, i, '[') {\n", expr)
+		g.decodeFallback(w, expr)
+		fmt.Fprintf(w, "} else {\ns%d := %s{}\ni++\n", n, g.typeString(t))
+		g.decodeElems(w, "']'")
+		fmt.Fprintf(w, "var e%d %s\n", n, g.typeString(u.Elem()))
+		g.decode(w, fmt.Sprintf("e%d", n), u.Elem())
+		fmt.Fprintf(w, "s%d = append(s%d, e%d)\n}\n%s = s%d\n}\n", n, n, n, expr, n)
+	case *types.Array:
+		n := g.loopNum
+		g.loopNum++
+		fmt.Fprintf(w, "if !gojsonIs(data, i, '[') {\n")
+		g.decodeFallback(w, expr)
+		fmt.Fprintf(w, "} else {\nn%d := 0\ni++\n", n)
+		g.decodeElems(w, "']'")
+		fmt.Fprintf(w, "if n%d < len(%s) {\n", n, expr)
+		g.decode(w, fmt.Sprintf("%s[n%d]", expr, n), u.Elem())
+		fmt.Fprintf(w, "} else if i, err = gojsonSkip(data, i); err != nil {\nreturn err\n}\nn%d++\n}\n", n)
+		// the rest of the elements are zero like go-json.
+		fmt.Fprintf(w, "for ; n%d < len(%s); n%d++ {\nvar z %s\n%s[n%d] = z\n}\n}\n", n, expr, n, g.typeString(u.Elem()), expr, n)
+	default:
+		g.decodeFallback(w, expr)
+	}
+}
+
+// decodeElems opens the loop over the elements of the array at data[i].
+func (g *generator) decodeElems(w *bytes.Buffer, end string) {
+	fmt.Fprintf(w, "for first := true; ; first = false {\nif i, more, err = gojsonMore(data, i, %s, first); err != nil {\nreturn err\n}\nif !more {\nbreak\n}\n", end)
+}
+
+func (g *generator) decodeBasic(w *bytes.Buffer, expr string, t types.Type, u *types.Basic) {
+	var read string
+	kind := types.Invalid // the type that read returns
+	switch {
+	case u.Info()&types.IsBoolean != 0:
+		read, kind = "gojsonBool(data, i)", types.Bool
+	case u.Info()&types.IsInteger != 0 && u.Info()&types.IsUnsigned != 0:
+		read, kind = fmt.Sprintf("gojsonUint(data, i, %s)", basicBits(u)), types.Uint64
+	case u.Info()&types.IsInteger != 0:
+		read, kind = fmt.Sprintf("gojsonInt(data, i, %s)", basicBits(u)), types.Int64
+	case u.Kind() == types.Float32 || u.Kind() == types.Float64:
+		read, kind = "gojsonFloat(data, i)", types.Float64
+	case u.Info()&types.IsString != 0:
+		read, kind = "gojsonString(data, i)", types.String
+	default:
+		g.decodeFallback(w, expr)
+		return
+	}
+	value := "x"
+	if !types.Identical(t, types.Typ[kind]) {
+		value = fmt.Sprintf("%s(x)", g.typeString(t))
+	}
+	fmt.Fprintf(w, "if x, end, ok := %s; ok {\n%s = %s\ni = end\n} else ", read, expr, value)
+	g.decodeFallback(w, expr)
+}
+
+// basicBits returns the size of the integer type in the generated code.
+func basicBits(t *types.Basic) string {
+	switch t.Kind() {
+	case types.Int8, types.Uint8:
+		return "8"
+	case types.Int16, types.Uint16:
+		return "16"
+	case types.Int32, types.Uint32:
+		return "32"
+	case types.Int, types.Uint, types.Uintptr:
+		return "strconv.IntSize"
+	}
+	return "64"
+}
+
+func (g *generator) decodeFallback(w *bytes.Buffer, expr string) {
+	fmt.Fprintf(w, "if i, err = gojsonDecodeValue(data, i, &%s); err != nil {\nreturn err\n}\n", expr)
+}
+
+var testTmpl = template.Must(template.New("").Parse(`// Code generated by gojson-gen. DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/goccy/go-json"
+)
+{{ range .Types }}
+// gojsonPlain{{ .Name }} has the fields of {{ .Name }} without the generated methods.
+type gojsonPlain{{ .Name }} {{ .Name }}
+{{ end }}
+// TestGojsonGen compares the generated methods with go-json at runtime
+// for the zero value and the sample values of each type.
+func TestGojsonGen(t *testing.T) {
+{{- range .Types }}
+	t.Run("{{ .Name }}", func(t *testing.T) {
+{{- if .EmbedsGenerated }}
+		t.Skip("{{ .Name }} embeds a generated type, so the methods are promoted to gojsonPlain{{ .Name }}")
+{{- else }}
+		var samples [3]{{ .Name }}
+		gojsonGenFill(reflect.ValueOf(&samples[1]).Elem(), 0, true)
+		gojsonGenFill(reflect.ValueOf(&samples[2]).Elem(), 0, false)
+		for _, v := range samples {
+			got, err := v.MarshalJSON()
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected, err := json.Marshal(gojsonPlain{{ .Name }}(v))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, expected) {
+				t.Fatalf("failed to marshal:\ngenerated: %s\nruntime:   %s", got, expected)
+			}
+			var decoded {{ .Name }}
+			if err := decoded.UnmarshalJSON(expected); err != nil {
+				t.Fatal(err)
+			}
+			var plain gojsonPlain{{ .Name }}
+			if err := json.Unmarshal(expected, &plain); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(decoded, {{ .Name }}(plain)) {
+				t.Fatalf("failed to unmarshal %s:\ngenerated: %#v\nruntime:   %#v", expected, decoded, plain)
+			}
+			if broken := bytes.Replace(expected, []byte(",\""), []byte(" \""), 1); !bytes.Equal(broken, expected) {
+				if err := decoded.UnmarshalJSON(broken); err == nil {
+					t.Fatalf("accepted the missing value separator in %s", broken)
+				}
+			}
+			if err := decoded.UnmarshalJSON(expected[:len(expected)-1]); err == nil {
+				t.Fatalf("accepted the truncated %s", expected)
+			}
+		}
+{{- end }}
+	})
+{{- end }}
+}
+
+// gojsonGenFill sets the sample values to the exported fields of v.
+// The pointers are left nil unless ptrs is true.
+func gojsonGenFill(v reflect.Value, depth int, ptrs bool) {
+	if depth > 3 || !v.CanSet() {
+		return
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(int64(-7 - depth))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v.SetUint(uint64(7 + depth))
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(1.5e-7 * float64(depth+1))
+	case reflect.String:
+		v.SetString("<a&b>\"\u2028\u00e9")
+	case reflect.Ptr:
+		if !ptrs {
+			return
+		}
+		v.Set(reflect.New(v.Type().Elem()))
+		gojsonGenFill(v.Elem(), depth+1, ptrs)
+	case reflect.Slice:
+		s := reflect.MakeSlice(v.Type(), 2, 2)
+		for i := 0; i < s.Len(); i++ {
+			gojsonGenFill(s.Index(i), depth+1, ptrs)
+		}
+		v.Set(s)
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			gojsonGenFill(v.Index(i), depth+1, ptrs)
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return
+		}
+		key := reflect.New(v.Type().Key()).Elem()
+		key.SetString("key")
+		elem := reflect.New(v.Type().Elem()).Elem()
+		gojsonGenFill(elem, depth+1, ptrs)
+		m := reflect.MakeMap(v.Type())
+		m.SetMapIndex(key, elem)
+		v.Set(m)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			gojsonGenFill(v.Field(i), depth+1, ptrs)
+		}
+	case reflect.Interface:
+		if v.NumMethod() == 0 {
+			v.Set(reflect.ValueOf("value"))
+		}
+	}
+}
+`))
+
+func (g *generator) generateTest() ([]byte, error) {
+	type testType struct {
+		Name            string
+		EmbedsGenerated bool
+	}
+	var testTypes []testType
+	for _, typ := range g.types {
+		tt := testType{Name: typ.Obj().Name()}
+		for _, f := range typeFields(typ) {
+			for _, v := range f.path[:len(f.path)-1] {
+				if named, ok := indirect(v.Type()).(*types.Named); ok && g.listed[named] {
+					tt.EmbedsGenerated = true
+				}
+			}
+		}
+		testTypes = append(testTypes, tt)
+	}
+	var buf bytes.Buffer
+	if err := testTmpl.Execute(&buf, map[string]interface{}{
+		"Package": g.pkg.Name(),
+		"Types":   testTypes,
+	}); err != nil {
+		return nil, err
+	}
+	return formatSource(buf.Bytes())
+}
@@ -0,0 +1,125 @@
+// gojson-gen generates MarshalJSON and UnmarshalJSON methods for the listed struct types,
+// so that they are encoded without compiling the opcodes at runtime.
+//
+//	gojson-gen -type T1,T2 [-o FILE] [-test] [DIR]
+//
+// The generated methods follow the rules of go-json for the struct fields: the keys and the
+// "-", "omitempty", "omitzero" and "string" options of the tags, and the conflicts of the
+// embedded fields. The values of the types the generator can't specialize, like maps and
+// interfaces, are encoded and decoded by go-json at runtime. The encode and decode options
+// aren't applied to the generated methods; they always behave like Marshal and Unmarshal
+// without options, and the unknown keys are ignored.
+//
+// The generated UnmarshalJSON reads the object and decodes the scalars, the slices, the arrays
+// and the pointers to them directly, and the values of the generated types by their UnmarshalJSON.
+// The other values, like maps, interfaces and the types with their own UnmarshalJSON, and the
+// values that don't match the field types are decoded by Unmarshal at runtime, so that the
+// errors are the ones of Unmarshal.
+//
+// Each type also gets AppendJSON(b []byte) ([]byte, error), which appends the encoding to b
+// without allocating a new buffer.
+//
+// -test also generates a test that compares the generated methods with go-json at runtime
+// for the sample values of the types.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	if err := _main(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "gojson-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func _main(args []string) error {
+	fs := flag.NewFlagSet("gojson-gen", flag.ContinueOnError)
+	typeNames := fs.String("type", "", "comma separated list of the struct types")
+	output := fs.String("o", "gojson_gen.go", "output file name in the package directory")
+	withTest := fs.Bool("test", false, "generate the test comparing the generated methods with go-json at runtime")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *typeNames == "" {
+		fs.Usage()
+		return fmt.Errorf("-type is required")
+	}
+	dir := "."
+	if fs.NArg() > 0 {
+		dir = fs.Arg(0)
+	}
+	pkg, err := loadPackage(dir, *output)
+	if err != nil {
+		return err
+	}
+	var named []*types.Named
+	for _, name := range strings.Split(*typeNames, ",") {
+		obj := pkg.Scope().Lookup(strings.TrimSpace(name))
+		if obj == nil {
+			return fmt.Errorf("type %s is not found in package %s", name, pkg.Name())
+		}
+		typ, ok := obj.Type().(*types.Named)
+		if !ok || obj.(*types.TypeName).IsAlias() {
+			return fmt.Errorf("%s is not a defined type", name)
+		}
+		if _, ok := typ.Underlying().(*types.Struct); !ok {
+			return fmt.Errorf("%s is not a struct type", name)
+		}
+		named = append(named, typ)
+	}
+
+	g := newGenerator(pkg, named)
+	src, err := g.generate()
+	if err != nil {
+		return err
+	}
+	outPath := filepath.Join(dir, *output)
+	if err := ioutil.WriteFile(outPath, src, 0644); err != nil {
+		return err
+	}
+	if !*withTest {
+		return nil
+	}
+	testSrc, err := g.generateTest()
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(strings.TrimSuffix(outPath, ".go")+"_test.go", testSrc, 0644)
+}
+
+// loadPackage type-checks the package in dir from the source, except the previously generated file.
+// It uses go/build and the source importer instead of golang.org/x/tools/go/packages,
+// because go-json has no dependencies and the versions of x/tools require newer Go than go.mod.
+func loadPackage(dir, generated string) (*types.Package, error) {
+	buildPkg, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, name := range buildPkg.GoFiles {
+		if name == generated {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, 0)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	return conf.Check(buildPkg.ImportPath, fset, files, nil)
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping the test building the generated package in short mode")
+	}
+	root, err := filepath.Abs("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "gojson-gen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src, err := ioutil.ReadFile(filepath.Join("testdata", "sample", "sample.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	goMod := "module sample\n\nrequire github.com/goccy/go-json v0.0.0\n\nreplace github.com/goccy/go-json => " + strconv.Quote(root) + "\n"
+	for name, content := range map[string][]byte{
+		"go.mod":    []byte(goMod),
+		"sample.go": src,
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), content, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := _main([]string{"-type", "Sample,Inner,Conflicts", "-test", dir}); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("go", "test", ".")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("failed to test the generated code: %v\n%s", err, out)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "missing type", args: []string{"testdata/sample"}},
+		{name: "unknown type", args: []string{"-type", "Unknown", "testdata/sample"}},
+		{name: "not struct", args: []string{"-type", "Enum", "testdata/sample"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := _main(test.args); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package sample
+
+import "time"
+
+type Inner struct {
+	A int    `json:"a"`
+	B string `json:"b,omitempty"`
+}
+
+type Base struct {
+	ID   int64
+	Name string `json:"name"`
+}
+
+type Conflict1 struct{ X int }
+type Conflict2 struct{ X int }
+
+type Sample struct {
+	*Base
+	Bool       bool           `json:"bool"`
+	Int8       int8           `json:"int8,string"`
+	Uint       uint           `json:"uint"`
+	Float32    float32        `json:"float32"`
+	Float64    float64        `json:"float64,omitempty"`
+	Str        string         `json:"str"`
+	QuotedStr  string         `json:"qstr,string"`
+	PtrInt     *int           `json:"ptr_int"`
+	Bytes      []byte         `json:"bytes"`
+	Ints       []int          `json:"ints"`
+	NoNil      []string       `json:"nonil,nonil"`
+	Matrix     [][2]float64   `json:"matrix"`
+	Inner      Inner          `json:"inner"`
+	Inners     []*Inner       `json:"inners"`
+	Map        map[string]int `json:"map"`
+	Any        interface{}    `json:"any"`
+	Time       time.Time      `json:"time"`
+	ZeroTime   time.Time      `json:"zero_time,omitzero"`
+	ZeroTimeP  *time.Time     `json:"zero_time_p,omitzero"`
+	NoNilBytes []byte         `json:"nonil_bytes,nonil"`
+	QuotedF    float64        `json:"qf,string"`
+	QuotedB    *bool          `json:"qb,string"`
+	Empty      []int          `json:"empty,omitempty"`
+	ZeroArr    [2]int         `json:"zero_arr,omitzero"`
+	Skip       int            `json:"-"`
+	Dash       int            `json:"-,"`
+	unexported int
+}
+
+type Conflicts struct {
+	Conflict1
+	Conflict2
+	Base
+	Y int `json:"name"`
+}
+
+type Enum int
@@ -0,0 +1,70 @@
+// gojson-struct prints the Go struct declarations inferred from the sample JSON documents.
+//
+//	gojson-struct [-name NAME] [-pkg PACKAGE] [FILE...]
+//
+// The samples are read from the files, or the standard input if FILE is omitted or "-".
+// Each input can have multiple documents like JSON Lines, and the shapes of all the documents
+// are merged by json.InferGoTypeWithName, so that the declared types decode all the samples
+// and encode them back.
+//
+// -pkg prints the package clause and the imports, so that the output can be saved as a Go file.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/goccy/go-json"
+)
+
+func main() {
+	if err := _main(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "gojson-struct: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func _main(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("gojson-struct", flag.ContinueOnError)
+	name := fs.String("name", "Root", "name of the root type")
+	pkg := fs.String("pkg", "", "package name printed with the package clause and the imports")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	files := fs.Args()
+	if len(files) == 0 {
+		files = []string{"-"}
+	}
+	samples := make([][]byte, 0, len(files))
+	for _, file := range files {
+		var (
+			sample []byte
+			err    error
+		)
+		if file == "-" {
+			sample, err = ioutil.ReadAll(stdin)
+		} else {
+			sample, err = ioutil.ReadFile(file)
+		}
+		if err != nil {
+			return err
+		}
+		samples = append(samples, sample)
+	}
+	decls, err := json.InferGoTypeWithName(*name, samples...)
+	if err != nil {
+		return err
+	}
+	if *pkg != "" {
+		fmt.Fprintf(stdout, "package %s\n\n", *pkg)
+		if strings.Contains(decls, "time.Time") {
+			fmt.Fprint(stdout, "import \"time\"\n\n")
+		}
+	}
+	_, err = fmt.Fprint(stdout, decls)
+	return err
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestInferFromFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "gojson-struct")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "sample.json")
+	if err := ioutil.WriteFile(file, []byte(`{"id":1,"sent_at":"2020-01-01T00:00:00Z"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var stdout bytes.Buffer
+	stdin := strings.NewReader(`{"id":2,"retry":true}` + "\n" + `{"id":3.5,"sent_at":null}`)
+	if err := _main([]string{"-name", "Event", "-pkg", "webhook", file, "-"}, stdin, &stdout); err != nil {
+		t.Fatal(err)
+	}
+	expected := "package webhook\n\nimport \"time\"\n\n" +
+		"type Event struct {\n" +
+		"\tID     float64    `json:\"id\"`\n" +
+		"\tSentAt *time.Time `json:\"sent_at,omitempty\"`\n" +
+		"\tRetry  *bool      `json:\"retry,omitempty\"`\n" +
+		"}\n"
+	if stdout.String() != expected {
+		t.Fatalf("unexpected output:\n%s", stdout.String())
+	}
+	if _, err := format.Source(stdout.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	if err := _main([]string{"-"}, strings.NewReader(`{"a":`), &stdout); err == nil {
+		t.Fatal("expected syntax error")
+	}
+}
@@ -0,0 +1,404 @@
+// gojson formats, validates, colorizes and queries JSON with the parsing rules of go-json.
+//
+//	gojson fmt [-indent STR] [-compact] [-sort-keys] [FILE...]
+//	gojson validate [FILE...]
+//	gojson color [-indent STR] [-compact] [-scheme SPEC] [-html] [FILE...]
+//	gojson get [-indent STR] [-compact] POINTER [FILE...]
+//	gojson lines [-get POINTER] [-skip-invalid] [FILE...]
+//
+// Every command reads the standard input if FILE is omitted or "-",
+// and processes the JSON values in the input one by one.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-json"
+)
+
+const usage = `usage: gojson <command> [flags] [FILE...]
+
+commands:
+  fmt       indent or compact the JSON values
+  validate  report the syntax errors with line and column
+  color     colorize the JSON values
+  get       print the value referenced by a JSON Pointer
+  lines     normalize JSON Lines ( NDJSON ) into compact lines
+`
+
+type command struct {
+	name string
+	run  func(c *cli, args []string) error
+}
+
+var commands = []command{
+	{name: "fmt", run: (*cli).fmt},
+	{name: "validate", run: (*cli).validate},
+	{name: "color", run: (*cli).color},
+	{name: "get", run: (*cli).get},
+	{name: "lines", run: (*cli).lines},
+}
+
+type cli struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	// failed is set when a command reported an error to stderr and continued.
+	failed bool
+}
+
+// errUsage is returned when the flags of a command are invalid, after the usage is printed.
+var errUsage = fmt.Errorf("invalid usage")
+
+func main() {
+	c := &cli{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr}
+	os.Exit(c.run(os.Args[1:]))
+}
+
+func (c *cli) run(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(c.stderr, usage)
+		return 2
+	}
+	for _, cmd := range commands {
+		if cmd.name != args[0] {
+			continue
+		}
+		if err := cmd.run(c, args[1:]); err != nil {
+			if err == errUsage {
+				return 2
+			}
+			fmt.Fprintf(c.stderr, "gojson %s: %v\n", cmd.name, err)
+			return 1
+		}
+		if c.failed {
+			return 1
+		}
+		return 0
+	}
+	fmt.Fprintf(c.stderr, "gojson: unknown command %q\n\n%s", args[0], usage)
+	return 2
+}
+
+func (c *cli) flagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet("gojson "+name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	return fs
+}
+
+func (c *cli) parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	return nil
+}
+
+// input is a file to process.
+type input struct {
+	name string
+	r    io.Reader
+}
+
+// eachInput calls fn for the files in args, or the standard input if args is empty.
+func (c *cli) eachInput(args []string, fn func(in input) error) error {
+	if len(args) == 0 {
+		args = []string{"-"}
+	}
+	for _, name := range args {
+		if name == "-" {
+			if err := fn(input{name: "<stdin>", r: c.stdin}); err != nil {
+				return err
+			}
+			continue
+		}
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		err = fn(input{name: name, r: f})
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// eachValue calls fn with each top-level JSON value of in.
+func eachValue(in input, fn func(raw json.RawMessage) error) error {
+	dec := json.NewDecoder(in.r)
+	for {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("%s: %v", in.name, err)
+		}
+		if err := fn(raw); err != nil {
+			return fmt.Errorf("%s: %v", in.name, err)
+		}
+	}
+}
+
+// indentFlags registers -indent and -compact, and returns the function formatting a value by them.
+func indentFlags(fs *flag.FlagSet) func(dst *bytes.Buffer, src []byte) error {
+	indent := fs.String("indent", "  ", "indent string")
+	compact := fs.Bool("compact", false, "write the values in compact form")
+	return func(dst *bytes.Buffer, src []byte) error {
+		if *compact {
+			return json.Compact(dst, src)
+		}
+		return json.Indent(dst, src, "", *indent)
+	}
+}
+
+func (c *cli) fmt(args []string) error {
+	fs := c.flagSet("fmt")
+	format := indentFlags(fs)
+	sortKeys := fs.Bool("sort-keys", false, "sort the keys of the objects")
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	return c.eachInput(fs.Args(), func(in input) error {
+		return eachValue(in, func(raw json.RawMessage) error {
+			if *sortKeys {
+				sorted, err := sortObjectKeys(raw)
+				if err != nil {
+					return err
+				}
+				raw = sorted
+			}
+			buf.Reset()
+			if err := format(&buf, raw); err != nil {
+				return err
+			}
+			buf.WriteByte('\n')
+			_, err := c.stdout.Write(buf.Bytes())
+			return err
+		})
+	})
+}
+
+// sortObjectKeys re-encodes raw with the members of the objects sorted by their keys.
+// The numbers are kept as they are written.
+func sortObjectKeys(raw json.RawMessage) (json.RawMessage, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c *cli) validate(args []string) error {
+	fs := c.flagSet("validate")
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+	return c.eachInput(fs.Args(), func(in input) error {
+		// src keeps the consumed input to convert the offset of the error into line and column.
+		var src bytes.Buffer
+		dec := json.NewDecoder(io.TeeReader(in.r, &src))
+		dec.UseNumber()
+		for {
+			var v interface{}
+			err := dec.Decode(&v)
+			if err == io.EOF {
+				return nil
+			}
+			if err == nil {
+				continue
+			}
+			syntaxErr, ok := err.(*json.SyntaxError)
+			if !ok {
+				return fmt.Errorf("%s: %v", in.name, err)
+			}
+			line, column := position(src.Bytes(), syntaxErr.Offset)
+			fmt.Fprintf(c.stderr, "%s:%d:%d: %v\n", in.name, line, column, err)
+			c.failed = true
+			return nil
+		}
+	})
+}
+
+// position returns the 1-based line and column of offset in src.
+func position(src []byte, offset int64) (int, int) {
+	if offset > int64(len(src)) {
+		offset = int64(len(src))
+	}
+	before := src[:offset]
+	line := bytes.Count(before, []byte{'\n'}) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
+
+func (c *cli) color(args []string) error {
+	fs := c.flagSet("color")
+	indent := fs.String("indent", "  ", "indent string")
+	compact := fs.Bool("compact", false, "write the values in compact form")
+	spec := fs.String("scheme", os.Getenv("JQ_COLORS"), "colors in the format of JQ_COLORS")
+	html := fs.Bool("html", false, "wrap the values in HTML <span> elements instead of ANSI colors")
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+	scheme := json.DefaultColorScheme
+	switch {
+	case *html:
+		scheme = json.HTMLScheme
+	case *spec != "":
+		parsed, err := json.ParseColorScheme(*spec)
+		if err != nil {
+			return err
+		}
+		scheme = parsed
+	}
+	if *compact {
+		*indent = ""
+	}
+	var b []byte
+	return c.eachInput(fs.Args(), func(in input) error {
+		return eachValue(in, func(raw json.RawMessage) error {
+			colorized, err := json.ColorizeBytes(b[:0], raw, scheme, "", *indent)
+			if err != nil {
+				return err
+			}
+			b = append(colorized, '\n')
+			_, err = c.stdout.Write(b)
+			return err
+		})
+	})
+}
+
+func (c *cli) get(args []string) error {
+	fs := c.flagSet("get")
+	format := indentFlags(fs)
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(c.stderr, "usage: gojson get [-indent STR] [-compact] POINTER [FILE...]")
+		return errUsage
+	}
+	pointer := fs.Arg(0)
+	var buf bytes.Buffer
+	return c.eachInput(fs.Args()[1:], func(in input) error {
+		return eachValue(in, func(raw json.RawMessage) error {
+			value, err := lookup(raw, pointer)
+			if err != nil {
+				return err
+			}
+			buf.Reset()
+			if err := format(&buf, value); err != nil {
+				return err
+			}
+			buf.WriteByte('\n')
+			_, err = c.stdout.Write(buf.Bytes())
+			return err
+		})
+	})
+}
+
+// lookup returns the value referenced by the RFC 6901 JSON Pointer in raw.
+func lookup(raw json.RawMessage, pointer string) (json.RawMessage, error) {
+	if pointer == "" {
+		return raw, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("invalid JSON Pointer %q", pointer)
+	}
+	unescape := strings.NewReplacer("~1", "/", "~0", "~")
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = unescape.Replace(token)
+		switch firstByte(raw) {
+		case '{':
+			var members map[string]json.RawMessage
+			if err := json.Unmarshal(raw, &members); err != nil {
+				return nil, err
+			}
+			value, exists := members[token]
+			if !exists {
+				return nil, fmt.Errorf("key %q of JSON Pointer %q is not found", token, pointer)
+			}
+			raw = value
+		case '[':
+			var elems []json.RawMessage
+			if err := json.Unmarshal(raw, &elems); err != nil {
+				return nil, err
+			}
+			idx, err := strconv.Atoi(token)
+			if err != nil || idx < 0 || idx >= len(elems) {
+				return nil, fmt.Errorf("index %q of JSON Pointer %q is not found", token, pointer)
+			}
+			raw = elems[idx]
+		default:
+			return nil, fmt.Errorf("token %q of JSON Pointer %q references a scalar value", token, pointer)
+		}
+	}
+	return raw, nil
+}
+
+func firstByte(raw json.RawMessage) byte {
+	trimmed := bytes.TrimLeft(raw, " \t\r\n")
+	if len(trimmed) == 0 {
+		return 0
+	}
+	return trimmed[0]
+}
+
+func (c *cli) lines(args []string) error {
+	fs := c.flagSet("lines")
+	pointer := fs.String("get", "", "write the value referenced by the JSON Pointer instead of the whole line")
+	skipInvalid := fs.Bool("skip-invalid", false, "report the invalid lines and the lines without the -get value, and continue")
+	if err := c.parse(fs, args); err != nil {
+		return err
+	}
+	enc := json.NewLinesEncoder(c.stdout)
+	enc.SetEscapeHTML(false)
+	return c.eachInput(fs.Args(), func(in input) error {
+		dec := json.NewLinesDecoder(in.r)
+		onInvalid := func(err *json.LineError) {
+			fmt.Fprintf(c.stderr, "%s: %v\n", in.name, err)
+			c.failed = true
+		}
+		if *skipInvalid {
+			dec.SkipInvalidLines(onInvalid)
+		}
+		for {
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				if err == io.EOF {
+					return nil
+				}
+				return fmt.Errorf("%s: %v", in.name, err)
+			}
+			value, err := lookup(raw, *pointer)
+			if err != nil {
+				// the line without the value is invalid for -get, like the line failing to parse.
+				lineErr := &json.LineError{Line: dec.Line(), Err: err}
+				if !*skipInvalid {
+					return fmt.Errorf("%s: %v", in.name, lineErr)
+				}
+				onInvalid(lineErr)
+				continue
+			}
+			if err := enc.Encode(value); err != nil {
+				return fmt.Errorf("%s:%d: %v", in.name, dec.Line(), err)
+			}
+		}
+	})
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func runCLI(t *testing.T, stdin string, args ...string) (string, string, int) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	c := &cli{stdin: strings.NewReader(stdin), stdout: &stdout, stderr: &stderr}
+	code := c.run(args)
+	return stdout.String(), stderr.String(), code
+}
+
+func TestCLI(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		stdin  string
+		stdout string
+		stderr string
+		code   int
+	}{
+		{
+			name:   "fmt",
+			args:   []string{"fmt", "-indent", "\t"},
+			stdin:  `{"b":1,"a":[1.50]} [] `,
+			stdout: "{\n\t\"b\": 1,\n\t\"a\": [\n\t\t1.50\n\t]\n}\n[]\n",
+		},
+		{
+			name:   "fmt sort keys",
+			args:   []string{"fmt", "-compact", "-sort-keys"},
+			stdin:  `{"b":1,"a":{"d":"<x>","c":1e3}}`,
+			stdout: `{"a":{"c":1e3,"d":"<x>"},"b":1}` + "\n",
+		},
+		{
+			name:   "fmt syntax error",
+			args:   []string{"fmt"},
+			stdin:  `[1,,2]`,
+			stderr: "gojson fmt: <stdin>:",
+			code:   1,
+		},
+		{
+			name:  "validate",
+			args:  []string{"validate"},
+			stdin: "{\"a\": 1}\n[true]",
+		},
+		{
+			name:   "validate error",
+			args:   []string{"validate"},
+			stdin:  "{\"a\":\n  [1,,2]}",
+			stderr: "<stdin>:2:6: ",
+			code:   1,
+		},
+		{
+			name:   "color html",
+			args:   []string{"color", "-html", "-compact"},
+			stdin:  `{"a":null}`,
+			stdout: `<span class="json-delim">{</span><span class="json-key">"a"</span><span class="json-colon">:</span><span class="json-null">null</span><span class="json-delim">}</span>` + "\n",
+		},
+		{
+			name:   "get",
+			args:   []string{"get", "-compact", "/a~1b/1"},
+			stdin:  `{"a/b":[1,{"c":2}]} {"a/b":[3,4]}`,
+			stdout: "{\"c\":2}\n4\n",
+		},
+		{
+			name:   "get not found",
+			args:   []string{"get", "/x"},
+			stdin:  `{"a":1}`,
+			stderr: `gojson get: <stdin>: key "x" of JSON Pointer "/x" is not found`,
+			code:   1,
+		},
+		{
+			name:   "lines",
+			args:   []string{"lines", "-get", "/a", "-skip-invalid"},
+			stdin:  "{\"a\": {\"b\": 1}}\nbad\n\n{\"a\": 2}\n",
+			stdout: "{\"b\":1}\n2\n",
+			stderr: "<stdin>: json: line 2: ",
+			code:   1,
+		},
+		{
+			name:   "lines pointer not found",
+			args:   []string{"lines", "-get", "/a", "-skip-invalid"},
+			stdin:  "{\"b\": 1}\n{\"a\": 2}\n",
+			stdout: "2\n",
+			stderr: "<stdin>: json: line 1: key \"a\" of JSON Pointer \"/a\" is not found\n",
+			code:   1,
+		},
+		{
+			name:   "lines pointer not found without skip",
+			args:   []string{"lines", "-get", "/a"},
+			stdin:  "{\"b\": 1}\n{\"a\": 2}\n",
+			stderr: "gojson lines: <stdin>: json: line 1: key \"a\"",
+			code:   1,
+		},
+		{
+			name:   "unknown command",
+			args:   []string{"unknown"},
+			stderr: `gojson: unknown command "unknown"`,
+			code:   2,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stdout, stderr, code := runCLI(t, test.stdin, test.args...)
+			if code != test.code {
+				t.Fatalf("expected exit code %d but got %d: %s", test.code, code, stderr)
+			}
+			if stdout != test.stdout {
+				t.Fatalf("expected stdout %q but got %q", test.stdout, stdout)
+			}
+			if !strings.HasPrefix(stderr, test.stderr) {
+				t.Fatalf("expected stderr starting with %q but got %q", test.stderr, stderr)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package json
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/goccy/go-json/internal/encoder"
 )
@@ -46,6 +47,22 @@ func createColorFormat(attr colorAttr) ColorFormat {
 	}
 }
 
+// Color256Format returns the format coloring the foreground by the color n of the ANSI 256-color palette.
+func Color256Format(n uint8) ColorFormat {
+	return ColorFormat{
+		Header: fmt.Sprintf("%s[38;5;%dm", escape, n),
+		Footer: resetColor(),
+	}
+}
+
+// TrueColorFormat returns the format coloring the foreground by the 24-bit RGB color.
+func TrueColorFormat(r, g, b uint8) ColorFormat {
+	return ColorFormat{
+		Header: fmt.Sprintf("%s[38;2;%d;%d;%dm", escape, r, g, b),
+		Footer: resetColor(),
+	}
+}
+
 func wrapColor(attr colorAttr) string {
 	return fmt.Sprintf("%s[%dm", escape, attr)
 }
@@ -65,4 +82,119 @@ var (
 		ObjectKey: createColorFormat(fgHiCyanColor),
 		Null:      createColorFormat(fgBlueColor),
 	}
+
+	// Default256ColorScheme is the scheme for the terminals supporting the ANSI 256-color palette.
+	Default256ColorScheme = &ColorScheme{
+		Int:       Color256Format(141),
+		Uint:      Color256Format(141),
+		Float:     Color256Format(141),
+		Bool:      Color256Format(221),
+		String:    Color256Format(114),
+		Binary:    Color256Format(203),
+		ObjectKey: Color256Format(81),
+		Null:      Color256Format(244),
+	}
+
+	// DefaultTrueColorScheme is the scheme for the terminals supporting 24-bit colors.
+	DefaultTrueColorScheme = &ColorScheme{
+		Int:       TrueColorFormat(209, 154, 102),
+		Uint:      TrueColorFormat(209, 154, 102),
+		Float:     TrueColorFormat(209, 154, 102),
+		Bool:      TrueColorFormat(229, 192, 123),
+		String:    TrueColorFormat(152, 195, 121),
+		Binary:    TrueColorFormat(224, 108, 117),
+		ObjectKey: TrueColorFormat(97, 175, 239),
+		Null:      TrueColorFormat(198, 120, 221),
+	}
+
+	// HTMLScheme wraps the values in <span> elements with the classes prefixed by "json-",
+	// like <span class="json-key">"name"</span>. See NewHTMLScheme for the class names.
+	HTMLScheme = NewHTMLScheme("json-")
 )
+
+// NewHTMLScheme returns the scheme wrapping the values in <span> elements for styling with CSS.
+// The class names are classPrefix followed by int, uint, float, bool, string, binary, key, null,
+// delim, comma and colon. HTML characters in strings are escaped, so the output can be embedded
+// in HTML as is.
+func NewHTMLScheme(classPrefix string) *ColorScheme {
+	format := func(class string) ColorFormat {
+		return ColorFormat{
+			Header: `<span class="` + classPrefix + class + `">`,
+			Footer: "</span>",
+		}
+	}
+	return &ColorScheme{
+		Int:        format("int"),
+		Uint:       format("uint"),
+		Float:      format("float"),
+		Bool:       format("bool"),
+		String:     format("string"),
+		Binary:     format("binary"),
+		ObjectKey:  format("key"),
+		Null:       format("null"),
+		Delim:      format("delim"),
+		Comma:      format("comma"),
+		Colon:      format("colon"),
+		EscapeHTML: true,
+	}
+}
+
+// ParseColorScheme parses spec in the format of the JQ_COLORS environment variable of jq, like "0;90:0;37:0;37:0;37:0;32:1;37:1;37:34;1".
+// spec is the colon separated list of SGR parameters for null, false, true, numbers, strings, arrays, objects and object keys.
+// false and true share the Bool format, which is taken from true unless it is omitted.
+// Numbers set Int, Uint and Float, and strings set String and Binary.
+// arrays and objects share the Delim, Comma and Colon formats, which are taken from objects unless it is omitted.
+// The omitted or empty fields keep the formats of DefaultColorScheme.
+func ParseColorScheme(spec string) (*ColorScheme, error) {
+	scheme := *DefaultColorScheme
+	fields := strings.Split(spec, ":")
+	if len(fields) > 8 {
+		return nil, fmt.Errorf("json: too many fields in color scheme %q", spec)
+	}
+	for i, field := range fields {
+		if field == "" {
+			continue
+		}
+		for _, c := range field {
+			if (c < '0' || '9' < c) && c != ';' {
+				return nil, fmt.Errorf("json: invalid color %q in color scheme %q", field, spec)
+			}
+		}
+		format := ColorFormat{
+			Header: fmt.Sprintf("%s[%sm", escape, field),
+			Footer: resetColor(),
+		}
+		switch i {
+		case 0:
+			scheme.Null = format
+		case 1:
+			if len(fields) < 3 || fields[2] == "" {
+				scheme.Bool = format
+			}
+		case 2:
+			scheme.Bool = format
+		case 3:
+			scheme.Int, scheme.Uint, scheme.Float = format, format, format
+		case 4:
+			scheme.String, scheme.Binary = format, format
+		case 5:
+			if len(fields) < 7 || fields[6] == "" {
+				scheme.Delim, scheme.Comma, scheme.Colon = format, format, format
+			}
+		case 6:
+			scheme.Delim, scheme.Comma, scheme.Colon = format, format, format
+		case 7:
+			scheme.ObjectKey = format
+		}
+	}
+	return &scheme, nil
+}
+
+// ColorizeBytes appends to dst the JSON-encoded src highlighted by scheme, for example to show
+// a JSON file or a RawMessage in a terminal. src is indented like Indent with prefix and indent,
+// or compacted if both of them are empty.
+// The numbers with a fraction or an exponent are colored by the Float format and the other numbers by Int,
+// and the Delim, Comma and Colon formats color the punctuations.
+func ColorizeBytes(dst, src []byte, scheme *ColorScheme, prefix, indent string) ([]byte, error) {
+	return encoder.ColorizeBytes(dst, src, scheme, prefix, indent)
+}
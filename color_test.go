@@ -1,6 +1,7 @@
 package json_test
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/goccy/go-json"
@@ -47,3 +48,118 @@ func TestColorize(t *testing.T) {
 		t.Log("\n" + string(b))
 	})
 }
+
+func TestColorizeBytes(t *testing.T) {
+	scheme := &json.ColorScheme{
+		Int:       json.ColorFormat{Header: "<i>", Footer: "</i>"},
+		Float:     json.ColorFormat{Header: "<f>", Footer: "</f>"},
+		Bool:      json.ColorFormat{Header: "<b>", Footer: "</b>"},
+		String:    json.ColorFormat{Header: "<s>", Footer: "</s>"},
+		ObjectKey: json.ColorFormat{Header: "<k>", Footer: "</k>"},
+		Null:      json.ColorFormat{Header: "<n>", Footer: "</n>"},
+		Delim:     json.ColorFormat{Header: "<d>", Footer: "</d>"},
+		Comma:     json.ColorFormat{Header: "<c>", Footer: "</c>"},
+		Colon:     json.ColorFormat{Header: "<:>", Footer: "</:>"},
+	}
+	src := []byte(` {"a": [1, -2.5e3, true], "b" : {}, "c":"<x>", "d":null} `)
+	t.Run("compact", func(t *testing.T) {
+		b, err := json.ColorizeBytes([]byte("prev:"), src, scheme, "", "")
+		assertErr(t, err)
+		assertEq(t, "colorize",
+			`prev:<d>{</d><k>"a"</k><:>:</:><d>[</d><i>1</i><c>,</c><f>-2.5e3</f><c>,</c><b>true</b><d>]</d><c>,</c>`+
+				`<k>"b"</k><:>:</:><d>{</d><d>}</d><c>,</c><k>"c"</k><:>:</:><s>"<x>"</s><c>,</c><k>"d"</k><:>:</:><n>null</n><d>}</d>`,
+			string(b),
+		)
+	})
+	t.Run("indent", func(t *testing.T) {
+		b, err := json.ColorizeBytes(nil, []byte(`{"a":[1,"s"],"b":[]}`), &json.ColorScheme{
+			Int:    json.ColorFormat{Header: "<i>", Footer: "</i>"},
+			String: json.ColorFormat{Header: "<s>", Footer: "</s>"},
+		}, ">", "  ")
+		assertErr(t, err)
+		expected := `{
+>  "a": [
+>    <i>1</i>,
+>    <s>"s"</s>
+>  ],
+>  "b": []
+>}`
+		assertEq(t, "colorize", expected, string(b))
+	})
+	t.Run("default scheme", func(t *testing.T) {
+		b, err := json.ColorizeBytes(nil, src, json.DefaultColorScheme, "", "\t")
+		assertErr(t, err)
+		t.Log("\n" + string(b))
+	})
+	t.Run("syntax error", func(t *testing.T) {
+		for _, src := range []string{``, `{"a" 1}`, `[1,]`, `{"a":1} x`, `[1`} {
+			_, err := json.ColorizeBytes(nil, []byte(src), json.DefaultColorScheme, "", "")
+			if err == nil {
+				t.Fatalf("expected error for %q", src)
+			}
+		}
+	})
+}
+
+func TestColorSchemes(t *testing.T) {
+	t.Run("html", func(t *testing.T) {
+		v := map[string]interface{}{"<a>": "x&y", "n": nil}
+		b, err := json.MarshalNoEscape(v)
+		assertErr(t, err)
+		b, err = json.ColorizeBytes(nil, b, json.HTMLScheme, "", "")
+		assertErr(t, err)
+		assertEq(t, "colorize",
+			`<span class="json-delim">{</span><span class="json-key">"\u003ca\u003e"</span><span class="json-colon">:</span>`+
+				`<span class="json-string">"x\u0026y"</span><span class="json-comma">,</span>`+
+				`<span class="json-key">"n"</span><span class="json-colon">:</span><span class="json-null">null</span><span class="json-delim">}</span>`,
+			string(b),
+		)
+	})
+	t.Run("html marshal", func(t *testing.T) {
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		assertErr(t, enc.EncodeWithOption(struct {
+			A string `json:"<a>"`
+			B int    `json:"b"`
+		}{A: "<x>", B: 1}, json.Colorize(json.NewHTMLScheme("c-"))))
+		assertEq(t, "marshal",
+			`{<span class="c-key">"\u003ca\u003e"</span>:<span class="c-string">"\u003cx\u003e"</span>,<span class="c-key">"b"</span>:<span class="c-int">1</span>}`+"\n",
+			buf.String(),
+		)
+	})
+	t.Run("256 color", func(t *testing.T) {
+		b, err := json.MarshalWithOption(1, json.Colorize(json.Default256ColorScheme))
+		assertErr(t, err)
+		assertEq(t, "marshal", "\x1b[38;5;141m1\x1b[0m", string(b))
+	})
+	t.Run("true color", func(t *testing.T) {
+		b, err := json.MarshalWithOption(true, json.Colorize(json.DefaultTrueColorScheme))
+		assertErr(t, err)
+		assertEq(t, "marshal", "\x1b[38;2;229;192;123mtrue\x1b[0m", string(b))
+	})
+	t.Run("parse", func(t *testing.T) {
+		scheme, err := json.ParseColorScheme("0;90:0;31::0;37:0;32::1;37:34;1")
+		assertErr(t, err)
+		assertEq(t, "null", "\x1b[0;90m", scheme.Null.Header)
+		assertEq(t, "bool", "\x1b[0;31m", scheme.Bool.Header)
+		assertEq(t, "float", "\x1b[0;37m", scheme.Float.Header)
+		assertEq(t, "binary", "\x1b[0;32m", scheme.Binary.Header)
+		assertEq(t, "delim", "\x1b[1;37m", scheme.Delim.Header)
+		assertEq(t, "key", "\x1b[34;1m", scheme.ObjectKey.Header)
+		assertEq(t, "footer", "\x1b[0m", scheme.ObjectKey.Footer)
+	})
+	t.Run("parse partial", func(t *testing.T) {
+		scheme, err := json.ParseColorScheme("1;31")
+		assertErr(t, err)
+		assertEq(t, "null", "\x1b[1;31m", scheme.Null.Header)
+		assertEq(t, "string", json.DefaultColorScheme.String, scheme.String)
+	})
+	t.Run("parse error", func(t *testing.T) {
+		for _, spec := range []string{"red", "1:2:3:4:5:6:7:8:9", "1;\x1b"} {
+			if _, err := json.ParseColorScheme(spec); err == nil {
+				t.Fatalf("expected error for %q", spec)
+			}
+		}
+	})
+}
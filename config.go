@@ -0,0 +1,95 @@
+package json
+
+import "io"
+
+// Config is a set of options shared by all encoding and decoding of the API frozen from it.
+type Config struct {
+	// FieldNaming derives the object keys of untagged struct fields.
+	// If it is nil, the Go field names are used as is.
+	FieldNaming *NamingPolicy
+
+	// FloatFormat formats floating point numbers when encoding.
+	// If it is nil, they are formatted like encoding/json.
+	FloatFormat *FloatStyle
+}
+
+// A FrozenConfig encodes and decodes values with the options of a Config.
+// It is safe for concurrent use by multiple goroutines.
+type FrozenConfig struct {
+	encodeOptFuncs []EncodeOptionFunc
+	decodeOptFuncs []DecodeOptionFunc
+}
+
+// Froze returns the API that applies the options of c.
+// Changing c after calling Froze doesn't affect the returned API.
+func (c Config) Froze() *FrozenConfig {
+	frozen := &FrozenConfig{}
+	if c.FieldNaming != nil {
+		frozen.encodeOptFuncs = append(frozen.encodeOptFuncs, FieldNaming(c.FieldNaming))
+		frozen.decodeOptFuncs = append(frozen.decodeOptFuncs, DecodeFieldNaming(c.FieldNaming))
+	}
+	if c.FloatFormat != nil {
+		frozen.encodeOptFuncs = append(frozen.encodeOptFuncs, FloatFormat(*c.FloatFormat))
+	}
+	return frozen
+}
+
+// Marshal is like the package-level Marshal but applies the options of the config.
+func (c *FrozenConfig) Marshal(v interface{}) ([]byte, error) {
+	return c.MarshalWithOption(v)
+}
+
+// MarshalWithOption is like Marshal but applies optFuncs after the options of the config.
+func (c *FrozenConfig) MarshalWithOption(v interface{}, optFuncs ...EncodeOptionFunc) ([]byte, error) {
+	return marshal(v, c.encodeOptions(optFuncs)...)
+}
+
+// MarshalIndent is like the package-level MarshalIndent but applies the options of the config.
+func (c *FrozenConfig) MarshalIndent(v interface{}, prefix, indent string) ([]byte, error) {
+	return c.MarshalIndentWithOption(v, prefix, indent)
+}
+
+// MarshalIndentWithOption is like MarshalIndent but applies optFuncs after the options of the config.
+func (c *FrozenConfig) MarshalIndentWithOption(v interface{}, prefix, indent string, optFuncs ...EncodeOptionFunc) ([]byte, error) {
+	return marshalIndent(v, prefix, indent, c.encodeOptions(optFuncs)...)
+}
+
+// Unmarshal is like the package-level Unmarshal but applies the options of the config.
+func (c *FrozenConfig) Unmarshal(data []byte, v interface{}) error {
+	return c.UnmarshalWithOption(data, v)
+}
+
+// UnmarshalWithOption is like Unmarshal but applies optFuncs after the options of the config.
+func (c *FrozenConfig) UnmarshalWithOption(data []byte, v interface{}, optFuncs ...DecodeOptionFunc) error {
+	return unmarshal(data, v, c.decodeOptions(optFuncs)...)
+}
+
+// NewEncoder returns a new encoder that writes to w with the options of the config.
+func (c *FrozenConfig) NewEncoder(w io.Writer) *Encoder {
+	enc := NewEncoder(w)
+	enc.optFuncs = c.encodeOptFuncs
+	return enc
+}
+
+// NewDecoder returns a new decoder that reads from r with the options of the config.
+func (c *FrozenConfig) NewDecoder(r io.Reader) *Decoder {
+	dec := NewDecoder(r)
+	for _, optFunc := range c.decodeOptFuncs {
+		optFunc(dec.s.Option)
+	}
+	return dec
+}
+
+func (c *FrozenConfig) encodeOptions(optFuncs []EncodeOptionFunc) []EncodeOptionFunc {
+	if len(optFuncs) == 0 {
+		return c.encodeOptFuncs
+	}
+	return append(append([]EncodeOptionFunc{}, c.encodeOptFuncs...), optFuncs...)
+}
+
+func (c *FrozenConfig) decodeOptions(optFuncs []DecodeOptionFunc) []DecodeOptionFunc {
+	if len(optFuncs) == 0 {
+		return c.decodeOptFuncs
+	}
+	return append(append([]DecodeOptionFunc{}, c.decodeOptFuncs...), optFuncs...)
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"strconv"
+	"strings"
 	"unsafe"
 
 	"github.com/goccy/go-json/internal/decoder"
@@ -13,7 +15,9 @@ import (
 )
 
 type Decoder struct {
-	s *decoder.Stream
+	s          *decoder.Stream
+	containers []byte
+	elementKey string
 }
 
 const (
@@ -34,16 +38,21 @@ func unmarshal(data []byte, v interface{}, optFuncs ...DecodeOptionFunc) error {
 	if err := validateType(header.typ, uintptr(header.ptr)); err != nil {
 		return err
 	}
-	dec, err := decoder.CompileToGetDecoder(header.typ)
-	if err != nil {
-		return err
-	}
 	ctx := decoder.TakeRuntimeContext()
 	ctx.Buf = src
 	ctx.Option.Flags = 0
 	for _, optFunc := range optFuncs {
 		optFunc(ctx.Option)
 	}
+	if err := validateWithOption(data, ctx.Option); err != nil {
+		decoder.ReleaseRuntimeContext(ctx)
+		return err
+	}
+	dec, err := decoder.CompileToGetDecoderWithOption(header.typ, ctx.Option)
+	if err != nil {
+		decoder.ReleaseRuntimeContext(ctx)
+		return err
+	}
 	cursor, err := dec.Decode(ctx, 0, 0, header.ptr)
 	if err != nil {
 		decoder.ReleaseRuntimeContext(ctx)
@@ -62,10 +71,6 @@ func unmarshalContext(ctx context.Context, data []byte, v interface{}, optFuncs
 	if err := validateType(header.typ, uintptr(header.ptr)); err != nil {
 		return err
 	}
-	dec, err := decoder.CompileToGetDecoder(header.typ)
-	if err != nil {
-		return err
-	}
 	rctx := decoder.TakeRuntimeContext()
 	rctx.Buf = src
 	rctx.Option.Flags = 0
@@ -74,6 +79,15 @@ func unmarshalContext(ctx context.Context, data []byte, v interface{}, optFuncs
 	for _, optFunc := range optFuncs {
 		optFunc(rctx.Option)
 	}
+	if err := validateWithOption(data, rctx.Option); err != nil {
+		decoder.ReleaseRuntimeContext(rctx)
+		return err
+	}
+	dec, err := decoder.CompileToGetDecoderWithOption(header.typ, rctx.Option)
+	if err != nil {
+		decoder.ReleaseRuntimeContext(rctx)
+		return err
+	}
 	cursor, err := dec.Decode(rctx, 0, 0, header.ptr)
 	if err != nil {
 		decoder.ReleaseRuntimeContext(rctx)
@@ -92,17 +106,21 @@ func unmarshalNoEscape(data []byte, v interface{}, optFuncs ...DecodeOptionFunc)
 	if err := validateType(header.typ, uintptr(header.ptr)); err != nil {
 		return err
 	}
-	dec, err := decoder.CompileToGetDecoder(header.typ)
-	if err != nil {
-		return err
-	}
-
 	ctx := decoder.TakeRuntimeContext()
 	ctx.Buf = src
 	ctx.Option.Flags = 0
 	for _, optFunc := range optFuncs {
 		optFunc(ctx.Option)
 	}
+	if err := validateWithOption(data, ctx.Option); err != nil {
+		decoder.ReleaseRuntimeContext(ctx)
+		return err
+	}
+	dec, err := decoder.CompileToGetDecoderWithOption(header.typ, ctx.Option)
+	if err != nil {
+		decoder.ReleaseRuntimeContext(ctx)
+		return err
+	}
 	cursor, err := dec.Decode(ctx, 0, 0, noescape(header.ptr))
 	if err != nil {
 		decoder.ReleaseRuntimeContext(ctx)
@@ -112,6 +130,16 @@ func unmarshalNoEscape(data []byte, v interface{}, optFuncs ...DecodeOptionFunc)
 	return validateEndBuf(src, cursor)
 }
 
+// validateWithOption validates the document by the validator of ValidateSchema.
+// It's a separate pass before the decoding, because the validator takes the whole document
+// and the decoder doesn't build the values that the validator reads.
+func validateWithOption(data []byte, opt *decoder.Option) error {
+	if opt.Flags&decoder.ValidateOption == 0 {
+		return nil
+	}
+	return opt.Validate(data)
+}
+
 func validateEndBuf(src []byte, cursor int64) error {
 	for {
 		switch src[cursor] {
@@ -188,20 +216,37 @@ func (d *Decoder) DecodeWithOption(v interface{}, optFuncs ...DecodeOptionFunc)
 		return err
 	}
 
-	dec, err := decoder.CompileToGetDecoder(typ)
+	s := d.s
+	for _, optFunc := range optFuncs {
+		optFunc(s.Option)
+	}
+	dec, err := decoder.CompileToGetDecoderWithOption(typ, s.Option)
 	if err != nil {
 		return err
 	}
-	if err := d.s.PrepareForDecode(); err != nil {
+	if err := s.PrepareForDecode(); err != nil {
 		return err
 	}
-	s := d.s
-	for _, optFunc := range optFuncs {
-		optFunc(s.Option)
+	if s.Option.Flags&decoder.ValidateOption != 0 {
+		value, err := s.PeekNextValue()
+		if err != nil {
+			return err
+		}
+		if err := s.Option.Validate(value); err != nil {
+			// the invalid value is discarded, so that the next call decodes the next value.
+			if err := s.SkipNextValue(); err != nil {
+				return err
+			}
+			s.Reset()
+			return err
+		}
 	}
-	if err := dec.DecodeStream(s, 0, header.ptr); err != nil {
+	err = dec.DecodeStream(s, 0, header.ptr)
+	s.Option.ResetReferences()
+	if err != nil {
 		return err
 	}
+	s.EndValue()
 	s.Reset()
 	return nil
 }
@@ -230,3 +275,206 @@ func (d *Decoder) InputOffset() int64 {
 func (d *Decoder) UseNumber() {
 	d.s.UseNumber = true
 }
+
+// EnterArray consumes the '[' that begins the next JSON value.
+// Each element can then be read with Decode while More reports true,
+// and Leave consumes the closing ']'.
+// This allows decoding a large array element by element without
+// buffering the whole array.
+func (d *Decoder) EnterArray() error {
+	if err := d.s.EnterContainer('['); err != nil {
+		return err
+	}
+	d.containers = append(d.containers, ']')
+	return nil
+}
+
+// EnterObject consumes the '{' that begins the next JSON value.
+// Each member can then be read with Key and Decode while More reports true,
+// and Leave consumes the closing '}'.
+func (d *Decoder) EnterObject() error {
+	if err := d.s.EnterContainer('{'); err != nil {
+		return err
+	}
+	d.containers = append(d.containers, '}')
+	return nil
+}
+
+// Key reads the next object key inside an object entered by EnterObject.
+func (d *Decoder) Key() (string, error) {
+	if len(d.containers) == 0 || d.containers[len(d.containers)-1] != '}' {
+		return "", fmt.Errorf("json: Decoder.Key called outside of object")
+	}
+	return d.s.ReadKey()
+}
+
+// Leave consumes the end of the array or object entered last by EnterArray or EnterObject.
+func (d *Decoder) Leave() error {
+	if len(d.containers) == 0 {
+		return fmt.Errorf("json: Decoder.Leave called without EnterArray or EnterObject")
+	}
+	if err := d.s.LeaveContainer(d.containers[len(d.containers)-1]); err != nil {
+		return err
+	}
+	d.containers = d.containers[:len(d.containers)-1]
+	d.s.Reset()
+	return nil
+}
+
+// Skip discards the next JSON value.
+func (d *Decoder) Skip() error {
+	if err := d.s.SkipNextValue(); err != nil {
+		return err
+	}
+	d.s.Reset()
+	return nil
+}
+
+// ElementKey returns the key of the object member currently passed to the callback of Elements.
+func (d *Decoder) ElementKey() string {
+	return d.elementKey
+}
+
+// Elements calls fn for each element of the array or object referenced by pointer
+// ( RFC 6901 JSON Pointer, "" means the next top-level value ) without buffering the whole value.
+// fn typically decodes the element by calling dec.Decode once;
+// if fn doesn't consume the element, it is skipped.
+// For object members, the key is available through dec.ElementKey.
+// After the referenced value is consumed, the rest of the enclosing top-level value is skipped.
+func (d *Decoder) Elements(pointer string, fn func(dec *Decoder) error) error {
+	tokens, err := parseJSONPointer(pointer)
+	if err != nil {
+		return err
+	}
+	base := len(d.containers)
+	for _, token := range tokens {
+		if err := d.enterPointerToken(token); err != nil {
+			return err
+		}
+	}
+	c, err := d.s.PeekValue()
+	if err != nil {
+		return err
+	}
+	switch c {
+	case '[':
+		if err := d.EnterArray(); err != nil {
+			return err
+		}
+	case '{':
+		if err := d.EnterObject(); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("json: value at %q is not an array or object", pointer)
+	}
+	isObject := c == '{'
+	for d.More() {
+		if isObject {
+			key, err := d.Key()
+			if err != nil {
+				return err
+			}
+			d.elementKey = key
+		}
+		if _, err := d.s.PeekValue(); err != nil {
+			return err
+		}
+		offset := d.s.TotalOffset()
+		if err := fn(d); err != nil {
+			return err
+		}
+		if d.s.TotalOffset() == offset {
+			if err := d.Skip(); err != nil {
+				return err
+			}
+		}
+	}
+	d.elementKey = ""
+	for len(d.containers) > base {
+		if err := d.skipRest(); err != nil {
+			return err
+		}
+		if err := d.Leave(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Decoder) enterPointerToken(token string) error {
+	c, err := d.s.PeekValue()
+	if err != nil {
+		return err
+	}
+	switch c {
+	case '{':
+		if err := d.EnterObject(); err != nil {
+			return err
+		}
+		for d.More() {
+			key, err := d.Key()
+			if err != nil {
+				return err
+			}
+			if key == token {
+				return nil
+			}
+			if err := d.Skip(); err != nil {
+				return err
+			}
+		}
+	case '[':
+		idx, err := strconv.Atoi(token)
+		if err != nil || idx < 0 {
+			return fmt.Errorf("json: invalid array index %q in JSON Pointer", token)
+		}
+		if err := d.EnterArray(); err != nil {
+			return err
+		}
+		for i := 0; d.More(); i++ {
+			if i == idx {
+				return nil
+			}
+			if err := d.Skip(); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("json: JSON Pointer token %q references a scalar value", token)
+	}
+	return fmt.Errorf("json: JSON Pointer token %q is not found", token)
+}
+
+func (d *Decoder) skipRest() error {
+	isObject := d.containers[len(d.containers)-1] == '}'
+	for d.More() {
+		if isObject {
+			if _, err := d.Key(); err != nil {
+				return err
+			}
+		}
+		if err := d.Skip(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseJSONPointer splits a RFC 6901 JSON Pointer into unescaped reference tokens.
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("json: invalid JSON Pointer %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		if strings.IndexByte(token, '~') < 0 {
+			continue
+		}
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens, nil
+}
@@ -0,0 +1,50 @@
+package json
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"unsafe"
+
+	"github.com/goccy/go-json/internal/decoder"
+	"github.com/goccy/go-json/internal/encoder"
+	"github.com/goccy/go-json/internal/runtime"
+)
+
+// DumpEncoderProgram writes the opcodes that Marshal executes to encode a value of typ to w, one per line:
+//
+//	[index]-op idx:N key:"key" offset:N ptrNum:N type:T flags:A|B next:N end:N nextField:N jmp:T
+//
+// The dashes are the depth of the opcode, idx is the slot of the pointer the opcode reads,
+// offset is the offset of the struct field, and next, end and nextField are the indexes of the jumps.
+// The operands that aren't set are omitted.
+// The program is compiled without the encode options, and it is the program that escapes HTML
+// in the keys like Marshal. The encoders that don't escape HTML, like Encoder with SetEscapeHTML(false),
+// execute the same opcodes with the unescaped keys.
+func DumpEncoderProgram(typ reflect.Type, w io.Writer) error {
+	rtype := runtime.Type2RType(typ)
+	codeSet, err := encoder.CompileToGetCodeSet(uintptr(unsafe.Pointer(rtype)))
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "type: %s\n", typ); err != nil {
+		return err
+	}
+	return codeSet.EscapeKeyCode.DumpProgram(w)
+}
+
+// DumpDecoderTree writes the tree of the decoders that Unmarshal uses to decode into a value of typ to w,
+// like Unmarshal(data, v) for v of *typ. The struct decoders are written with the key matching strategy,
+// which is the bitmaps of the lowercase keys matched by any case of the keys for the small structs
+// or the map lookup of the keys and their lowercase aliases, and their fields with the keys and the offsets.
+// The tree is compiled without the decode options, like Unmarshal without options.
+func DumpDecoderTree(typ reflect.Type, w io.Writer) error {
+	dec, err := decoder.CompileToGetDecoder(runtime.PtrTo(runtime.Type2RType(typ)))
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "type: %s\n", typ); err != nil {
+		return err
+	}
+	return decoder.Dump(w, dec)
+}
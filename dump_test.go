@@ -0,0 +1,116 @@
+package json_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+)
+
+type dumpItem struct {
+	ID   int      `json:"id"`
+	Name string   `json:"name,omitempty"`
+	Tags []string `json:"tags"`
+}
+
+func TestDumpEncoderProgram(t *testing.T) {
+	var buf bytes.Buffer
+	assertErr(t, json.DumpEncoderProgram(reflect.TypeOf(dumpItem{}), &buf))
+	assertEq(t, "program", `type: json_test.dumpItem
+[0]-StructHeadInt idx:0 key:"id" offset:0 type:int flags:Indirect|IsTaggedKey|NilCheck next:1 end:7 nextField:1
+[1]-StructFieldOmitEmptyString idx:0 key:"name" offset:8 type:string flags:Indirect|IsTaggedKey|NilCheck next:2 nextField:2
+[2]-StructFieldSlice idx:0 key:"tags" offset:24 flags:Indirect|IsTaggedKey|NilCheck next:3 nextField:7
+[3]-Slice idx:4 flags:Indirect next:4 end:6
+[4]--String idx:7 type:string flags:Indirect next:5
+[5]-SliceElem idx:4 next:4 end:6
+[6]-SliceEnd idx:9 type:[]string next:7
+[7]-StructEnd idx:10 next:7
+[7]-End idx:10 type:json_test.dumpItem
+`, buf.String())
+
+	// the program is the one that Marshal executes.
+	var trace bytes.Buffer
+	_, err := json.MarshalWithOption(dumpItem{Tags: []string{"a"}}, json.DebugWriter(&trace))
+	assertErr(t, err)
+	for _, line := range strings.Split(strings.TrimSpace(trace.String()), "\n") {
+		executed := line[:strings.LastIndex(line, " out:")]
+		assertEq(t, "executed opcode", true, strings.Contains(buf.String(), executed+"\n"))
+	}
+
+	_, err = json.Marshal(make(chan int))
+	assertNeq(t, "error", nil, err)
+	assertNeq(t, "unsupported", nil, json.DumpEncoderProgram(reflect.TypeOf(make(chan int)), &buf))
+}
+
+func TestDumpDecoderTree(t *testing.T) {
+	var buf bytes.Buffer
+	assertErr(t, json.DumpDecoderTree(reflect.TypeOf(map[string]*dumpItem{}), &buf))
+	assertEq(t, "tree", `type: map[string]*json_test.dumpItem
+map map[string]*json_test.dumpItem
+  key: string
+  value: ptr *json_test.dumpItem
+    struct json_test.dumpItem keyMatching:bitmap(uint8) caseInsensitive
+      field "id" offset:0 tagged
+        int
+      field "name" offset:8 tagged
+        string
+      field "tags" offset:24 tagged
+        slice []string elemSize:16
+          string
+`, buf.String())
+
+	t.Run("case-insensitive keys", func(t *testing.T) {
+		type base struct {
+			X int
+		}
+		type small struct {
+			base
+			Y int
+		}
+		type large struct {
+			base
+			A, B, C, D, E, F, G, H, I, J, K, L, M, N, O, P, Q int
+		}
+		var buf bytes.Buffer
+		assertErr(t, json.DumpDecoderTree(reflect.TypeOf(small{}), &buf))
+		assertEq(t, "small", true, strings.Contains(buf.String(), "keyMatching:bitmap(uint8) caseInsensitive\n"))
+		assertEq(t, "promoted", true, strings.Contains(buf.String(), "field \"X\" offset:0\n"))
+		var s small
+		assertErr(t, json.Unmarshal([]byte(`{"x":1,"y":2}`), &s))
+		assertEq(t, "small value", 1, s.X)
+
+		buf.Reset()
+		assertErr(t, json.DumpDecoderTree(reflect.TypeOf(large{}), &buf))
+		assertEq(t, "large", true, strings.Contains(buf.String(), "keyMatching:map\n"))
+		assertEq(t, "alias", true, strings.Contains(buf.String(), "field \"Q\" offset:136 aliases:\"q\"\n"))
+		assertEq(t, "promoted alias", true, strings.Contains(buf.String(), "field \"X\" offset:0 aliases:\"x\"\n"))
+		var l large
+		assertErr(t, json.Unmarshal([]byte(`{"q":1,"x":2}`), &l))
+		assertEq(t, "large value", 1, l.Q)
+		assertEq(t, "promoted value", 2, l.X)
+	})
+}
+
+func TestDebugWriter(t *testing.T) {
+	var trace bytes.Buffer
+	b, err := json.MarshalWithOption(dumpItem{ID: 1, Tags: []string{"a", "b"}}, json.DebugWriter(&trace))
+	assertErr(t, err)
+	assertEq(t, "encoded", `{"id":1,"tags":["a","b"]}`, string(b))
+	var ops []string
+	for _, line := range strings.Split(strings.TrimSpace(trace.String()), "\n") {
+		ops = append(ops, strings.Fields(line)[0])
+	}
+	assertEq(t, "trace",
+		"[0]-StructHeadInt [1]-StructFieldOmitEmptyString [2]-StructFieldSlice [3]-Slice [4]--String [5]-SliceElem [4]--String [5]-SliceElem [7]-StructEnd [7]-End",
+		strings.Join(ops, " "),
+	)
+	assertEq(t, "output length", true, strings.HasSuffix(trace.String(), " out:26\n"))
+
+	// the writer isn't used by the next encoding without the option.
+	trace.Reset()
+	_, err = json.MarshalWithOption(dumpItem{}, json.Debug())
+	assertErr(t, err)
+	assertEq(t, "trace", "", trace.String())
+}
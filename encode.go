@@ -7,6 +7,7 @@ import (
 
 	"github.com/goccy/go-json/internal/encoder"
 	"github.com/goccy/go-json/internal/encoder/vm"
+	"github.com/goccy/go-json/internal/encoder/vm_canonical"
 	"github.com/goccy/go-json/internal/encoder/vm_color"
 	"github.com/goccy/go-json/internal/encoder/vm_color_indent"
 	"github.com/goccy/go-json/internal/encoder/vm_indent"
@@ -19,6 +20,8 @@ type Encoder struct {
 	enabledHTMLEscape bool
 	prefix            string
 	indentStr         string
+	flushThreshold    int
+	optFuncs          []EncodeOptionFunc
 }
 
 // NewEncoder returns a new encoder that writes to w.
@@ -61,9 +64,19 @@ func (e *Encoder) encodeWithOption(ctx *encoder.RuntimeContext, v interface{}, o
 	if e.enabledHTMLEscape {
 		ctx.Option.Flag |= encoder.HTMLEscapeOption
 	}
+	for _, optFunc := range e.optFuncs {
+		optFunc(ctx.Option)
+	}
 	for _, optFunc := range optFuncs {
 		optFunc(ctx.Option)
 	}
+	if e.flushThreshold > 0 {
+		ctx.FlushWriter = e.w
+		ctx.FlushThreshold = e.flushThreshold
+		defer func() {
+			ctx.FlushWriter = nil
+		}()
+	}
 	var (
 		buf []byte
 		err error
@@ -108,6 +121,16 @@ func (e *Encoder) SetIndent(prefix, indent string) {
 	e.enabledIndent = true
 }
 
+// SetFlushThreshold makes the encoder write encoded data to the underlying io.Writer
+// whenever its internal buffer grows beyond threshold bytes, instead of rendering the whole value in memory first.
+// The buffer is flushed between elements of arrays, slices and unordered maps;
+// sorted maps are always rendered in memory.
+// If encoding fails, part of the value may already have been written.
+// Calling SetFlushThreshold(0) disables incremental flushing.
+func (e *Encoder) SetFlushThreshold(threshold int) {
+	e.flushThreshold = threshold
+}
+
 func marshalContext(ctx context.Context, v interface{}, optFuncs ...EncodeOptionFunc) ([]byte, error) {
 	rctx := encoder.TakeRuntimeContext()
 	rctx.Option.Flag = 0
@@ -220,7 +243,7 @@ func encode(ctx *encoder.RuntimeContext, v interface{}) ([]byte, error) {
 	typ := header.typ
 
 	typeptr := uintptr(unsafe.Pointer(typ))
-	codeSet, err := encoder.CompileToGetCodeSet(typeptr)
+	codeSet, err := encoder.CompileToGetCodeSetWithOption(typeptr, ctx.Option)
 	if err != nil {
 		return nil, err
 	}
@@ -248,7 +271,7 @@ func encodeNoEscape(ctx *encoder.RuntimeContext, v interface{}) ([]byte, error)
 	typ := header.typ
 
 	typeptr := uintptr(unsafe.Pointer(typ))
-	codeSet, err := encoder.CompileToGetCodeSet(typeptr)
+	codeSet, err := encoder.CompileToGetCodeSetWithOption(typeptr, ctx.Option)
 	if err != nil {
 		return nil, err
 	}
@@ -265,6 +288,15 @@ func encodeNoEscape(ctx *encoder.RuntimeContext, v interface{}) ([]byte, error)
 }
 
 func encodeIndent(ctx *encoder.RuntimeContext, v interface{}, prefix, indent string) ([]byte, error) {
+	if (ctx.Option.Flag & encoder.CanonicalOption) != 0 {
+		// canonical output is always compact.
+		// append a newline so that the result ends with ",\n" like indented output.
+		buf, err := encode(ctx, v)
+		if err != nil {
+			return nil, err
+		}
+		return append(buf, '\n'), nil
+	}
 	b := ctx.Buf[:0]
 	if v == nil {
 		b = encoder.AppendNull(ctx, b)
@@ -275,7 +307,7 @@ func encodeIndent(ctx *encoder.RuntimeContext, v interface{}, prefix, indent str
 	typ := header.typ
 
 	typeptr := uintptr(unsafe.Pointer(typ))
-	codeSet, err := encoder.CompileToGetCodeSet(typeptr)
+	codeSet, err := encoder.CompileToGetCodeSetWithOption(typeptr, ctx.Option)
 	if err != nil {
 		return nil, err
 	}
@@ -295,6 +327,16 @@ func encodeIndent(ctx *encoder.RuntimeContext, v interface{}, prefix, indent str
 }
 
 func encodeRunCode(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]byte, error) {
+	if (ctx.Option.Flag & encoder.CanonicalOption) != 0 {
+		ctx.Option.Flag &^= encoder.HTMLEscapeOption | encoder.UnorderedMapOption
+		if (ctx.Option.Flag & encoder.DebugOption) != 0 {
+			return vm_canonical.DebugRun(ctx, b, codeSet)
+		}
+		return vm_canonical.Run(ctx, b, codeSet)
+	}
+	if (ctx.Option.Flag&encoder.ColorizeOption) != 0 && ctx.Option.ColorScheme.EscapeHTML {
+		ctx.Option.Flag |= encoder.HTMLEscapeOption
+	}
 	if (ctx.Option.Flag & encoder.DebugOption) != 0 {
 		if (ctx.Option.Flag & encoder.ColorizeOption) != 0 {
 			return vm_color.DebugRun(ctx, b, codeSet)
@@ -310,6 +352,9 @@ func encodeRunCode(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.Opcod
 func encodeRunIndentCode(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet, prefix, indent string) ([]byte, error) {
 	ctx.Prefix = []byte(prefix)
 	ctx.IndentStr = []byte(indent)
+	if (ctx.Option.Flag&encoder.ColorizeOption) != 0 && ctx.Option.ColorScheme.EscapeHTML {
+		ctx.Option.Flag |= encoder.HTMLEscapeOption
+	}
 	if (ctx.Option.Flag & encoder.DebugOption) != 0 {
 		if (ctx.Option.Flag & encoder.ColorizeOption) != 0 {
 			return vm_color_indent.DebugRun(ctx, b, codeSet)
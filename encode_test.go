@@ -565,8 +565,29 @@ func Test_MarshalIndent(t *testing.T) {
 			assertEq(t, "map[string]interface{}", result, string(bytes))
 		})
 	})
+	t.Run("values of interfaces", func(t *testing.T) {
+		for _, v := range []interface{}{
+			[]interface{}{indentMarshaler{}},
+			[]interface{}{[]interface{}{indentMarshaler{}, []int{1}}},
+			map[string]interface{}{"a": &indentMarshaler{}, "b": []int{1}},
+			struct {
+				A interface{}
+				B []interface{}
+			}{A: indentMarshaler{}, B: []interface{}{[]int{1}, map[string]int{"c": 1}}},
+		} {
+			bytes, err := json.MarshalIndent(v, prefix, indent)
+			assertErr(t, err)
+			expected, err := stdjson.MarshalIndent(v, prefix, indent)
+			assertErr(t, err)
+			assertEq(t, "interface", string(expected), string(bytes))
+		}
+	})
 }
 
+type indentMarshaler struct{}
+
+func (indentMarshaler) MarshalJSON() ([]byte, error) { return []byte(`{"x":[1,{}]}`), nil }
+
 type StringTag struct {
 	BoolStr    bool        `json:",string"`
 	IntStr     int64       `json:",string"`
@@ -1693,6 +1714,238 @@ func TestOmitEmpty(t *testing.T) {
 	}
 }
 
+type omitZeroPoint struct {
+	X, Y int
+}
+
+type omitZeroValue struct {
+	valid bool
+}
+
+func (v omitZeroValue) IsZero() bool { return !v.valid }
+
+func (v omitZeroValue) MarshalJSON() ([]byte, error) { return []byte(`"value"`), nil }
+
+type omitZeroPtrValue int
+
+func (v *omitZeroPtrValue) IsZero() bool { return *v < 0 }
+
+func TestOmitZero(t *testing.T) {
+	type T struct {
+		Time     time.Time         `json:"time,omitzero"`
+		Point    omitZeroPoint     `json:"point,omitzero"`
+		Array    [2]int            `json:"array,omitzero"`
+		Int      int               `json:"int,omitzero"`
+		Float    float64           `json:"float,omitzero"`
+		Ptr      *int              `json:"ptr,omitzero"`
+		Slice    []int             `json:"slice,omitzero"`
+		Map      map[string]int    `json:"map,omitzero"`
+		Iface    interface{}       `json:"iface,omitzero"`
+		Value    omitZeroValue     `json:"value,omitzero"`
+		PtrValue omitZeroPtrValue  `json:"ptrValue,omitzero"`
+		Both     []int             `json:"both,omitempty,omitzero"`
+		Empty    map[string]string `json:"empty,omitzero"`
+		Last     string            `json:"last,omitzero"`
+	}
+	t.Run("zero", func(t *testing.T) {
+		bytes, err := json.Marshal(T{PtrValue: -1, Both: []int{}, Empty: map[string]string{}})
+		assertErr(t, err)
+		assertEq(t, "zero", `{"empty":{}}`, string(bytes))
+	})
+	t.Run("non zero", func(t *testing.T) {
+		v := 0
+		bytes, err := json.Marshal(&T{
+			Time:  time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+			Point: omitZeroPoint{Y: 1},
+			Array: [2]int{0, 1},
+			Ptr:   &v,
+			Slice: []int{},
+			Iface: 0,
+			Value: omitZeroValue{valid: true},
+			Last:  "last",
+		})
+		assertErr(t, err)
+		assertEq(t, "non zero", `{"time":"2021-01-02T03:04:05Z","point":{"X":0,"Y":1},"array":[0,1],"ptr":0,"slice":[],"iface":0,"value":"value","ptrValue":0,"last":"last"}`, string(bytes))
+	})
+	t.Run("negative zero", func(t *testing.T) {
+		type U struct {
+			F64   float64    `json:"f64,omitzero"`
+			F32   float32    `json:"f32,omitzero"`
+			Array [2]float64 `json:"array,omitzero"`
+			Point struct {
+				X float64
+			} `json:"point,omitzero"`
+		}
+		negz := math.Copysign(0, -1)
+		v := U{F64: negz, F32: float32(negz), Array: [2]float64{negz, 0}}
+		v.Point.X = negz
+		for _, v := range []interface{}{v, &v} {
+			bytes, err := json.Marshal(v)
+			assertErr(t, err)
+			assertEq(t, "negative zero", `{}`, string(bytes))
+		}
+		bytes, err := json.Marshal(struct {
+			F float64 `json:"f,omitzero"`
+		}{F: negz})
+		assertErr(t, err)
+		assertEq(t, "single field", `{}`, string(bytes))
+	})
+	t.Run("embedded", func(t *testing.T) {
+		type U struct {
+			omitZeroPoint `json:",omitzero"`
+			Z             int `json:"z"`
+		}
+		type V struct {
+			omitZeroPoint `json:"p,omitzero"`
+			Z             int `json:"z"`
+		}
+		type W struct {
+			*omitZeroPoint `json:",omitzero"`
+		}
+		for _, tc := range []struct {
+			v        interface{}
+			expected string
+		}{
+			// the fields of the embedded struct are promoted, so omitzero is ignored.
+			{U{}, `{"X":0,"Y":0,"z":0}`},
+			{&U{omitZeroPoint: omitZeroPoint{X: 1}}, `{"X":1,"Y":0,"z":0}`},
+			{W{omitZeroPoint: &omitZeroPoint{}}, `{"X":0,"Y":0}`},
+			{V{}, `{"z":0}`},
+			{&V{omitZeroPoint: omitZeroPoint{Y: 2}}, `{"p":{"X":0,"Y":2},"z":0}`},
+		} {
+			got, err := json.Marshal(tc.v)
+			assertErr(t, err)
+			assertEq(t, "embedded", tc.expected, string(got))
+			indent, err := json.MarshalIndent(tc.v, "", "  ")
+			assertErr(t, err)
+			var expected bytes.Buffer
+			assertErr(t, stdjson.Indent(&expected, []byte(tc.expected), "", "  "))
+			assertEq(t, "embedded indent", expected.String(), string(indent))
+		}
+	})
+	t.Run("pointer shape struct", func(t *testing.T) {
+		type M struct {
+			M map[string]int `json:"m,omitzero"`
+		}
+		type P struct {
+			P struct {
+				P *int
+			} `json:"p,omitzero"`
+		}
+		one := 1
+		for _, tc := range []struct {
+			v        interface{}
+			expected string
+		}{
+			{M{}, `{}`},
+			{M{M: map[string]int{}}, `{"m":{}}`},
+			{&M{M: map[string]int{}}, `{"m":{}}`},
+			{[]interface{}{M{}, M{M: map[string]int{}}}, `[{},{"m":{}}]`},
+			{P{}, `{}`},
+			{&P{}, `{}`},
+			{[]interface{}{P{}}, `[{}]`},
+			{P{P: struct{ P *int }{P: &one}}, `{"p":{"P":1}}`},
+		} {
+			bytes, err := json.Marshal(tc.v)
+			assertErr(t, err)
+			assertEq(t, "pointer shape", tc.expected, string(bytes))
+		}
+	})
+	t.Run("zero time in location", func(t *testing.T) {
+		type U struct {
+			DeletedAt time.Time `json:"deleted_at,omitzero"`
+		}
+		bytes, err := json.Marshal(U{DeletedAt: time.Time{}.In(time.FixedZone("JST", 9*60*60))})
+		assertErr(t, err)
+		assertEq(t, "time", `{}`, string(bytes))
+		bytes, err = json.Marshal(&U{})
+		assertErr(t, err)
+		assertEq(t, "pointer", `{}`, string(bytes))
+	})
+	t.Run("pointer to struct", func(t *testing.T) {
+		type U struct {
+			A *omitZeroPoint `json:"a,omitzero"`
+			B *omitZeroPoint `json:"b,omitzero"`
+			C int
+		}
+		bytes, err := json.Marshal(U{B: &omitZeroPoint{}})
+		assertErr(t, err)
+		assertEq(t, "pointer", `{"b":{"X":0,"Y":0},"C":0}`, string(bytes))
+	})
+	t.Run("embedded", func(t *testing.T) {
+		type U struct {
+			omitZeroPoint
+			A omitZeroPoint `json:"a,omitzero"`
+		}
+		bytes, err := json.Marshal(U{omitZeroPoint: omitZeroPoint{X: 1}})
+		assertErr(t, err)
+		assertEq(t, "embedded", `{"X":1,"Y":0}`, string(bytes))
+	})
+	t.Run("single pointer field", func(t *testing.T) {
+		type U struct {
+			P *int `json:"p,omitzero"`
+		}
+		bytes, err := json.Marshal(U{})
+		assertErr(t, err)
+		assertEq(t, "nil", `{}`, string(bytes))
+		v := 1
+		bytes, err = json.Marshal(U{P: &v})
+		assertErr(t, err)
+		assertEq(t, "non nil", `{"p":1}`, string(bytes))
+	})
+	t.Run("single zero time pointer field", func(t *testing.T) {
+		type U struct {
+			When *time.Time `json:"when,omitzero"`
+		}
+		zero := time.Time{}
+		for _, v := range []interface{}{U{}, U{When: &zero}, &U{When: &zero}} {
+			bytes, err := json.Marshal(v)
+			assertErr(t, err)
+			assertEq(t, "zero", `{}`, string(bytes))
+		}
+		when := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+		bytes, err := json.Marshal(U{When: &when})
+		assertErr(t, err)
+		assertEq(t, "non zero", `{"when":"2021-01-02T03:04:05Z"}`, string(bytes))
+	})
+	t.Run("string option", func(t *testing.T) {
+		type U struct {
+			N   int         `json:"n,omitzero,string"`
+			U   uint8       `json:"u,omitzero,string"`
+			F   float64     `json:"f,omitzero,string"`
+			B   bool        `json:"b,omitzero,string"`
+			S   string      `json:"s,omitzero,string"`
+			P   *int        `json:"p,omitzero,string"`
+			Num json.Number `json:"num,omitzero,string"`
+		}
+		bytes, err := json.Marshal(U{})
+		assertErr(t, err)
+		assertEq(t, "zero", `{}`, string(bytes))
+		p := 7
+		v := U{N: 5, U: 6, F: 1.5, B: true, S: "s", P: &p, Num: "8"}
+		bytes, err = json.Marshal(v)
+		assertErr(t, err)
+		assertEq(t, "non zero", `{"n":"5","u":"6","f":"1.5","b":"true","s":"\"s\"","p":"7","num":"8"}`, string(bytes))
+		expected, err := stdjson.Marshal(v)
+		assertErr(t, err)
+		assertEq(t, "encoding/json", string(expected), string(bytes))
+		bytes, err = json.Marshal(struct {
+			N int `json:"n,omitzero,string"`
+		}{N: 5})
+		assertErr(t, err)
+		assertEq(t, "single field", `{"n":"5"}`, string(bytes))
+	})
+	t.Run("indent", func(t *testing.T) {
+		type U struct {
+			A int `json:"a,omitzero"`
+			B int `json:"b,omitzero"`
+		}
+		bytes, err := json.MarshalIndent(U{B: 1}, "", "  ")
+		assertErr(t, err)
+		assertEq(t, "indent", "{\n  \"b\": 1\n}", string(bytes))
+	})
+}
+
 type testNullStr string
 
 func (v *testNullStr) MarshalJSON() ([]byte, error) {
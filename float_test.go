@@ -0,0 +1,94 @@
+package json_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/goccy/go-json"
+)
+
+func TestFloatFormat(t *testing.T) {
+	values := []interface{}{1.0, 1234.5678, 0.000001234, 1e21, float32(2.5), float32(3)}
+	tests := []struct {
+		name     string
+		style    json.FloatStyle
+		expected string
+	}{
+		{"default", json.FloatStyle{}, `[1,1234.5678,0.000001234,1e+21,2.5,3]`},
+		{"precision", json.FloatStyle{Precision: 3}, `[1,1230,0.00000123,1e+21,2.5,3]`},
+		{"fixed", json.FloatStyle{Notation: 'f'}, `[1,1234.5678,0.000001234,1000000000000000000000,2.5,3]`},
+		{"exponent", json.FloatStyle{Notation: 'e', Precision: 2}, `[1e+00,1.2e+03,1.2e-06,1e+21,2.5e+00,3e+00]`},
+		{"thresholds", json.FloatStyle{MinFixed: 0.001, MaxFixed: 1000}, `[1,1.2345678e+03,1.234e-06,1e+21,2.5,3]`},
+		{"trailing zero", json.FloatStyle{TrailingZero: true}, `[1.0,1234.5678,0.000001234,1e+21,2.5,3.0]`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b, err := json.MarshalWithOption(values, json.FloatFormat(test.style))
+			assertErr(t, err)
+			assertEq(t, "float", test.expected, string(b))
+		})
+	}
+	t.Run("field tag", func(t *testing.T) {
+		type T struct {
+			Price    float64  `json:"price,precision=4,fmt=f"`
+			Ratio    *float32 `json:"ratio,precision=2"`
+			Large    float64  `json:"large,fmt=f"`
+			Small    float64  `json:"small,fmt=e"`
+			Quoted   float64  `json:"quoted,string,precision=2"`
+			Untagged float64  `json:"untagged"`
+		}
+		ratio := float32(0.33333)
+		v := T{Price: 12.34567, Ratio: &ratio, Large: 1e22, Small: 0.5, Quoted: 1.55555, Untagged: 2}
+		b, err := json.Marshal(v)
+		assertErr(t, err)
+		assertEq(t, "tag", `{"price":12.35,"ratio":0.33,"large":10000000000000000000000,"small":5e-01,"quoted":"1.6","untagged":2}`, string(b))
+
+		b, err = json.MarshalWithOption(v, json.FloatFormat(json.FloatStyle{TrailingZero: true}))
+		assertErr(t, err)
+		assertEq(t, "tag with option", `{"price":12.35,"ratio":0.33,"large":10000000000000000000000.0,"small":5e-01,"quoted":"1.6","untagged":2.0}`, string(b))
+
+		b, err = json.MarshalIndent(v, "", "")
+		assertErr(t, err)
+		assertEq(t, "indent", "{\n\"price\": 12.35,\n\"ratio\": 0.33,\n\"large\": 10000000000000000000000,\n\"small\": 5e-01,\n\"quoted\": \"1.6\",\n\"untagged\": 2\n}", string(b))
+	})
+	t.Run("field tag of elements", func(t *testing.T) {
+		type Inner struct {
+			V float64 `json:"v"`
+		}
+		type T struct {
+			Slice  []float64             `json:"s,precision=3"`
+			Array  [2]*float32           `json:"a,precision=2"`
+			Map    map[string]float64    `json:"m,precision=3,fmt=e"`
+			Nested [][]float64           `json:"n,fmt=f"`
+			Struct []Inner               `json:"st,precision=1"`
+			Iface  []interface{}         `json:"i,precision=1"`
+			Ptrs   map[string][]*float64 `json:"p,precision=2"`
+		}
+		f32 := float32(0.33333)
+		f64 := 2.71828
+		v := T{
+			Slice:  []float64{3.14159, 100},
+			Array:  [2]*float32{&f32, nil},
+			Map:    map[string]float64{"k": 3.14159},
+			Nested: [][]float64{{1e22}},
+			Struct: []Inner{{V: 1.25}},
+			Iface:  []interface{}{1.25},
+			Ptrs:   map[string][]*float64{"e": {&f64}},
+		}
+		expected := `{"s":[3.14,100],"a":[0.33,null],"m":{"k":3.14e+00},"n":[[10000000000000000000000]],"st":[{"v":1.25}],"i":[1.25],"p":{"e":[2.7]}}`
+		b, err := json.Marshal(v)
+		assertErr(t, err)
+		assertEq(t, "elements", expected, string(b))
+
+		b, err = json.MarshalIndent(v, "", "")
+		assertErr(t, err)
+		var buf bytes.Buffer
+		assertErr(t, json.Compact(&buf, b))
+		assertEq(t, "indent", expected, buf.String())
+	})
+	t.Run("canonical ignores style", func(t *testing.T) {
+		b, err := json.MarshalWithOption(values, json.FloatFormat(json.FloatStyle{Precision: 2, TrailingZero: true}), json.Canonical())
+		assertErr(t, err)
+		assertEq(t, "canonical", `[1,1234.5678,0.000001234,1e+21,2.5,3]`, string(b))
+	})
+}
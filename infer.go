@@ -0,0 +1,375 @@
+package json
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/goccy/go-json/internal/runtime"
+)
+
+// InferGoType infers the Go types of the JSON documents in samples, and returns their gofmt'd
+// declarations. The root type is named "Root". See InferGoTypeWithName.
+func InferGoType(samples ...[]byte) (string, error) {
+	return InferGoTypeWithName("Root", samples...)
+}
+
+// InferGoTypeWithName infers the Go types of the JSON documents in samples, and returns their gofmt'd
+// declarations with the root type named name.
+// Each sample can have multiple documents like JSON Lines, and the shapes of all the documents are merged:
+//
+//   - the objects are declared as the structs whose fields are tagged with the keys;
+//   - the numbers are int64, uint64 if any of them is greater than the max of int64,
+//     or float64 if any of them has a fraction or an exponent or doesn't fit in either;
+//   - the strings are time.Time if all of them are RFC 3339 timestamps;
+//   - the values that are null in some documents are pointers, except the slices, the maps and interface{};
+//   - the fields missing in some objects are pointers tagged with omitempty, or tagged with omitzero
+//     for the slices and the maps, so that the documents round-trip;
+//   - the values of the different kinds are interface{}.
+//
+// The objects whose keys can't be the keys of the json tags are map[string]T.
+// The declarations require importing "time" if they refer to time.Time.
+func InferGoTypeWithName(name string, samples ...[]byte) (string, error) {
+	root := &inferredShape{}
+	for _, sample := range samples {
+		dec := NewDecoder(bytes.NewReader(sample))
+		dec.UseNumber()
+		for {
+			var v interface{}
+			if err := dec.DecodeWithOption(&v, DecodeOrderedMap()); err != nil {
+				if err == io.EOF {
+					break
+				}
+				return "", err
+			}
+			root.observe(v)
+		}
+	}
+	in := &inferrer{typeNames: map[string]bool{}}
+	decl := &inferredDecl{name: in.uniqueTypeName(name, "")}
+	in.decls = append(in.decls, decl)
+	if root.isStruct() {
+		in.declareStruct(decl, root)
+	} else {
+		decl.typ = in.goType(root, "", name)
+	}
+	var b strings.Builder
+	for i, decl := range in.decls {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		decl.write(&b)
+	}
+	// gofmt aligns the fields by the widths of the characters, not the bytes.
+	formatted, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return "", err
+	}
+	return string(formatted), nil
+}
+
+// inferredShape is the merged shape of the observed values.
+type inferredShape struct {
+	nulls   int
+	bools   int
+	ints    int
+	uints   int // the integers that are greater than the max of int64
+	negs    int // the negative integers
+	floats  int
+	strings int
+	times   int
+	arrays  int
+	objects int
+
+	elem   *inferredShape
+	keys   []string
+	fields map[string]*inferredShape
+	// present is the number of the objects that have the key.
+	present map[string]int
+}
+
+func (s *inferredShape) observe(v interface{}) {
+	switch v := v.(type) {
+	case nil:
+		s.nulls++
+	case bool:
+		s.bools++
+	case Number:
+		if n, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			s.ints++
+			if n < 0 {
+				s.negs++
+			}
+		} else if _, err := strconv.ParseUint(string(v), 10, 64); err == nil {
+			s.uints++
+		} else {
+			s.floats++
+		}
+	case string:
+		s.strings++
+		if _, err := time.Parse(time.RFC3339, v); err == nil {
+			s.times++
+		}
+	case []interface{}:
+		s.arrays++
+		if s.elem == nil {
+			s.elem = &inferredShape{}
+		}
+		for _, elem := range v {
+			s.elem.observe(elem)
+		}
+	case *OrderedMap:
+		s.objects++
+		if s.fields == nil {
+			s.fields = map[string]*inferredShape{}
+			s.present = map[string]int{}
+		}
+		for _, item := range v.Items() {
+			field, exists := s.fields[item.Key]
+			if !exists {
+				field = &inferredShape{}
+				s.fields[item.Key] = field
+				s.keys = append(s.keys, item.Key)
+			}
+			s.present[item.Key]++
+			field.observe(item.Value)
+		}
+	}
+}
+
+// kinds returns the number of the kinds observed except null.
+func (s *inferredShape) kinds() int {
+	n := 0
+	for _, count := range []int{s.bools, s.ints + s.uints + s.floats, s.strings, s.arrays, s.objects} {
+		if count > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// isStruct reports whether the objects of the shape are declared as a struct.
+func (s *inferredShape) isStruct() bool {
+	if s.kinds() != 1 || s.objects == 0 || len(s.keys) == 0 {
+		return false
+	}
+	for _, key := range s.keys {
+		if !runtime.IsValidTag(key) {
+			return false
+		}
+	}
+	return true
+}
+
+type inferredDecl struct {
+	name   string
+	typ    string // the type of the non-struct declaration
+	fields []inferredField
+}
+
+type inferredField struct {
+	name string
+	typ  string
+	tag  string
+}
+
+// write writes the declaration, which is aligned by gofmt later.
+func (d *inferredDecl) write(b *strings.Builder) {
+	if d.fields == nil {
+		fmt.Fprintf(b, "type %s %s\n", d.name, d.typ)
+		return
+	}
+	fmt.Fprintf(b, "type %s struct {\n", d.name)
+	for _, f := range d.fields {
+		fmt.Fprintf(b, "\t%s %s %s\n", f.name, f.typ, f.tag)
+	}
+	b.WriteString("}\n")
+}
+
+type inferrer struct {
+	decls     []*inferredDecl
+	typeNames map[string]bool
+}
+
+func (in *inferrer) uniqueTypeName(name, parent string) string {
+	if !in.typeNames[name] {
+		in.typeNames[name] = true
+		return name
+	}
+	if parent != "" && !in.typeNames[parent+name] {
+		in.typeNames[parent+name] = true
+		return parent + name
+	}
+	for i := 2; ; i++ {
+		numbered := fmt.Sprintf("%s%d", name, i)
+		if !in.typeNames[numbered] {
+			in.typeNames[numbered] = true
+			return numbered
+		}
+	}
+}
+
+func (in *inferrer) declareStruct(decl *inferredDecl, s *inferredShape) {
+	decl.fields = []inferredField{}
+	fieldNames := map[string]bool{}
+	for _, key := range s.keys {
+		fieldName := goFieldName(key)
+		for i := 2; fieldNames[fieldName]; i++ {
+			fieldName = fmt.Sprintf("%s%d", goFieldName(key), i)
+		}
+		fieldNames[fieldName] = true
+
+		field := s.fields[key]
+		typ := in.goType(field, decl.name, fieldName)
+		tagKey := key
+		if s.present[key] < s.objects {
+			switch {
+			case strings.HasPrefix(typ, "[]") || strings.HasPrefix(typ, "map["):
+				tagKey += ",omitzero"
+			case typ == "interface{}" || strings.HasPrefix(typ, "*"):
+				tagKey += ",omitempty"
+			default:
+				typ = "*" + typ
+				tagKey += ",omitempty"
+			}
+		}
+		if tagKey == "-" {
+			// the tag "-" ignores the field.
+			tagKey = "-,"
+		}
+		decl.fields = append(decl.fields, inferredField{
+			name: fieldName,
+			typ:  typ,
+			tag:  fmt.Sprintf("`json:%s`", strconv.Quote(tagKey)),
+		})
+	}
+}
+
+// goType returns the Go type of the shape. The structs are declared with the name.
+func (in *inferrer) goType(s *inferredShape, parent, name string) string {
+	var typ string
+	switch {
+	case s.kinds() != 1:
+		return "interface{}"
+	case s.bools > 0:
+		typ = "bool"
+	case s.floats > 0 || s.uints > 0 && s.negs > 0:
+		typ = "float64"
+	case s.uints > 0:
+		typ = "uint64"
+	case s.ints > 0:
+		typ = "int64"
+	case s.strings > 0 && s.times == s.strings:
+		typ = "time.Time"
+	case s.strings > 0:
+		typ = "string"
+	case s.arrays > 0:
+		if s.elem == nil || s.elem.kinds() == 0 {
+			return "[]interface{}"
+		}
+		return "[]" + in.goType(s.elem, parent, singularName(name))
+	case s.isStruct():
+		decl := &inferredDecl{name: in.uniqueTypeName(name, parent)}
+		in.decls = append(in.decls, decl)
+		in.declareStruct(decl, s)
+		typ = decl.name
+	default:
+		// the objects that have no keys, or the keys that can't be tagged.
+		var values inferredShape
+		for _, key := range s.keys {
+			values.merge(s.fields[key])
+		}
+		return "map[string]" + in.goType(&values, parent, singularName(name))
+	}
+	if s.nulls > 0 {
+		return "*" + typ
+	}
+	return typ
+}
+
+// merge merges the observed values of x into s.
+func (s *inferredShape) merge(x *inferredShape) {
+	s.nulls += x.nulls
+	s.bools += x.bools
+	s.ints += x.ints
+	s.uints += x.uints
+	s.negs += x.negs
+	s.floats += x.floats
+	s.strings += x.strings
+	s.times += x.times
+	s.arrays += x.arrays
+	s.objects += x.objects
+	if x.elem != nil {
+		if s.elem == nil {
+			s.elem = &inferredShape{}
+		}
+		s.elem.merge(x.elem)
+	}
+	for _, key := range x.keys {
+		if s.fields == nil {
+			s.fields = map[string]*inferredShape{}
+			s.present = map[string]int{}
+		}
+		field, exists := s.fields[key]
+		if !exists {
+			field = &inferredShape{}
+			s.fields[key] = field
+			s.keys = append(s.keys, key)
+		}
+		s.present[key] += x.present[key]
+		field.merge(x.fields[key])
+	}
+}
+
+// commonInitialisms are the words written in upper case in the Go names, like golint.
+var commonInitialisms = map[string]bool{
+	"ACL": true, "API": true, "ASCII": true, "CPU": true, "CSS": true, "DNS": true, "EOF": true,
+	"GUID": true, "HTML": true, "HTTP": true, "HTTPS": true, "ID": true, "IP": true, "JSON": true,
+	"LHS": true, "QPS": true, "RAM": true, "RHS": true, "RPC": true, "SLA": true, "SMTP": true,
+	"SQL": true, "SSH": true, "TCP": true, "TLS": true, "TTL": true, "UDP": true, "UI": true,
+	"UID": true, "UUID": true, "URI": true, "URL": true, "UTF8": true, "VM": true, "XML": true,
+}
+
+// goFieldName converts the key into the exported Go name, like "user_id" into "UserID".
+func goFieldName(key string) string {
+	normalized := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, key)
+	var b strings.Builder
+	for _, word := range splitWords(normalized) {
+		if upper := strings.ToUpper(word); commonInitialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+	name := b.String()
+	if name == "" {
+		return "Field"
+	}
+	if first := []rune(name)[0]; !unicode.IsUpper(first) {
+		return "X" + name
+	}
+	return name
+}
+
+// singularName returns the name of the element of the slice.
+func singularName(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies") && len(name) > 3:
+		return name[:len(name)-3] + "y"
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss") && len(name) > 1:
+		return name[:len(name)-1]
+	}
+	return name + "Elem"
+}
@@ -0,0 +1,111 @@
+package json_test
+
+import (
+	"bytes"
+	"go/format"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+const inferSamples = `{"user_id":1,"name":"a","score":1.5,"created_at":"2020-01-01T00:00:00Z","tags":["x"],"items":[{"id":1,"url":"u"}],"meta":{"a\"b":1},"-":true,"note":null,"any":1}
+{"user_id":2,"name":"b","score":2,"created_at":"2020-01-02T00:00:00Z","items":[],"owner":{"id":3},"note":"n","any":"x"}`
+
+const inferExpected = "type Root struct {\n" +
+	"\tUserID    int64            `json:\"user_id\"`\n" +
+	"\tName      string           `json:\"name\"`\n" +
+	"\tScore     float64          `json:\"score\"`\n" +
+	"\tCreatedAt time.Time        `json:\"created_at\"`\n" +
+	"\tTags      []string         `json:\"tags,omitzero\"`\n" +
+	"\tItems     []Item           `json:\"items\"`\n" +
+	"\tMeta      map[string]int64 `json:\"meta,omitzero\"`\n" +
+	"\tField     *bool            `json:\"-,omitempty\"`\n" +
+	"\tNote      *string          `json:\"note\"`\n" +
+	"\tAny       interface{}      `json:\"any\"`\n" +
+	"\tOwner     *Owner           `json:\"owner,omitempty\"`\n" +
+	"}\n" +
+	"\n" +
+	"type Item struct {\n" +
+	"\tID  int64  `json:\"id\"`\n" +
+	"\tURL string `json:\"url\"`\n" +
+	"}\n" +
+	"\n" +
+	"type Owner struct {\n" +
+	"\tID int64 `json:\"id\"`\n" +
+	"}\n"
+
+// inferRoot is the copy of inferExpected.
+type inferRoot struct {
+	UserID    int64            `json:"user_id"`
+	Name      string           `json:"name"`
+	Score     float64          `json:"score"`
+	CreatedAt time.Time        `json:"created_at"`
+	Tags      []string         `json:"tags,omitzero"`
+	Items     []inferItem      `json:"items"`
+	Meta      map[string]int64 `json:"meta,omitzero"`
+	Field     *bool            `json:"-,omitempty"`
+	Note      *string          `json:"note"`
+	Any       interface{}      `json:"any"`
+	Owner     *inferOwner      `json:"owner,omitempty"`
+}
+
+type inferItem struct {
+	ID  int64  `json:"id"`
+	URL string `json:"url"`
+}
+
+type inferOwner struct {
+	ID int64 `json:"id"`
+}
+
+func TestInferGoType(t *testing.T) {
+	got, err := json.InferGoType([]byte(inferSamples))
+	assertErr(t, err)
+	assertEq(t, "declarations", inferExpected, got)
+	formatted, err := format.Source([]byte(got))
+	assertErr(t, err)
+	assertEq(t, "gofmt", string(formatted), got)
+
+	t.Run("round trip", func(t *testing.T) {
+		expected := []string{
+			`{"user_id":1,"name":"a","score":1.5,"created_at":"2020-01-01T00:00:00Z","tags":["x"],"items":[{"id":1,"url":"u"}],"meta":{"a\"b":1},"-":true,"note":null,"any":1}`,
+			`{"user_id":2,"name":"b","score":2,"created_at":"2020-01-02T00:00:00Z","items":[],"note":"n","any":"x","owner":{"id":3}}`,
+		}
+		for i, line := range strings.Split(inferSamples, "\n") {
+			var v inferRoot
+			assertErr(t, json.Unmarshal([]byte(line), &v))
+			b, err := json.Marshal(v)
+			assertErr(t, err)
+			assertEq(t, "encoded", expected[i], string(b))
+		}
+	})
+	t.Run("name", func(t *testing.T) {
+		got, err := json.InferGoTypeWithName("Users", []byte(`[{"id":1,"users":[{"id":2}]}]`), []byte(`[]`))
+		assertErr(t, err)
+		assertEq(t, "declarations", "type Users []User\n\ntype User struct {\n\tID    int64      `json:\"id\"`\n\tUsers []UserUser `json:\"users\"`\n}\n\ntype UserUser struct {\n\tID int64 `json:\"id\"`\n}\n", got)
+	})
+	t.Run("scalar", func(t *testing.T) {
+		got, err := json.InferGoType([]byte("1 null 2.5"), []byte(`{}`))
+		assertErr(t, err)
+		assertEq(t, "mixed", "type Root interface{}\n", got)
+		got, err = json.InferGoType([]byte("1 null 2.5"))
+		assertErr(t, err)
+		assertEq(t, "nullable", "type Root *float64\n", got)
+	})
+	t.Run("non-ASCII keys", func(t *testing.T) {
+		got, err := json.InferGoType([]byte(`{"naïve_ñame":1,"id":"x"}`))
+		assertErr(t, err)
+		assertEq(t, "declarations", "type Root struct {\n\tNaïveÑame int64  `json:\"naïve_ñame\"`\n\tID        string `json:\"id\"`\n}\n", got)
+	})
+	t.Run("unsigned", func(t *testing.T) {
+		got, err := json.InferGoType([]byte(`{"a":18446744073709551615,"b":-1,"c":1} {"a":1,"b":18446744073709551615,"c":2}`))
+		assertErr(t, err)
+		assertEq(t, "declarations", "type Root struct {\n\tA uint64  `json:\"a\"`\n\tB float64 `json:\"b\"`\n\tC int64   `json:\"c\"`\n}\n", got)
+	})
+	t.Run("syntax error", func(t *testing.T) {
+		_, err := json.InferGoType(bytes.Repeat([]byte("{"), 2))
+		assertNeq(t, "error", nil, err)
+	})
+}
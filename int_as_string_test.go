@@ -0,0 +1,94 @@
+package json_test
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/goccy/go-json"
+)
+
+func TestInt64AsString(t *testing.T) {
+	type T struct {
+		ID      int64   `json:"id"`
+		Small   int64   `json:"small"`
+		Neg     int64   `json:"neg"`
+		U       uint64  `json:"u"`
+		I32     int32   `json:"i32"`
+		Ptr     *int64  `json:"ptr"`
+		Nil     *uint64 `json:"nil"`
+		Omit    int64   `json:"omit,omitempty"`
+		Tagged  int64   `json:"tagged,string"`
+		IDs     []int64 `json:"ids"`
+		Map     map[int64]uint64
+		Any     interface{} `json:"any"`
+		Trailer int         `json:"trailer"`
+	}
+	big := int64(1 << 60)
+	v := T{
+		ID:      1<<53 + 1,
+		Small:   1<<53 - 1,
+		Neg:     -(1<<53 + 1),
+		U:       math.MaxUint64,
+		I32:     math.MaxInt32,
+		Ptr:     &big,
+		Tagged:  1,
+		IDs:     []int64{1, 1 << 62},
+		Map:     map[int64]uint64{1: 1 << 63},
+		Any:     []interface{}{int64(1 << 54), uint8(200)},
+		Trailer: 1 << 54,
+	}
+	t.Run("int64", func(t *testing.T) {
+		b, err := json.MarshalWithOption(v, json.Int64AsString(json.MaxSafeInteger))
+		assertErr(t, err)
+		expected := `{"id":"9007199254740993","small":9007199254740991,"neg":"-9007199254740993","u":"18446744073709551615","i32":2147483647,"ptr":"1152921504606846976","nil":null,"tagged":"1","ids":[1,"4611686018427387904"],"Map":{"1":"9223372036854775808"},"any":["18014398509481984",200],"trailer":"18014398509481984"}`
+		assertEq(t, "int64", expected, string(b))
+
+		b, err = json.Marshal(v)
+		assertErr(t, err)
+		assertNeq(t, "disabled", expected, string(b))
+	})
+	t.Run("any integer", func(t *testing.T) {
+		b, err := json.MarshalWithOption([]interface{}{int8(-100), uint16(100), int32(99)}, json.IntAsString(99))
+		assertErr(t, err)
+		assertEq(t, "any", `["-100","100",99]`, string(b))
+
+		b, err = json.MarshalWithOption([]interface{}{int8(-100), uint16(100), int64(100)}, json.Int64AsString(99))
+		assertErr(t, err)
+		assertEq(t, "int64 only", `[-100,100,"100"]`, string(b))
+	})
+	t.Run("indent", func(t *testing.T) {
+		b, err := json.MarshalIndentWithOption(map[string]int64{"a": 1 << 60}, "", " ", json.Int64AsString(json.MaxSafeInteger))
+		assertErr(t, err)
+		assertEq(t, "indent", "{\n \"a\": \"1152921504606846976\"\n}", string(b))
+	})
+	t.Run("decode", func(t *testing.T) {
+		b, err := json.MarshalWithOption(v, json.Int64AsString(json.MaxSafeInteger))
+		assertErr(t, err)
+		var got T
+		assertErr(t, json.UnmarshalWithOption(b, &got, json.DecodeIntAsString()))
+		assertEq(t, "id", v.ID, got.ID)
+		assertEq(t, "u", v.U, got.U)
+		assertEq(t, "neg", v.Neg, got.Neg)
+		assertEq(t, "ptr", *v.Ptr, *got.Ptr)
+		assertEq(t, "ids", v.IDs[1], got.IDs[1])
+		assertEq(t, "map", v.Map[1], got.Map[1])
+
+		var streamed T
+		dec := json.NewDecoder(bytes.NewReader(b))
+		assertErr(t, dec.DecodeWithOption(&streamed, json.DecodeIntAsString()))
+		assertEq(t, "stream id", v.ID, streamed.ID)
+		assertEq(t, "stream u", v.U, streamed.U)
+
+		if err := json.Unmarshal(b, &got); err == nil {
+			t.Fatal("expected error without option")
+		}
+		var i int
+		if err := json.UnmarshalWithOption([]byte(`"1x"`), &i, json.DecodeIntAsString()); err == nil {
+			t.Fatal("expected error")
+		}
+		if err := json.UnmarshalWithOption([]byte(`"null"`), &i, json.DecodeIntAsString()); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+}
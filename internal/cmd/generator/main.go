@@ -257,6 +257,13 @@ func (t OpType) FieldToOmitEmptyField() OpType {
 			})
 		}
 	}
+	// fields tagged with omitzero are encoded by the generic struct operations.
+	// StructPtrHeadOmitZero must be placed two steps after StructHeadOmitZero like other ptr heads.
+	opTypes = append(opTypes,
+		createOpType("StructHeadOmitZero", "StructField"),
+		createOpType("StructFieldOmitZero", "StructField"),
+		createOpType("StructPtrHeadOmitZero", "StructField"),
+	)
 	var b bytes.Buffer
 	if err := tmpl.Execute(&b, struct {
 		CodeTypes []string
@@ -287,7 +294,7 @@ func generateVM() error {
 	if err != nil {
 		return err
 	}
-	for _, pkg := range []string{"vm", "vm_indent", "vm_color", "vm_color_indent"} {
+	for _, pkg := range []string{"vm", "vm_indent", "vm_color", "vm_color_indent", "vm_canonical"} {
 		f.Name.Name = pkg
 		var buf bytes.Buffer
 		printer.Fprint(&buf, fset, f)
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
-	"sync/atomic"
 	"unicode"
 	"unsafe"
 
@@ -16,8 +15,11 @@ import (
 var (
 	jsonNumberType   = reflect.TypeOf(json.Number(""))
 	typeAddr         *runtime.TypeAddr
-	cachedDecoderMap unsafe.Pointer // map[uintptr]decoder
+	cachedDecoderMap runtime.CodeCache // map[uintptr]Decoder
 	cachedDecoder    []Decoder
+
+	// decoderGroup deduplicates the concurrent compilations of the types cached in cachedDecoder.
+	decoderGroup runtime.CompileGroup
 )
 
 func init() {
@@ -28,47 +30,63 @@ func init() {
 	cachedDecoder = make([]Decoder, typeAddr.AddrRange>>typeAddr.AddrShift)
 }
 
-func loadDecoderMap() map[uintptr]Decoder {
-	p := atomic.LoadPointer(&cachedDecoderMap)
-	return *(*map[uintptr]Decoder)(unsafe.Pointer(&p))
+func compileToGetDecoderSlowPath(typeptr uintptr, typ *runtime.Type) (Decoder, error) {
+	dec, err := cachedDecoderMap.LoadOrCompile(typeptr, func() (interface{}, error) {
+		return compileHead(typ, map[uintptr]Decoder{}, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dec.(Decoder), nil
 }
 
-func storeDecoder(typ uintptr, dec Decoder, m map[uintptr]Decoder) {
-	newDecoderMap := make(map[uintptr]Decoder, len(m)+1)
-	newDecoderMap[typ] = dec
-
-	for k, v := range m {
-		newDecoderMap[k] = v
+// compileDecoderOnce compiles the type cached in cachedDecoder.
+// The concurrent callers of the same type share the compiled decoder.
+func compileDecoderOnce(typeptr uintptr, typ *runtime.Type) (Decoder, error) {
+	dec, err := decoderGroup.Do(typeptr, func() (interface{}, error) {
+		return compileHead(typ, map[uintptr]Decoder{}, nil)
+	})
+	if err != nil {
+		return nil, err
 	}
+	return dec.(Decoder), nil
+}
 
-	atomic.StorePointer(&cachedDecoderMap, *(*unsafe.Pointer)(unsafe.Pointer(&newDecoderMap)))
+type namingPolicyDecoderKey struct {
+	policy  *runtime.NamingPolicy
+	typeptr uintptr
 }
 
-func compileToGetDecoderSlowPath(typeptr uintptr, typ *runtime.Type) (Decoder, error) {
-	decoderMap := loadDecoderMap()
-	if dec, exists := decoderMap[typeptr]; exists {
-		return dec, nil
-	}
+var namingPolicyDecoders runtime.CodeCache // map[namingPolicyDecoderKey]Decoder
 
-	dec, err := compileHead(typ, map[uintptr]Decoder{})
+// CompileToGetDecoderWithOption is like CompileToGetDecoder,
+// but compiles the type with the naming policy of opt if it is enabled.
+// The decoders compiled with a naming policy are cached for every policy.
+func CompileToGetDecoderWithOption(typ *runtime.Type, opt *Option) (Decoder, error) {
+	if (opt.Flags&NamingPolicyOption) == 0 || opt.NamingPolicy == nil {
+		return CompileToGetDecoder(typ)
+	}
+	key := namingPolicyDecoderKey{policy: opt.NamingPolicy, typeptr: uintptr(unsafe.Pointer(typ))}
+	dec, err := namingPolicyDecoders.LoadOrCompile(key, func() (interface{}, error) {
+		return compileHead(typ, map[uintptr]Decoder{}, opt.NamingPolicy)
+	})
 	if err != nil {
 		return nil, err
 	}
-	storeDecoder(typeptr, dec, decoderMap)
-	return dec, nil
+	return dec.(Decoder), nil
 }
 
-func compileHead(typ *runtime.Type, structTypeToDecoder map[uintptr]Decoder) (Decoder, error) {
+func compileHead(typ *runtime.Type, structTypeToDecoder map[uintptr]Decoder, namingPolicy *runtime.NamingPolicy) (Decoder, error) {
 	switch {
 	case implementsUnmarshalJSONType(runtime.PtrTo(typ)):
 		return newUnmarshalJSONDecoder(runtime.PtrTo(typ), "", ""), nil
 	case runtime.PtrTo(typ).Implements(unmarshalTextType):
 		return newUnmarshalTextDecoder(runtime.PtrTo(typ), "", ""), nil
 	}
-	return compile(typ.Elem(), "", "", structTypeToDecoder)
+	return compile(typ.Elem(), "", "", structTypeToDecoder, namingPolicy)
 }
 
-func compile(typ *runtime.Type, structName, fieldName string, structTypeToDecoder map[uintptr]Decoder) (Decoder, error) {
+func compile(typ *runtime.Type, structName, fieldName string, structTypeToDecoder map[uintptr]Decoder, namingPolicy *runtime.NamingPolicy) (Decoder, error) {
 	switch {
 	case implementsUnmarshalJSONType(runtime.PtrTo(typ)):
 		return newUnmarshalJSONDecoder(runtime.PtrTo(typ), structName, fieldName), nil
@@ -78,19 +96,19 @@ func compile(typ *runtime.Type, structName, fieldName string, structTypeToDecode
 
 	switch typ.Kind() {
 	case reflect.Ptr:
-		return compilePtr(typ, structName, fieldName, structTypeToDecoder)
+		return compilePtr(typ, structName, fieldName, structTypeToDecoder, namingPolicy)
 	case reflect.Struct:
-		return compileStruct(typ, structName, fieldName, structTypeToDecoder)
+		return compileStruct(typ, structName, fieldName, structTypeToDecoder, namingPolicy)
 	case reflect.Slice:
 		elem := typ.Elem()
 		if elem.Kind() == reflect.Uint8 {
 			return compileBytes(elem, structName, fieldName)
 		}
-		return compileSlice(typ, structName, fieldName, structTypeToDecoder)
+		return compileSlice(typ, structName, fieldName, structTypeToDecoder, namingPolicy)
 	case reflect.Array:
-		return compileArray(typ, structName, fieldName, structTypeToDecoder)
+		return compileArray(typ, structName, fieldName, structTypeToDecoder, namingPolicy)
 	case reflect.Map:
-		return compileMap(typ, structName, fieldName, structTypeToDecoder)
+		return compileMap(typ, structName, fieldName, structTypeToDecoder, namingPolicy)
 	case reflect.Interface:
 		return compileInterface(typ, structName, fieldName)
 	case reflect.Uintptr:
@@ -157,11 +175,11 @@ func isStringTagSupportedType(typ *runtime.Type) bool {
 	return true
 }
 
-func compileMapKey(typ *runtime.Type, structName, fieldName string, structTypeToDecoder map[uintptr]Decoder) (Decoder, error) {
+func compileMapKey(typ *runtime.Type, structName, fieldName string, structTypeToDecoder map[uintptr]Decoder, namingPolicy *runtime.NamingPolicy) (Decoder, error) {
 	if runtime.PtrTo(typ).Implements(unmarshalTextType) {
 		return newUnmarshalTextDecoder(runtime.PtrTo(typ), structName, fieldName), nil
 	}
-	dec, err := compile(typ, structName, fieldName, structTypeToDecoder)
+	dec, err := compile(typ, structName, fieldName, structTypeToDecoder, namingPolicy)
 	if err != nil {
 		return nil, err
 	}
@@ -187,8 +205,8 @@ ERROR:
 	}
 }
 
-func compilePtr(typ *runtime.Type, structName, fieldName string, structTypeToDecoder map[uintptr]Decoder) (Decoder, error) {
-	dec, err := compile(typ.Elem(), structName, fieldName, structTypeToDecoder)
+func compilePtr(typ *runtime.Type, structName, fieldName string, structTypeToDecoder map[uintptr]Decoder, namingPolicy *runtime.NamingPolicy) (Decoder, error) {
+	dec, err := compile(typ.Elem(), structName, fieldName, structTypeToDecoder, namingPolicy)
 	if err != nil {
 		return nil, err
 	}
@@ -284,30 +302,30 @@ func compileBytes(typ *runtime.Type, structName, fieldName string) (Decoder, err
 	return newBytesDecoder(typ, structName, fieldName), nil
 }
 
-func compileSlice(typ *runtime.Type, structName, fieldName string, structTypeToDecoder map[uintptr]Decoder) (Decoder, error) {
+func compileSlice(typ *runtime.Type, structName, fieldName string, structTypeToDecoder map[uintptr]Decoder, namingPolicy *runtime.NamingPolicy) (Decoder, error) {
 	elem := typ.Elem()
-	decoder, err := compile(elem, structName, fieldName, structTypeToDecoder)
+	decoder, err := compile(elem, structName, fieldName, structTypeToDecoder, namingPolicy)
 	if err != nil {
 		return nil, err
 	}
 	return newSliceDecoder(decoder, elem, elem.Size(), structName, fieldName), nil
 }
 
-func compileArray(typ *runtime.Type, structName, fieldName string, structTypeToDecoder map[uintptr]Decoder) (Decoder, error) {
+func compileArray(typ *runtime.Type, structName, fieldName string, structTypeToDecoder map[uintptr]Decoder, namingPolicy *runtime.NamingPolicy) (Decoder, error) {
 	elem := typ.Elem()
-	decoder, err := compile(elem, structName, fieldName, structTypeToDecoder)
+	decoder, err := compile(elem, structName, fieldName, structTypeToDecoder, namingPolicy)
 	if err != nil {
 		return nil, err
 	}
 	return newArrayDecoder(decoder, elem, typ.Len(), structName, fieldName), nil
 }
 
-func compileMap(typ *runtime.Type, structName, fieldName string, structTypeToDecoder map[uintptr]Decoder) (Decoder, error) {
-	keyDec, err := compileMapKey(typ.Key(), structName, fieldName, structTypeToDecoder)
+func compileMap(typ *runtime.Type, structName, fieldName string, structTypeToDecoder map[uintptr]Decoder, namingPolicy *runtime.NamingPolicy) (Decoder, error) {
+	keyDec, err := compileMapKey(typ.Key(), structName, fieldName, structTypeToDecoder, namingPolicy)
 	if err != nil {
 		return nil, err
 	}
-	valueDec, err := compile(typ.Elem(), structName, fieldName, structTypeToDecoder)
+	valueDec, err := compile(typ.Elem(), structName, fieldName, structTypeToDecoder, namingPolicy)
 	if err != nil {
 		return nil, err
 	}
@@ -324,6 +342,10 @@ func compileFunc(typ *runtime.Type, strutName, fieldName string) (Decoder, error
 
 func removeConflictFields(fieldMap map[string]*structFieldSet, conflictedMap map[string]struct{}, dec *structDecoder, field reflect.StructField) {
 	for k, v := range dec.fieldMap {
+		if k != v.key {
+			// the lowercase alias of the field is added with the field.
+			continue
+		}
 		if _, exists := conflictedMap[k]; exists {
 			// already conflicted key
 			continue
@@ -377,7 +399,7 @@ func removeConflictFields(fieldMap map[string]*structFieldSet, conflictedMap map
 	}
 }
 
-func compileStruct(typ *runtime.Type, structName, fieldName string, structTypeToDecoder map[uintptr]Decoder) (Decoder, error) {
+func compileStruct(typ *runtime.Type, structName, fieldName string, structTypeToDecoder map[uintptr]Decoder, namingPolicy *runtime.NamingPolicy) (Decoder, error) {
 	fieldNum := typ.NumField()
 	conflictedMap := map[string]struct{}{}
 	fieldMap := map[string]*structFieldSet{}
@@ -385,7 +407,7 @@ func compileStruct(typ *runtime.Type, structName, fieldName string, structTypeTo
 	if dec, exists := structTypeToDecoder[typeptr]; exists {
 		return dec, nil
 	}
-	structDec := newStructDecoder(structName, fieldName, fieldMap)
+	structDec := newStructDecoder(typ, structName, fieldName, fieldMap)
 	structTypeToDecoder[typeptr] = structDec
 	structName = typ.Name()
 	for i := 0; i < fieldNum; i++ {
@@ -394,8 +416,8 @@ func compileStruct(typ *runtime.Type, structName, fieldName string, structTypeTo
 			continue
 		}
 		isUnexportedField := unicode.IsLower([]rune(field.Name)[0])
-		tag := runtime.StructTagFromField(field)
-		dec, err := compile(runtime.Type2RType(field.Type), structName, field.Name, structTypeToDecoder)
+		tag := runtime.StructTagFromFieldWithNamingPolicy(field, namingPolicy)
+		dec, err := compile(runtime.Type2RType(field.Type), structName, field.Name, structTypeToDecoder, namingPolicy)
 		if err != nil {
 			return nil, err
 		}
@@ -421,6 +443,10 @@ func compileStruct(typ *runtime.Type, structName, fieldName string, structTypeTo
 				}
 				if dec, ok := contentDec.(*structDecoder); ok {
 					for k, v := range dec.fieldMap {
+						if k != v.key {
+							// the lowercase alias of the field is added with the field.
+							continue
+						}
 						if _, exists := conflictedMap[k]; exists {
 							// already conflicted key
 							continue
@@ -19,7 +19,7 @@ func CompileToGetDecoder(typ *runtime.Type) (Decoder, error) {
 		return dec, nil
 	}
 
-	dec, err := compileHead(typ, map[uintptr]Decoder{})
+	dec, err := compileDecoderOnce(typeptr, typ)
 	if err != nil {
 		return nil, err
 	}
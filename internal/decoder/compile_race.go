@@ -25,7 +25,7 @@ func CompileToGetDecoder(typ *runtime.Type) (Decoder, error) {
 	}
 	decMu.RUnlock()
 
-	dec, err := compileHead(typ, map[uintptr]Decoder{})
+	dec, err := compileDecoderOnce(typeptr, typ)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,143 @@
+package decoder
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Dump writes the tree of dec to w, one decoder per line indented by the depth.
+// The struct decoders are written with their key matching strategy and the fields with the keys and the offsets.
+// The fields of the struct decoders that match the keys case-insensitively are written without the aliases.
+func Dump(w io.Writer, dec Decoder) error {
+	d := &dumper{w: w, visited: map[*structDecoder]bool{}}
+	d.dump(dec, 0, "")
+	return d.err
+}
+
+type dumper struct {
+	w       io.Writer
+	visited map[*structDecoder]bool
+	err     error
+}
+
+func (d *dumper) printf(depth int, label, format string, args ...interface{}) {
+	if d.err != nil {
+		return
+	}
+	_, d.err = fmt.Fprintf(d.w, "%s%s%s\n", strings.Repeat("  ", depth), label, fmt.Sprintf(format, args...))
+}
+
+func (d *dumper) dump(dec Decoder, depth int, label string) {
+	switch dec := dec.(type) {
+	case *ptrDecoder:
+		d.printf(depth, label, "ptr *%s", dec.typ)
+		d.dump(dec.dec, depth+1, "")
+	case *structDecoder:
+		d.dumpStruct(dec, depth, label)
+	case *anonymousFieldDecoder:
+		d.printf(depth, label, "embedded *%s offset:%d", dec.structType, dec.offset)
+		d.dump(dec.dec, depth+1, "")
+	case *sliceDecoder:
+		d.printf(depth, label, "slice []%s elemSize:%d", dec.elemType, dec.size)
+		d.dump(dec.valueDecoder, depth+1, "")
+	case *arrayDecoder:
+		d.printf(depth, label, "array [%d]%s elemSize:%d", dec.alen, dec.elemType, dec.size)
+		d.dump(dec.valueDecoder, depth+1, "")
+	case *mapDecoder:
+		d.printf(depth, label, "map %s", dec.mapType)
+		d.dump(dec.keyDecoder, depth+1, "key: ")
+		d.dump(dec.valueDecoder, depth+1, "value: ")
+	case *bytesDecoder:
+		d.printf(depth, label, "bytes %s", dec.typ)
+	case *wrappedStringDecoder:
+		d.printf(depth, label, "quoted %s", dec.typ)
+		d.dump(dec.dec, depth+1, "")
+	case *intDecoder:
+		d.printf(depth, label, "%s", dec.kind)
+	case *uintDecoder:
+		d.printf(depth, label, "%s", dec.kind)
+	case *floatDecoder:
+		d.printf(depth, label, "float")
+	case *boolDecoder:
+		d.printf(depth, label, "bool")
+	case *stringDecoder:
+		d.printf(depth, label, "string")
+	case *numberDecoder:
+		d.printf(depth, label, "number")
+	case *interfaceDecoder:
+		d.printf(depth, label, "interface %s", dec.typ)
+	case *funcDecoder:
+		d.printf(depth, label, "func %s", dec.typ)
+	case *unmarshalJSONDecoder:
+		d.printf(depth, label, "UnmarshalJSON %s", dec.typ)
+	case *unmarshalTextDecoder:
+		d.printf(depth, label, "UnmarshalText %s", dec.typ)
+	case nil:
+		d.printf(depth, label, "<nil>")
+	default:
+		d.printf(depth, label, "%T", dec)
+	}
+}
+
+func (d *dumper) dumpStruct(dec *structDecoder, depth int, label string) {
+	if d.visited[dec] {
+		d.printf(depth, label, "struct %s (recursive)", dec.typ)
+		return
+	}
+	d.visited[dec] = true
+	defer delete(d.visited, dec)
+
+	// the bitmap matchers compare the keys with the lower case keys of the fields,
+	// so the fields are matched by any case of the keys and the aliases in fieldMap aren't used.
+	var matching string
+	switch {
+	case dec.keyBitmapUint8 != nil:
+		matching = "bitmap(uint8) caseInsensitive"
+	case dec.keyBitmapUint16 != nil:
+		matching = "bitmap(uint16) caseInsensitive"
+	default:
+		matching = "map"
+	}
+	caseInsensitive := matching != "map"
+	d.printf(depth, label, "struct %s keyMatching:%s", dec.typ, matching)
+
+	keys := map[*structFieldSet][]string{}
+	sets := []*structFieldSet{}
+	for key, set := range dec.fieldMap {
+		if _, exists := keys[set]; !exists {
+			sets = append(sets, set)
+		}
+		keys[set] = append(keys[set], key)
+	}
+	sort.Slice(sets, func(i, j int) bool {
+		if sets[i].offset != sets[j].offset {
+			return sets[i].offset < sets[j].offset
+		}
+		return sets[i].key < sets[j].key
+	})
+	for _, set := range sets {
+		var aliases []string
+		for _, key := range keys[set] {
+			if key != set.key && !caseInsensitive {
+				aliases = append(aliases, fmt.Sprintf("%q", key))
+			}
+		}
+		sort.Strings(aliases)
+		line := fmt.Sprintf("field %q offset:%d", set.key, set.offset)
+		if set.isTaggedKey {
+			line += " tagged"
+		}
+		if len(aliases) > 0 {
+			line += " aliases:" + strings.Join(aliases, ",")
+		}
+		if set.err != nil {
+			line += fmt.Sprintf(" err:%q", set.err)
+		}
+		d.printf(depth+1, "", "%s", line)
+		if set.dec != nil {
+			d.dump(set.dec, depth+2, "")
+		}
+	}
+}
@@ -178,8 +178,58 @@ func (d *intDecoder) decodeByte(buf []byte, cursor int64) ([]byte, int64, error)
 	}
 }
 
+// decodeStreamQuotedByte is like decodeStreamByte but also accepts a number enclosed in quotes.
+func (d *intDecoder) decodeStreamQuotedByte(s *Stream) ([]byte, error) {
+	if s.skipWhiteSpace() != '"' {
+		return d.decodeStreamByte(s)
+	}
+	s.cursor++
+	start := s.totalOffset()
+	num, err := d.decodeStreamByte(s)
+	if err != nil {
+		return nil, err
+	}
+	if num == nil {
+		return nil, d.typeError([]byte("null"), start)
+	}
+	// copy the number because reading the closing quote may overwrite the buffer.
+	num = append([]byte{}, num...)
+	if !s.equalChar('"') {
+		return nil, errors.ErrSyntax(fmt.Sprintf("expected closing quote of integer but found %q", s.char()), s.totalOffset())
+	}
+	s.cursor++
+	return num, nil
+}
+
+// decodeQuotedByte is like decodeByte but also accepts a number enclosed in quotes.
+func (d *intDecoder) decodeQuotedByte(buf []byte, cursor int64) ([]byte, int64, error) {
+	cursor = skipWhiteSpace(buf, cursor)
+	if buf[cursor] != '"' {
+		return d.decodeByte(buf, cursor)
+	}
+	num, c, err := d.decodeByte(buf, cursor+1)
+	if err != nil {
+		return nil, 0, err
+	}
+	if num == nil {
+		return nil, 0, d.typeError([]byte("null"), cursor+1)
+	}
+	if buf[c] != '"' {
+		return nil, 0, errors.ErrSyntax(fmt.Sprintf("expected closing quote of integer but found %q", buf[c]), c)
+	}
+	return num, c + 1, nil
+}
+
 func (d *intDecoder) DecodeStream(s *Stream, depth int64, p unsafe.Pointer) error {
-	bytes, err := d.decodeStreamByte(s)
+	var (
+		bytes []byte
+		err   error
+	)
+	if (s.Option.Flags & IntAsStringOption) != 0 {
+		bytes, err = d.decodeStreamQuotedByte(s)
+	} else {
+		bytes, err = d.decodeStreamByte(s)
+	}
 	if err != nil {
 		return err
 	}
@@ -210,7 +260,16 @@ func (d *intDecoder) DecodeStream(s *Stream, depth int64, p unsafe.Pointer) erro
 }
 
 func (d *intDecoder) Decode(ctx *RuntimeContext, cursor, depth int64, p unsafe.Pointer) (int64, error) {
-	bytes, c, err := d.decodeByte(ctx.Buf, cursor)
+	var (
+		bytes []byte
+		c     int64
+		err   error
+	)
+	if (ctx.Option.Flags & IntAsStringOption) != 0 {
+		bytes, c, err = d.decodeQuotedByte(ctx.Buf, cursor)
+	} else {
+		bytes, c, err = d.decodeByte(ctx.Buf, cursor)
+	}
 	if err != nil {
 		return 0, err
 	}
@@ -212,6 +212,9 @@ func (d *interfaceDecoder) decodeStreamEmptyInterface(s *Stream, depth int64, p
 	for {
 		switch c {
 		case '{':
+			if s.Option.Flags&OrderedMapOption != 0 {
+				return d.decodeStreamOrderedObject(s, depth, p)
+			}
 			var v map[string]interface{}
 			ptr := unsafe.Pointer(&v)
 			if err := d.mapDecoder.DecodeStream(s, depth, ptr); err != nil {
@@ -327,7 +330,7 @@ func (d *interfaceDecoder) DecodeStream(s *Stream, depth int64, p unsafe.Pointer
 		*(*interface{})(p) = nil
 		return nil
 	}
-	decoder, err := CompileToGetDecoder(typ)
+	decoder, err := CompileToGetDecoderWithOption(typ, s.Option)
 	if err != nil {
 		return err
 	}
@@ -392,7 +395,7 @@ func (d *interfaceDecoder) Decode(ctx *RuntimeContext, cursor, depth int64, p un
 		**(**interface{})(unsafe.Pointer(&p)) = nil
 		return cursor, nil
 	}
-	decoder, err := CompileToGetDecoder(typ)
+	decoder, err := CompileToGetDecoderWithOption(typ, ctx.Option)
 	if err != nil {
 		return 0, err
 	}
@@ -404,6 +407,9 @@ func (d *interfaceDecoder) decodeEmptyInterface(ctx *RuntimeContext, cursor, dep
 	cursor = skipWhiteSpace(buf, cursor)
 	switch buf[cursor] {
 	case '{':
+		if ctx.Option.Flags&OrderedMapOption != 0 {
+			return d.decodeOrderedObject(ctx, cursor, depth, p)
+		}
 		var v map[string]interface{}
 		ptr := unsafe.Pointer(&v)
 		cursor, err := d.mapDecoder.Decode(ctx, cursor, depth, ptr)
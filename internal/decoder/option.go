@@ -1,15 +1,34 @@
 package decoder
 
-import "context"
+import (
+	"context"
+
+	"github.com/goccy/go-json/internal/runtime"
+)
 
 type OptionFlags uint8
 
 const (
 	FirstWinOption OptionFlags = 1 << iota
 	ContextOption
+	NamingPolicyOption
+	IntAsStringOption
+	PreserveReferencesOption
+	OrderedMapOption
+	ValidateOption
 )
 
 type Option struct {
-	Flags   OptionFlags
-	Context context.Context
+	Flags        OptionFlags
+	Context      context.Context
+	NamingPolicy *runtime.NamingPolicy
+
+	// NewOrderedObject creates the value of the JSON object decoded into interface{} by OrderedMapOption.
+	NewOrderedObject func() OrderedObject
+
+	// Validate validates the bytes of the value before it is decoded by ValidateOption.
+	Validate func(data []byte) error
+
+	// references keeps the structs restored by PreserveReferencesOption for the ids.
+	references map[string]reference
 }
@@ -0,0 +1,95 @@
+package decoder
+
+import (
+	"unsafe"
+
+	"github.com/goccy/go-json/internal/errors"
+)
+
+// OrderedObject is the JSON object that keeps the order of its keys.
+// OrderedMapOption sets the members to it in the order of the document.
+type OrderedObject interface {
+	Set(key string, value interface{})
+}
+
+func (d *interfaceDecoder) decodeStreamOrderedObject(s *Stream, depth int64, p unsafe.Pointer) error {
+	depth++
+	if depth > maxDecodeNestingDepth {
+		return errors.ErrExceededMaxDepth(s.char(), s.cursor)
+	}
+
+	obj := s.Option.NewOrderedObject()
+	s.cursor++
+	if s.skipWhiteSpace() == '}' {
+		s.cursor++
+		*(*interface{})(p) = obj
+		return nil
+	}
+	for {
+		var key string
+		if err := d.stringDecoder.DecodeStream(s, depth, unsafe.Pointer(&key)); err != nil {
+			return err
+		}
+		s.skipWhiteSpace()
+		if !s.equalChar(':') {
+			return errors.ErrExpected("colon after object key", s.totalOffset())
+		}
+		s.cursor++
+		var value interface{}
+		if err := d.decodeStreamEmptyInterface(s, depth, unsafe.Pointer(&value)); err != nil {
+			return err
+		}
+		obj.Set(key, value)
+		s.skipWhiteSpace()
+		if s.equalChar('}') {
+			s.cursor++
+			*(*interface{})(p) = obj
+			return nil
+		}
+		if !s.equalChar(',') {
+			return errors.ErrExpected("comma after object value", s.totalOffset())
+		}
+		s.cursor++
+	}
+}
+
+func (d *interfaceDecoder) decodeOrderedObject(ctx *RuntimeContext, cursor, depth int64, p unsafe.Pointer) (int64, error) {
+	buf := ctx.Buf
+	depth++
+	if depth > maxDecodeNestingDepth {
+		return 0, errors.ErrExceededMaxDepth(buf[cursor], cursor)
+	}
+
+	obj := ctx.Option.NewOrderedObject()
+	cursor = skipWhiteSpace(buf, cursor+1)
+	if buf[cursor] == '}' {
+		**(**interface{})(unsafe.Pointer(&p)) = obj
+		return cursor + 1, nil
+	}
+	for {
+		var key string
+		keyCursor, err := d.stringDecoder.Decode(ctx, cursor, depth, unsafe.Pointer(&key))
+		if err != nil {
+			return 0, err
+		}
+		cursor = skipWhiteSpace(buf, keyCursor)
+		if buf[cursor] != ':' {
+			return 0, errors.ErrExpected("colon after object key", cursor)
+		}
+		var value interface{}
+		valueCursor, err := d.decodeEmptyInterface(ctx, cursor+1, depth, unsafe.Pointer(&value))
+		if err != nil {
+			return 0, err
+		}
+		obj.Set(key, value)
+		cursor = skipWhiteSpace(buf, valueCursor)
+		if buf[cursor] == '}' {
+			**(**interface{})(unsafe.Pointer(&p)) = obj
+			return cursor + 1, nil
+		}
+		if buf[cursor] != ',' {
+			return 0, errors.ErrExpected("comma after object value", cursor)
+		}
+		cursor++
+	}
+}
@@ -45,6 +45,16 @@ func (d *ptrDecoder) DecodeStream(s *Stream, depth int64, p unsafe.Pointer) erro
 		*(*unsafe.Pointer)(p) = nil
 		return nil
 	}
+	if (s.Option.Flags&PreserveReferencesOption) != 0 && s.char() == '{' {
+		ref, err := decodeStreamReference(s, d.typ)
+		if err != nil {
+			return err
+		}
+		if ref != nil {
+			*(*unsafe.Pointer)(p) = ref
+			return nil
+		}
+	}
 	var newptr unsafe.Pointer
 	if *(*unsafe.Pointer)(p) == nil {
 		newptr = unsafe_New(d.typ)
@@ -71,6 +81,16 @@ func (d *ptrDecoder) Decode(ctx *RuntimeContext, cursor, depth int64, p unsafe.P
 		cursor += 4
 		return cursor, nil
 	}
+	if (ctx.Option.Flags&PreserveReferencesOption) != 0 && buf[cursor] == '{' {
+		ref, c, err := decodeReference(ctx, cursor, d.typ)
+		if err != nil {
+			return 0, err
+		}
+		if ref != nil {
+			*(*unsafe.Pointer)(p) = ref
+			return c, nil
+		}
+	}
 	var newptr unsafe.Pointer
 	if *(*unsafe.Pointer)(p) == nil {
 		newptr = unsafe_New(d.typ)
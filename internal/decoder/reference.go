@@ -0,0 +1,164 @@
+package decoder
+
+import (
+	"bytes"
+	"fmt"
+	"unsafe"
+
+	"github.com/goccy/go-json/internal/errors"
+	"github.com/goccy/go-json/internal/runtime"
+)
+
+var (
+	referenceIDKey     = []byte(`"$id"`)
+	referenceKey       = []byte(`"$ref"`)
+	referenceIDDecoder = newStringDecoder("", "")
+)
+
+type reference struct {
+	typ *runtime.Type
+	ptr unsafe.Pointer
+}
+
+// ResetReferences forgets the structs restored by PreserveReferencesOption before decoding a new value.
+func (o *Option) ResetReferences() {
+	for id := range o.references {
+		delete(o.references, id)
+	}
+}
+
+func (o *Option) setReference(id []byte, typ *runtime.Type, p unsafe.Pointer) {
+	if o.references == nil {
+		o.references = map[string]reference{}
+	}
+	o.references[string(id)] = reference{typ: typ, ptr: p}
+}
+
+func (o *Option) reference(id []byte, typ *runtime.Type, offset int64) (unsafe.Pointer, error) {
+	ref, exists := o.references[string(id)]
+	if !exists {
+		return nil, errors.ErrSyntax(fmt.Sprintf("json: unknown reference id %q", id), offset)
+	}
+	if ref.typ != typ {
+		return nil, errors.ErrSyntax(fmt.Sprintf("json: reference id %q refers to %s instead of %s", id, ref.typ, typ), offset)
+	}
+	return ref.ptr, nil
+}
+
+// decodeReferenceMember decodes the member of key at cursor and returns its id and the cursor after it.
+// If the member at cursor isn't key, it returns nil id and cursor as it is.
+func decodeReferenceMember(buf []byte, cursor int64, key []byte) ([]byte, int64, error) {
+	if !bytes.HasPrefix(buf[cursor:], key) {
+		return nil, cursor, nil
+	}
+	cursor = skipWhiteSpace(buf, cursor+int64(len(key)))
+	if buf[cursor] != ':' {
+		return nil, 0, errors.ErrExpected("colon after object key", cursor)
+	}
+	id, c, err := referenceIDDecoder.decodeByte(buf, cursor+1)
+	if err != nil {
+		return nil, 0, err
+	}
+	if id == nil {
+		return nil, 0, errors.ErrExpected("reference id", cursor+1)
+	}
+	return id, c, nil
+}
+
+// decodeReference decodes the object referring to the struct restored already like {"$ref":"1"}.
+// The cursor points '{' of the object. If the object isn't a reference, it returns nil pointer.
+func decodeReference(ctx *RuntimeContext, cursor int64, typ *runtime.Type) (unsafe.Pointer, int64, error) {
+	buf := ctx.Buf
+	id, c, err := decodeReferenceMember(buf, skipWhiteSpace(buf, cursor+1), referenceKey)
+	if err != nil || id == nil {
+		return nil, cursor, err
+	}
+	c = skipWhiteSpace(buf, c)
+	if buf[c] != '}' {
+		return nil, 0, errors.ErrExpected("end of reference object", c)
+	}
+	p, err := ctx.Option.reference(id, typ, cursor)
+	if err != nil {
+		return nil, 0, err
+	}
+	return p, c + 1, nil
+}
+
+// decodeReferenceID registers p as the struct of the id if the object begins with the "$id" member,
+// and returns the cursor after the member and the following comma.
+// The cursor points the first member of the object.
+func decodeReferenceID(ctx *RuntimeContext, cursor int64, typ *runtime.Type, p unsafe.Pointer) (int64, error) {
+	buf := ctx.Buf
+	id, c, err := decodeReferenceMember(buf, cursor, referenceIDKey)
+	if err != nil || id == nil {
+		return cursor, err
+	}
+	ctx.Option.setReference(id, typ, p)
+	c = skipWhiteSpace(buf, c)
+	if buf[c] == ',' {
+		c++
+	}
+	return c, nil
+}
+
+// streamHasPrefix reports whether the data of s at the cursor begins with prefix, reading the data as needed.
+func streamHasPrefix(s *Stream, prefix []byte) bool {
+	for s.length-s.cursor < int64(len(prefix)) {
+		if !s.read() {
+			break
+		}
+	}
+	return bytes.HasPrefix(s.buf[s.cursor:s.length], prefix)
+}
+
+func decodeStreamReferenceMember(s *Stream, key []byte) ([]byte, error) {
+	if !streamHasPrefix(s, key) {
+		return nil, nil
+	}
+	s.cursor += int64(len(key))
+	if s.skipWhiteSpace() != ':' {
+		return nil, errors.ErrExpected("colon after object key", s.totalOffset())
+	}
+	s.cursor++
+	id, err := referenceIDDecoder.decodeStreamByte(s)
+	if err != nil {
+		return nil, err
+	}
+	if id == nil {
+		return nil, errors.ErrExpected("reference id", s.totalOffset())
+	}
+	return id, nil
+}
+
+// decodeStreamReference is like decodeReference, but decodes the object from s.
+func decodeStreamReference(s *Stream, typ *runtime.Type) (unsafe.Pointer, error) {
+	start := s.cursor
+	s.cursor++
+	s.skipWhiteSpace()
+	id, err := decodeStreamReferenceMember(s, referenceKey)
+	if err != nil {
+		return nil, err
+	}
+	if id == nil {
+		s.cursor = start
+		return nil, nil
+	}
+	if s.skipWhiteSpace() != '}' {
+		return nil, errors.ErrExpected("end of reference object", s.totalOffset())
+	}
+	s.cursor++
+	return s.Option.reference(id, typ, s.totalOffset())
+}
+
+// decodeStreamReferenceID is like decodeReferenceID, but decodes the member from s.
+func decodeStreamReferenceID(s *Stream, typ *runtime.Type, p unsafe.Pointer) error {
+	id, err := decodeStreamReferenceMember(s, referenceIDKey)
+	if err != nil || id == nil {
+		return err
+	}
+	s.Option.setReference(id, typ, p)
+	if s.skipWhiteSpace() == ',' {
+		s.cursor++
+	}
+	return nil
+}
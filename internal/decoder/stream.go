@@ -26,6 +26,10 @@ type Stream struct {
 	UseNumber             bool
 	DisallowUnknownFields bool
 	Option                *Option
+
+	// needsComma has an entry per container entered by EnterContainer, and reports whether
+	// the next value of the container must be preceded by a value separator.
+	needsComma []bool
 }
 
 func NewStream(r io.Reader) *Stream {
@@ -52,6 +56,10 @@ func (s *Stream) Buffered() io.Reader {
 }
 
 func (s *Stream) PrepareForDecode() error {
+	if len(s.needsComma) > 0 {
+		_, err := s.nextValueChar()
+		return err
+	}
 	for {
 		switch s.char() {
 		case ' ', '\t', '\r', '\n':
@@ -178,6 +186,133 @@ END:
 	return nil, io.EOF
 }
 
+// nextValueChar skips whitespace and a value separator and returns the first character of the next value.
+// Inside a container entered by EnterContainer, the separator is required between the values and rejected elsewhere.
+func (s *Stream) nextValueChar() (byte, error) {
+	if len(s.needsComma) == 0 {
+		if err := s.PrepareForDecode(); err != nil {
+			return nul, err
+		}
+		return s.skipWhiteSpace(), nil
+	}
+	c := s.skipWhiteSpace()
+	last := len(s.needsComma) - 1
+	if s.needsComma[last] {
+		if c != ',' {
+			if c == nul {
+				return nul, errors.ErrUnexpectedEndOfJSON("value separator", s.totalOffset())
+			}
+			return nul, errors.ErrExpected("comma after object member or array element", s.totalOffset())
+		}
+		s.cursor++
+		s.needsComma[last] = false
+		c = s.skipWhiteSpace()
+	} else if c == ',' {
+		return nul, errors.ErrInvalidCharacter(c, "value", s.totalOffset())
+	}
+	if c == nul {
+		return nul, errors.ErrUnexpectedEndOfJSON("value", s.totalOffset())
+	}
+	return c, nil
+}
+
+// EndValue records that a value of the current container is consumed, so that the next one requires a value separator.
+func (s *Stream) EndValue() {
+	if len(s.needsComma) > 0 {
+		s.needsComma[len(s.needsComma)-1] = true
+	}
+}
+
+// EnterContainer consumes the beginning delimiter ( '{' or '[' ) of the next value.
+func (s *Stream) EnterContainer(delim byte) error {
+	c, err := s.nextValueChar()
+	if err != nil {
+		return err
+	}
+	if c != delim {
+		if delim == '{' {
+			return errors.ErrInvalidCharacter(c, "beginning of object", s.totalOffset())
+		}
+		return errors.ErrInvalidCharacter(c, "beginning of array", s.totalOffset())
+	}
+	s.cursor++
+	s.needsComma = append(s.needsComma, false)
+	return nil
+}
+
+// LeaveContainer consumes the end delimiter ( '}' or ']' ) of the current object or array.
+func (s *Stream) LeaveContainer(delim byte) error {
+	c := s.skipWhiteSpace()
+	if c == nul {
+		return errors.ErrUnexpectedEndOfJSON("end of container", s.totalOffset())
+	}
+	if c != delim {
+		if delim == '}' {
+			return errors.ErrExpected("end of object", s.totalOffset())
+		}
+		return errors.ErrExpected("end of array", s.totalOffset())
+	}
+	s.cursor++
+	if len(s.needsComma) > 0 {
+		s.needsComma = s.needsComma[:len(s.needsComma)-1]
+	}
+	s.EndValue()
+	return nil
+}
+
+// ReadKey reads an object key and the following colon.
+func (s *Stream) ReadKey() (string, error) {
+	c, err := s.nextValueChar()
+	if err != nil {
+		return "", err
+	}
+	if c != '"' {
+		return "", errors.ErrInvalidCharacter(c, "object key", s.totalOffset())
+	}
+	bytes, err := stringBytes(s)
+	if err != nil {
+		return "", err
+	}
+	key := string(bytes)
+	if s.skipWhiteSpace() != ':' {
+		return "", errors.ErrExpected("colon after object key", s.totalOffset())
+	}
+	s.cursor++
+	return key, nil
+}
+
+// SkipNextValue discards the next value.
+func (s *Stream) SkipNextValue() error {
+	if _, err := s.nextValueChar(); err != nil {
+		return err
+	}
+	if err := s.skipValue(0); err != nil {
+		return err
+	}
+	s.EndValue()
+	return nil
+}
+
+// PeekNextValue returns the bytes of the next value without consuming it.
+// The bytes are valid until the stream reads the input again.
+func (s *Stream) PeekNextValue() ([]byte, error) {
+	if _, err := s.nextValueChar(); err != nil {
+		return nil, err
+	}
+	start := s.cursor
+	if err := s.skipValue(0); err != nil {
+		return nil, err
+	}
+	value := s.buf[start:s.cursor]
+	s.cursor = start
+	return value, nil
+}
+
+// PeekValue returns the first character of the next value without consuming it.
+func (s *Stream) PeekValue() (byte, error) {
+	return s.nextValueChar()
+}
+
 func (s *Stream) reset() {
 	s.offset += s.cursor
 	s.buf = s.buf[s.cursor:]
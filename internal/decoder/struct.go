@@ -11,6 +11,7 @@ import (
 	"unsafe"
 
 	"github.com/goccy/go-json/internal/errors"
+	"github.com/goccy/go-json/internal/runtime"
 )
 
 type structFieldSet struct {
@@ -24,6 +25,7 @@ type structFieldSet struct {
 }
 
 type structDecoder struct {
+	typ                *runtime.Type
 	fieldMap           map[string]*structFieldSet
 	fieldUniqueNameNum int
 	stringDecoder      *stringDecoder
@@ -51,8 +53,9 @@ func init() {
 	}
 }
 
-func newStructDecoder(structName, fieldName string, fieldMap map[string]*structFieldSet) *structDecoder {
+func newStructDecoder(typ *runtime.Type, structName, fieldName string, fieldMap map[string]*structFieldSet) *structDecoder {
 	return &structDecoder{
+		typ:              typ,
 		fieldMap:         fieldMap,
 		stringDecoder:    newStringDecoder(structName, fieldName),
 		structName:       structName,
@@ -657,6 +660,12 @@ func (d *structDecoder) DecodeStream(s *Stream, depth int64, p unsafe.Pointer) e
 		}
 	}
 	s.cursor++
+	if (s.Option.Flags & PreserveReferencesOption) != 0 {
+		s.skipWhiteSpace()
+		if err := decodeStreamReferenceID(s, d.typ, p); err != nil {
+			return err
+		}
+	}
 	if s.skipWhiteSpace() == '}' {
 		s.cursor++
 		return nil
@@ -744,6 +753,13 @@ func (d *structDecoder) Decode(ctx *RuntimeContext, cursor, depth int64, p unsaf
 	}
 	cursor++
 	cursor = skipWhiteSpace(buf, cursor)
+	if (ctx.Option.Flags & PreserveReferencesOption) != 0 {
+		c, err := decodeReferenceID(ctx, cursor, d.typ, p)
+		if err != nil {
+			return 0, err
+		}
+		cursor = skipWhiteSpace(buf, c)
+	}
 	if buf[cursor] == '}' {
 		cursor++
 		return cursor, nil
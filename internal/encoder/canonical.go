@@ -0,0 +1,684 @@
+package encoder
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+	"unsafe"
+
+	"github.com/goccy/go-json/internal/errors"
+)
+
+// This file implements the output rules of the JSON Canonicalization Scheme ( RFC 8785 ).
+
+// AppendCanonicalString appends s as a JSON string with the minimal escaping required by RFC 8785.
+// Invalid UTF-8 bytes are replaced by U+FFFD.
+func AppendCanonicalString(_ *RuntimeContext, b []byte, s string) []byte {
+	b = append(b, '"')
+	i := 0
+	for j := 0; j < len(s); {
+		c := s[j]
+		if c >= utf8.RuneSelf {
+			r, size := utf8.DecodeRuneInString(s[j:])
+			if r == utf8.RuneError && size == 1 {
+				b = append(b, s[i:j]...)
+				b = append(b, "\ufffd"...)
+				j += size
+				i = j
+				continue
+			}
+			j += size
+			continue
+		}
+		if c >= 0x20 && c != '"' && c != '\\' {
+			j++
+			continue
+		}
+		b = append(b, s[i:j]...)
+		switch c {
+		case '"', '\\':
+			b = append(b, '\\', c)
+		case '\b':
+			b = append(b, '\\', 'b')
+		case '\f':
+			b = append(b, '\\', 'f')
+		case '\n':
+			b = append(b, '\\', 'n')
+		case '\r':
+			b = append(b, '\\', 'r')
+		case '\t':
+			b = append(b, '\\', 't')
+		default:
+			b = append(b, `\u00`...)
+			b = append(b, hex[c>>4], hex[c&0xF])
+		}
+		j++
+		i = j
+	}
+	return append(append(b, s[i:]...), '"')
+}
+
+// AppendCanonicalFloat64 appends v in the format of ECMAScript's Number.prototype.toString as required by RFC 8785.
+func AppendCanonicalFloat64(_ *RuntimeContext, b []byte, v float64) []byte {
+	return appendESNumber(b, v, 64)
+}
+
+// AppendCanonicalFloat32 appends v like AppendCanonicalFloat64,
+// but uses the shortest digits that represent the float32 value.
+func AppendCanonicalFloat32(_ *RuntimeContext, b []byte, v float32) []byte {
+	return appendESNumber(b, float64(v), 32)
+}
+
+func appendESNumber(b []byte, v float64, bitSize int) []byte {
+	if v == 0 {
+		// includes negative zero.
+		return append(b, '0')
+	}
+	if v < 0 {
+		b = append(b, '-')
+		v = -v
+	}
+	var buf [32]byte
+	s := strconv.AppendFloat(buf[:0], v, 'e', -1, bitSize)
+
+	// s has the form d[.ddd]e±dd
+	var (
+		digits [24]byte
+		k      int
+		exp    int
+		expNeg bool
+	)
+	i := 0
+	for ; s[i] != 'e'; i++ {
+		if s[i] != '.' {
+			digits[k] = s[i]
+			k++
+		}
+	}
+	i++
+	if s[i] == '-' {
+		expNeg = true
+	}
+	for i++; i < len(s); i++ {
+		exp = exp*10 + int(s[i]-'0')
+	}
+	if expNeg {
+		exp = -exp
+	}
+	n := exp + 1 // position of the decimal point
+	switch {
+	case k <= n && n <= 21:
+		b = append(b, digits[:k]...)
+		for i := 0; i < n-k; i++ {
+			b = append(b, '0')
+		}
+	case 0 < n && n <= 21:
+		b = append(b, digits[:n]...)
+		b = append(b, '.')
+		b = append(b, digits[n:k]...)
+	case -6 < n && n <= 0:
+		b = append(b, '0', '.')
+		for i := 0; i < -n; i++ {
+			b = append(b, '0')
+		}
+		b = append(b, digits[:k]...)
+	default:
+		b = append(b, digits[0])
+		if k > 1 {
+			b = append(b, '.')
+			b = append(b, digits[1:k]...)
+		}
+		b = append(b, 'e')
+		if n-1 < 0 {
+			b = append(b, '-')
+			b = strconv.AppendInt(b, int64(1-n), 10)
+		} else {
+			b = append(b, '+')
+			b = strconv.AppendInt(b, int64(n-1), 10)
+		}
+	}
+	return b
+}
+
+// AppendCanonicalNumber appends the number literal n in the canonical number format.
+func AppendCanonicalNumber(_ *RuntimeContext, b []byte, n json.Number) ([]byte, error) {
+	if len(n) == 0 {
+		return append(b, '0'), nil
+	}
+	return appendCanonicalNumberLiteral(b, []byte(n))
+}
+
+// appendCanonicalNumberLiteral appends lit in the canonical number format,
+// which is the format of the IEEE 754 double precision value nearest to lit.
+func appendCanonicalNumberLiteral(b []byte, lit []byte) ([]byte, error) {
+	if !isValidNumberLiteral(lit) {
+		return nil, fmt.Errorf("json: invalid number literal %q", lit)
+	}
+	f, err := strconv.ParseFloat(string(lit), 64)
+	if err != nil || math.IsInf(f, 0) {
+		return nil, fmt.Errorf("json: number %s is out of range", lit)
+	}
+	return appendESNumber(b, f, 64), nil
+}
+
+// maxExactInt is the maximum magnitude of the integers that IEEE 754 double precision values represent exactly.
+const maxExactInt = 1 << 53
+
+// AppendCanonicalInt appends the signed integer u64 as the nearest IEEE 754 double precision value as required by RFC 8785,
+// e.g. 9007199254740993 is written as 9007199254740992.
+func AppendCanonicalInt(ctx *RuntimeContext, b []byte, u64 uint64, code *Opcode) []byte {
+	mask := numMask(code.NumBitSize)
+	n := u64 & mask
+	if (u64>>(code.NumBitSize-1))&1 == 1 {
+		n = -n & mask
+		if n > maxExactInt {
+			return appendESNumber(b, -float64(n), 64)
+		}
+	} else if n > maxExactInt {
+		return appendESNumber(b, float64(n), 64)
+	}
+	return AppendInt(ctx, b, u64, code)
+}
+
+// AppendCanonicalUint appends the unsigned integer u64 as the nearest IEEE 754 double precision value like AppendCanonicalInt.
+func AppendCanonicalUint(ctx *RuntimeContext, b []byte, u64 uint64, code *Opcode) []byte {
+	if n := u64 & numMask(code.NumBitSize); n > maxExactInt {
+		return appendESNumber(b, float64(n), 64)
+	}
+	return AppendUint(ctx, b, u64, code)
+}
+
+// AppendCanonicalIntString is like AppendIntString, but the unquoted integers are written by AppendCanonicalInt.
+// The quoted integers are strings, so they are written exactly.
+func AppendCanonicalIntString(ctx *RuntimeContext, b []byte, u64 uint64, code *Opcode) []byte {
+	if !IsQuotedInt(ctx, u64, code) {
+		return AppendCanonicalInt(ctx, b, u64, code)
+	}
+	return AppendIntString(ctx, b, u64, code)
+}
+
+// AppendCanonicalUintString is like AppendUintString, but the unquoted integers are written by AppendCanonicalUint.
+func AppendCanonicalUintString(ctx *RuntimeContext, b []byte, u64 uint64, code *Opcode) []byte {
+	if !IsQuotedUint(ctx, u64, code) {
+		return AppendCanonicalUint(ctx, b, u64, code)
+	}
+	return AppendUintString(ctx, b, u64, code)
+}
+
+func isValidNumberLiteral(s []byte) bool {
+	i := 0
+	if i < len(s) && s[i] == '-' {
+		i++
+	}
+	if i >= len(s) {
+		return false
+	}
+	switch {
+	case s[i] == '0':
+		i++
+	case '1' <= s[i] && s[i] <= '9':
+		for i < len(s) && '0' <= s[i] && s[i] <= '9' {
+			i++
+		}
+	default:
+		return false
+	}
+	if i < len(s) && s[i] == '.' {
+		i++
+		if i >= len(s) || s[i] < '0' || '9' < s[i] {
+			return false
+		}
+		for i < len(s) && '0' <= s[i] && s[i] <= '9' {
+			i++
+		}
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
+			i++
+		}
+		if i >= len(s) || s[i] < '0' || '9' < s[i] {
+			return false
+		}
+		for i < len(s) && '0' <= s[i] && s[i] <= '9' {
+			i++
+		}
+	}
+	return i == len(s)
+}
+
+// utf16Reader reads UTF-16 code units from an encoded JSON string.
+type utf16Reader struct {
+	s       string
+	cursor  int
+	pending uint16
+}
+
+// next returns the next code unit. ok is false at the closing double quote or the end of s.
+func (r *utf16Reader) next() (uint16, bool) {
+	if r.pending != 0 {
+		u := r.pending
+		r.pending = 0
+		return u, true
+	}
+	if r.cursor >= len(r.s) {
+		return 0, false
+	}
+	c := r.s[r.cursor]
+	switch {
+	case c == '"':
+		return 0, false
+	case c == '\\' && r.cursor+1 < len(r.s):
+		r.cursor += 2
+		switch r.s[r.cursor-1] {
+		case 'b':
+			return '\b', true
+		case 'f':
+			return '\f', true
+		case 'n':
+			return '\n', true
+		case 'r':
+			return '\r', true
+		case 't':
+			return '\t', true
+		case 'u':
+			if r.cursor+4 > len(r.s) {
+				return utf8.RuneError, true
+			}
+			var u uint16
+			for _, c := range []byte(r.s[r.cursor : r.cursor+4]) {
+				switch {
+				case '0' <= c && c <= '9':
+					c -= '0'
+				case 'a' <= c && c <= 'f':
+					c = c - 'a' + 10
+				case 'A' <= c && c <= 'F':
+					c = c - 'A' + 10
+				default:
+					r.cursor += 4
+					return utf8.RuneError, true
+				}
+				u = u<<4 | uint16(c)
+			}
+			r.cursor += 4
+			return u, true
+		}
+		return uint16(r.s[r.cursor-1]), true
+	case c < utf8.RuneSelf:
+		r.cursor++
+		return uint16(c), true
+	}
+	rn, size := utf8.DecodeRuneInString(r.s[r.cursor:])
+	r.cursor += size
+	if rn >= 0x10000 {
+		hi, lo := utf16.EncodeRune(rn)
+		r.pending = uint16(lo)
+		return uint16(hi), true
+	}
+	return uint16(rn), true
+}
+
+// compareJSONStringUTF16 compares two encoded JSON strings ( with or without the opening double quote )
+// by the UTF-16 code units of their decoded values, as required for sorting object keys by RFC 8785.
+func compareJSONStringUTF16(a, b []byte) int {
+	if len(a) > 0 && a[0] == '"' {
+		a = a[1:]
+	}
+	if len(b) > 0 && b[0] == '"' {
+		b = b[1:]
+	}
+	ra := utf16Reader{s: *(*string)(unsafe.Pointer(&a))}
+	rb := utf16Reader{s: *(*string)(unsafe.Pointer(&b))}
+	for {
+		ua, okA := ra.next()
+		ub, okB := rb.next()
+		switch {
+		case !okA && !okB:
+			return 0
+		case !okA:
+			return -1
+		case !okB:
+			return 1
+		case ua != ub:
+			if ua < ub {
+				return -1
+			}
+			return 1
+		}
+	}
+}
+
+// decodeJSONString decodes an encoded JSON string value without its surrounding double quotes.
+func decodeJSONString(s []byte) string {
+	r := utf16Reader{s: string(s)}
+	units := make([]uint16, 0, len(s))
+	for {
+		u, ok := r.next()
+		if !ok {
+			break
+		}
+		units = append(units, u)
+	}
+	return string(utf16.Decode(units))
+}
+
+// AppendCanonicalStructHead appends '{' and starts collecting the members of the object for sorting.
+func AppendCanonicalStructHead(ctx *RuntimeContext, b []byte) []byte {
+	ctx.canonicalFrames = append(ctx.canonicalFrames, len(ctx.canonicalPos))
+	ctx.canonicalPos = append(ctx.canonicalPos, len(b))
+	return append(b, '{')
+}
+
+// AppendCanonicalStructKey appends the key of a struct field and records the position of the member.
+func AppendCanonicalStructKey(ctx *RuntimeContext, code *Opcode, b []byte) []byte {
+	ctx.canonicalPos = append(ctx.canonicalPos, len(b))
+	return appendCanonicalKey(b, code.Key)
+}
+
+func appendCanonicalKey(b []byte, key string) []byte {
+	for i := 0; i < len(key); i++ {
+		if key[i] == '\\' {
+			// the key was escaped at compile time, so re-encode it with the canonical escaping.
+			b = AppendCanonicalString(nil, b, decodeJSONString([]byte(key[1:len(key)-2])))
+			return append(b, ':')
+		}
+	}
+	return append(b, key...)
+}
+
+// PopCanonicalObject discards the member positions of the innermost object
+// whose members are already sorted ( e.g. a sorted map ).
+func PopCanonicalObject(ctx *RuntimeContext) {
+	last := len(ctx.canonicalFrames) - 1
+	ctx.canonicalPos = ctx.canonicalPos[:ctx.canonicalFrames[last]]
+	ctx.canonicalFrames = ctx.canonicalFrames[:last]
+}
+
+type canonicalMember struct {
+	start int
+	end   int
+}
+
+// SortCanonicalObject sorts the members of the innermost object, which b ends with ( followed by a comma ),
+// by their keys in UTF-16 code unit order.
+func SortCanonicalObject(ctx *RuntimeContext, b []byte) []byte {
+	last := len(ctx.canonicalFrames) - 1
+	frame := ctx.canonicalFrames[last]
+	keys := ctx.canonicalPos[frame+1:]
+	defer func() {
+		ctx.canonicalPos = ctx.canonicalPos[:frame]
+		ctx.canonicalFrames = ctx.canonicalFrames[:last]
+	}()
+	if len(keys) < 2 {
+		return b
+	}
+	end := len(b) - 2 // b ends with "},"
+	members := ctx.canonicalMembers[:0]
+	for i, pos := range keys {
+		memberEnd := end
+		if i+1 < len(keys) {
+			memberEnd = keys[i+1] - 1 // exclude the comma
+		}
+		members = append(members, canonicalMember{start: pos, end: memberEnd})
+	}
+	ctx.canonicalMembers = members
+	less := func(i, j int) bool {
+		return compareJSONStringUTF16(b[members[i].start:members[i].end], b[members[j].start:members[j].end]) < 0
+	}
+	if sort.SliceIsSorted(members, less) {
+		return b
+	}
+	sort.SliceStable(members, less)
+	buf := ctx.canonicalBuf[:0]
+	for i, m := range members {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, b[m.start:m.end]...)
+	}
+	copy(b[keys[0]:end], buf)
+	ctx.canonicalBuf = buf
+	return b
+}
+
+// CanonicalizeAppended rewrites the JSON value that was appended to b after offset in the canonical form.
+func CanonicalizeAppended(ctx *RuntimeContext, b []byte, offset int) ([]byte, error) {
+	src := append(ctx.canonicalBuf[:0], b[offset:]...)
+	src = append(src, nul)
+	ctx.canonicalBuf = src
+	dst, cursor, err := canonicalizeValue(b[:offset], src, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateEndBuf(src, cursor); err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+// Canonicalize appends to dst the canonical form ( RFC 8785 ) of the JSON-encoded src.
+func Canonicalize(dst, src []byte) ([]byte, error) {
+	if len(src) == 0 {
+		return nil, errors.ErrUnexpectedEndOfJSON("", 0)
+	}
+	buf := make([]byte, len(src)+1)
+	copy(buf, src)
+	dst, cursor, err := canonicalizeValue(dst, buf, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateEndBuf(buf, cursor); err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+func canonicalizeValue(dst, src []byte, cursor int64) ([]byte, int64, error) {
+	cursor = skipWhiteSpace(src, cursor)
+	switch src[cursor] {
+	case '{':
+		return canonicalizeObject(dst, src, cursor)
+	case '[':
+		return canonicalizeArray(dst, src, cursor)
+	case '"':
+		s, next, err := canonicalizeStringValue(src, cursor)
+		if err != nil {
+			return nil, 0, err
+		}
+		return AppendCanonicalString(nil, dst, s), next, nil
+	case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		start := cursor
+		for numberChars[src[cursor]] {
+			cursor++
+		}
+		dst, err := appendCanonicalNumberLiteral(dst, src[start:cursor])
+		if err != nil {
+			return nil, 0, errors.ErrSyntax(err.Error(), start)
+		}
+		return dst, cursor, nil
+	case 't':
+		return compactTrue(dst, src, cursor)
+	case 'f':
+		return compactFalse(dst, src, cursor)
+	case 'n':
+		return compactNull(dst, src, cursor)
+	case nul:
+		return nil, 0, errors.ErrUnexpectedEndOfJSON("value", cursor)
+	}
+	return nil, 0, errors.ErrSyntax(fmt.Sprintf("unexpected character '%c'", src[cursor]), cursor)
+}
+
+var numberChars = [256]bool{
+	'0': true, '1': true, '2': true, '3': true, '4': true,
+	'5': true, '6': true, '7': true, '8': true, '9': true,
+	'.': true, 'e': true, 'E': true, '+': true, '-': true,
+}
+
+type canonicalObjectMember struct {
+	key   string
+	value []byte
+}
+
+func canonicalizeObject(dst, src []byte, cursor int64) ([]byte, int64, error) {
+	cursor = skipWhiteSpace(src, cursor+1)
+	if src[cursor] == '}' {
+		return append(dst, '{', '}'), cursor + 1, nil
+	}
+	var members []canonicalObjectMember
+	seen := map[string]struct{}{}
+	for {
+		cursor = skipWhiteSpace(src, cursor)
+		if src[cursor] != '"' {
+			return nil, 0, errors.ErrExpected("object key", cursor)
+		}
+		key, next, err := canonicalizeStringValue(src, cursor)
+		if err != nil {
+			return nil, 0, err
+		}
+		if _, exists := seen[key]; exists {
+			return nil, 0, errors.ErrSyntax(fmt.Sprintf("duplicate object key %q", key), cursor)
+		}
+		seen[key] = struct{}{}
+		cursor = skipWhiteSpace(src, next)
+		if src[cursor] != ':' {
+			return nil, 0, errors.ErrExpected("colon after object key", cursor)
+		}
+		value, next, err := canonicalizeValue(nil, src, cursor+1)
+		if err != nil {
+			return nil, 0, err
+		}
+		members = append(members, canonicalObjectMember{key: key, value: value})
+		cursor = skipWhiteSpace(src, next)
+		if src[cursor] == '}' {
+			cursor++
+			break
+		}
+		if src[cursor] != ',' {
+			return nil, 0, errors.ErrExpected("comma after object value", cursor)
+		}
+		cursor++
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return compareUTF16(members[i].key, members[j].key) < 0
+	})
+	dst = append(dst, '{')
+	for i, m := range members {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = AppendCanonicalString(nil, dst, m.key)
+		dst = append(dst, ':')
+		dst = append(dst, m.value...)
+	}
+	return append(dst, '}'), cursor, nil
+}
+
+func canonicalizeArray(dst, src []byte, cursor int64) ([]byte, int64, error) {
+	dst = append(dst, '[')
+	cursor = skipWhiteSpace(src, cursor+1)
+	if src[cursor] == ']' {
+		return append(dst, ']'), cursor + 1, nil
+	}
+	for {
+		var err error
+		dst, cursor, err = canonicalizeValue(dst, src, cursor)
+		if err != nil {
+			return nil, 0, err
+		}
+		cursor = skipWhiteSpace(src, cursor)
+		if src[cursor] == ']' {
+			return append(dst, ']'), cursor + 1, nil
+		}
+		if src[cursor] != ',' {
+			return nil, 0, errors.ErrExpected("comma after array element", cursor)
+		}
+		dst = append(dst, ',')
+		cursor++
+	}
+}
+
+// canonicalizeStringValue validates the JSON string that starts at cursor and returns its decoded value.
+func canonicalizeStringValue(src []byte, cursor int64) (string, int64, error) {
+	start := cursor + 1
+	hasEscape := false
+	for cursor++; ; cursor++ {
+		switch c := src[cursor]; {
+		case c == '"':
+			raw := src[start:cursor]
+			if !hasEscape {
+				return string(raw), cursor + 1, nil
+			}
+			return decodeJSONString(raw), cursor + 1, nil
+		case c == '\\':
+			hasEscape = true
+			cursor++
+			switch src[cursor] {
+			case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+			case 'u':
+				for i := int64(1); i <= 4; i++ {
+					if !isHexChar(src[cursor+i]) {
+						return "", 0, errors.ErrSyntax("invalid escape sequence in string", cursor)
+					}
+				}
+				cursor += 4
+			default:
+				return "", 0, errors.ErrSyntax("invalid escape sequence in string", cursor)
+			}
+		case c == nul:
+			return "", 0, errors.ErrUnexpectedEndOfJSON("string", cursor)
+		case c < 0x20:
+			return "", 0, errors.ErrSyntax("invalid control character in string", cursor)
+		}
+	}
+}
+
+func isHexChar(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
+
+// compareUTF16 compares a and b by their UTF-16 code units.
+func compareUTF16(a, b string) int {
+	ra := utf16Reader{s: a}
+	rb := utf16Reader{s: b}
+	for {
+		ua, okA := ra.nextRaw()
+		ub, okB := rb.nextRaw()
+		switch {
+		case !okA && !okB:
+			return 0
+		case !okA:
+			return -1
+		case !okB:
+			return 1
+		case ua != ub:
+			if ua < ub {
+				return -1
+			}
+			return 1
+		}
+	}
+}
+
+// nextRaw is like next, but reads a decoded ( not JSON-encoded ) string.
+func (r *utf16Reader) nextRaw() (uint16, bool) {
+	if r.pending != 0 {
+		u := r.pending
+		r.pending = 0
+		return u, true
+	}
+	if r.cursor >= len(r.s) {
+		return 0, false
+	}
+	rn, size := utf8.DecodeRuneInString(r.s[r.cursor:])
+	r.cursor += size
+	if rn >= 0x10000 {
+		hi, lo := utf16.EncodeRune(rn)
+		r.pending = uint16(lo)
+		return uint16(hi), true
+	}
+	return uint16(rn), true
+}
@@ -0,0 +1,191 @@
+package encoder
+
+import (
+	"fmt"
+
+	"github.com/goccy/go-json/internal/errors"
+)
+
+// ColorizeBytes appends the JSON-encoded src to dst with the colors of scheme.
+// src is indented by prefix and indentStr like Indent, or compacted if both of them are empty.
+func ColorizeBytes(dst, src []byte, scheme *ColorScheme, prefix, indentStr string) ([]byte, error) {
+	if len(src) == 0 {
+		return nil, errors.ErrUnexpectedEndOfJSON("", 0)
+	}
+
+	srcCtx, srcBuf := takeIndentSrcRuntimeContext(src)
+	defer ReleaseRuntimeContext(srcCtx)
+
+	dst, cursor, err := colorizeValue(dst, srcBuf, 0, 0, scheme, []byte(prefix), []byte(indentStr))
+	if err != nil {
+		return nil, err
+	}
+	if err := validateEndBuf(srcBuf, cursor); err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+func colorizeValue(
+	dst []byte,
+	src []byte,
+	indentNum int,
+	cursor int64,
+	scheme *ColorScheme,
+	prefix []byte,
+	indentBytes []byte) ([]byte, int64, error) {
+	cursor = skipWhiteSpace(src, cursor)
+	var (
+		format ColorFormat
+		err    error
+	)
+	switch src[cursor] {
+	case '{':
+		return colorizeObject(dst, src, indentNum, cursor, scheme, prefix, indentBytes)
+	case '[':
+		return colorizeArray(dst, src, indentNum, cursor, scheme, prefix, indentBytes)
+	case '"':
+		format = scheme.String
+		dst, cursor, err = compactString(append(dst, format.Header...), src, cursor, scheme.EscapeHTML)
+	case '-', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		format = numberColorFormat(src, cursor, scheme)
+		dst, cursor, err = compactNumber(append(dst, format.Header...), src, cursor)
+	case 't':
+		format = scheme.Bool
+		dst, cursor, err = compactTrue(append(dst, format.Header...), src, cursor)
+	case 'f':
+		format = scheme.Bool
+		dst, cursor, err = compactFalse(append(dst, format.Header...), src, cursor)
+	case 'n':
+		format = scheme.Null
+		dst, cursor, err = compactNull(append(dst, format.Header...), src, cursor)
+	case nul:
+		return nil, 0, errors.ErrUnexpectedEndOfJSON("value", cursor)
+	default:
+		return nil, 0, errors.ErrSyntax(fmt.Sprintf("unexpected character '%c'", src[cursor]), cursor)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	return append(dst, format.Footer...), cursor, nil
+}
+
+// numberColorFormat returns the Float format for the numbers with a fraction or an exponent,
+// and the Int format for the others because the Go type of the raw number is unknown.
+func numberColorFormat(src []byte, cursor int64, scheme *ColorScheme) ColorFormat {
+	for ; floatTable[src[cursor]]; cursor++ {
+		switch src[cursor] {
+		case '.', 'e', 'E':
+			return scheme.Float
+		}
+	}
+	return scheme.Int
+}
+
+func appendColorized(dst []byte, format ColorFormat, c byte) []byte {
+	return append(append(append(dst, format.Header...), c), format.Footer...)
+}
+
+func appendColorizeIndent(dst []byte, indentNum int, prefix, indentBytes []byte) []byte {
+	if len(prefix) == 0 && len(indentBytes) == 0 {
+		return dst
+	}
+	dst = append(append(dst, '\n'), prefix...)
+	for i := 0; i < indentNum; i++ {
+		dst = append(dst, indentBytes...)
+	}
+	return dst
+}
+
+func colorizeObject(
+	dst []byte,
+	src []byte,
+	indentNum int,
+	cursor int64,
+	scheme *ColorScheme,
+	prefix []byte,
+	indentBytes []byte) ([]byte, int64, error) {
+	dst = appendColorized(dst, scheme.Delim, '{')
+	cursor = skipWhiteSpace(src, cursor+1)
+	if src[cursor] == '}' {
+		return appendColorized(dst, scheme.Delim, '}'), cursor + 1, nil
+	}
+	indentNum++
+	var err error
+	for {
+		dst = appendColorizeIndent(dst, indentNum, prefix, indentBytes)
+		cursor = skipWhiteSpace(src, cursor)
+		dst, cursor, err = compactString(append(dst, scheme.ObjectKey.Header...), src, cursor, scheme.EscapeHTML)
+		if err != nil {
+			return nil, 0, err
+		}
+		dst = append(dst, scheme.ObjectKey.Footer...)
+		cursor = skipWhiteSpace(src, cursor)
+		if src[cursor] != ':' {
+			return nil, 0, errors.ErrSyntax(
+				fmt.Sprintf("invalid character '%c' after object key", src[cursor]),
+				cursor+1,
+			)
+		}
+		dst = appendColorized(dst, scheme.Colon, ':')
+		if len(prefix) != 0 || len(indentBytes) != 0 {
+			dst = append(dst, ' ')
+		}
+		dst, cursor, err = colorizeValue(dst, src, indentNum, cursor+1, scheme, prefix, indentBytes)
+		if err != nil {
+			return nil, 0, err
+		}
+		cursor = skipWhiteSpace(src, cursor)
+		switch src[cursor] {
+		case '}':
+			dst = appendColorizeIndent(dst, indentNum-1, prefix, indentBytes)
+			return appendColorized(dst, scheme.Delim, '}'), cursor + 1, nil
+		case ',':
+			dst = appendColorized(dst, scheme.Comma, ',')
+		default:
+			return nil, 0, errors.ErrSyntax(
+				fmt.Sprintf("invalid character '%c' after object key:value pair", src[cursor]),
+				cursor+1,
+			)
+		}
+		cursor++
+	}
+}
+
+func colorizeArray(
+	dst []byte,
+	src []byte,
+	indentNum int,
+	cursor int64,
+	scheme *ColorScheme,
+	prefix []byte,
+	indentBytes []byte) ([]byte, int64, error) {
+	dst = appendColorized(dst, scheme.Delim, '[')
+	cursor = skipWhiteSpace(src, cursor+1)
+	if src[cursor] == ']' {
+		return appendColorized(dst, scheme.Delim, ']'), cursor + 1, nil
+	}
+	indentNum++
+	var err error
+	for {
+		dst = appendColorizeIndent(dst, indentNum, prefix, indentBytes)
+		dst, cursor, err = colorizeValue(dst, src, indentNum, cursor, scheme, prefix, indentBytes)
+		if err != nil {
+			return nil, 0, err
+		}
+		cursor = skipWhiteSpace(src, cursor)
+		switch src[cursor] {
+		case ']':
+			dst = appendColorizeIndent(dst, indentNum-1, prefix, indentBytes)
+			return appendColorized(dst, scheme.Delim, ']'), cursor + 1, nil
+		case ',':
+			dst = appendColorized(dst, scheme.Comma, ',')
+		default:
+			return nil, 0, errors.ErrSyntax(
+				fmt.Sprintf("invalid character '%c' after array value", src[cursor]),
+				cursor+1,
+			)
+		}
+		cursor++
+	}
+}
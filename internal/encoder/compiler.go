@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
-	"sync/atomic"
 	"unsafe"
 
 	"github.com/goccy/go-json/internal/errors"
@@ -18,14 +17,22 @@ type marshalerContext interface {
 	MarshalJSON(context.Context) ([]byte, error)
 }
 
+type isZeroer interface {
+	IsZero() bool
+}
+
 var (
 	marshalJSONType        = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
 	marshalJSONContextType = reflect.TypeOf((*marshalerContext)(nil)).Elem()
 	marshalTextType        = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
 	jsonNumberType         = reflect.TypeOf(json.Number(""))
+	isZeroerType           = reflect.TypeOf((*isZeroer)(nil)).Elem()
 	cachedOpcodeSets       []*OpcodeSet
-	cachedOpcodeMap        unsafe.Pointer // map[uintptr]*OpcodeSet
+	cachedOpcodeMap        runtime.CodeCache // map[uintptr]*OpcodeSet
 	typeAddr               *runtime.TypeAddr
+
+	// opcodeSetGroup deduplicates the concurrent compilations of the types cached in cachedOpcodeSets.
+	opcodeSetGroup runtime.CompileGroup
 )
 
 func init() {
@@ -36,46 +43,150 @@ func init() {
 	cachedOpcodeSets = make([]*OpcodeSet, typeAddr.AddrRange>>typeAddr.AddrShift)
 }
 
-func loadOpcodeMap() map[uintptr]*OpcodeSet {
-	p := atomic.LoadPointer(&cachedOpcodeMap)
-	return *(*map[uintptr]*OpcodeSet)(unsafe.Pointer(&p))
+func compileToGetCodeSetSlowPath(typeptr uintptr) (*OpcodeSet, error) {
+	codeSet, err := cachedOpcodeMap.LoadOrCompile(typeptr, func() (interface{}, error) {
+		return compileCodeSet(typeptr)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codeSet.(*OpcodeSet), nil
 }
 
-func storeOpcodeSet(typ uintptr, set *OpcodeSet, m map[uintptr]*OpcodeSet) {
-	newOpcodeMap := make(map[uintptr]*OpcodeSet, len(m)+1)
-	newOpcodeMap[typ] = set
+// compileOpcodeSetOnce compiles the type cached in cachedOpcodeSets.
+// The concurrent callers of the same type share the compiled set.
+func compileOpcodeSetOnce(typeptr uintptr) (*OpcodeSet, error) {
+	codeSet, err := opcodeSetGroup.Do(typeptr, func() (interface{}, error) {
+		return compileCodeSet(typeptr)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codeSet.(*OpcodeSet), nil
+}
+
+func compileCodeSet(typeptr uintptr) (*OpcodeSet, error) {
+	// noescape trick for header.typ ( reflect.*rtype )
+	copiedType := *(**runtime.Type)(unsafe.Pointer(&typeptr))
 
-	for k, v := range m {
-		newOpcodeMap[k] = v
+	noescapeKeyCode, err := compileHead(&compileContext{
+		typ:                      copiedType,
+		structTypeToCompiledCode: map[structCodeKey]*CompiledCode{},
+	})
+	if err != nil {
+		return nil, err
 	}
+	escapeKeyCode, err := compileHead(&compileContext{
+		typ:                      copiedType,
+		structTypeToCompiledCode: map[structCodeKey]*CompiledCode{},
+		escapeKey:                true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	noescapeKeyCode = copyOpcode(noescapeKeyCode)
+	escapeKeyCode = copyOpcode(escapeKeyCode)
+	setTotalLengthToInterfaceOp(noescapeKeyCode)
+	setTotalLengthToInterfaceOp(escapeKeyCode)
+	interfaceNoescapeKeyCode := copyToInterfaceOpcode(noescapeKeyCode)
+	interfaceEscapeKeyCode := copyToInterfaceOpcode(escapeKeyCode)
+	codeLength := noescapeKeyCode.TotalLength()
+	return &OpcodeSet{
+		Type:                     copiedType,
+		NoescapeKeyCode:          noescapeKeyCode,
+		EscapeKeyCode:            escapeKeyCode,
+		InterfaceNoescapeKeyCode: interfaceNoescapeKeyCode,
+		InterfaceEscapeKeyCode:   interfaceEscapeKeyCode,
+		CodeLength:               codeLength,
+		EndCode:                  ToEndCode(interfaceNoescapeKeyCode),
+	}, nil
+}
 
-	atomic.StorePointer(&cachedOpcodeMap, *(*unsafe.Pointer)(unsafe.Pointer(&newOpcodeMap)))
+type optionCodeSetKey struct {
+	policy           *runtime.NamingPolicy
+	intStringBitSize uint8
+	query            string // the canonical form of the field query
+	redact           redactMode
+	references       bool
+	typeptr          uintptr
 }
 
-func compileToGetCodeSetSlowPath(typeptr uintptr) (*OpcodeSet, error) {
-	opcodeMap := loadOpcodeMap()
-	if codeSet, exists := opcodeMap[typeptr]; exists {
-		return codeSet, nil
+var optionCodeSets runtime.CodeCache // map[optionCodeSetKey]*OpcodeSet
+
+// CompileToGetCodeSetWithOption is like CompileToGetCodeSet,
+// but compiles the type with the naming policy, the field query, the redact mode and
+// the reference mode of opt, and converts the integer operations for IntAsStringOption if they are enabled.
+// The codes compiled with options are cached for every combination of them.
+func CompileToGetCodeSetWithOption(typeptr uintptr, opt *Option) (*OpcodeSet, error) {
+	var query *FieldQuery
+	if (opt.Flag & FieldQueryOption) != 0 {
+		query = opt.FieldQuery
 	}
+	return CompileToGetCodeSetWithQuery(typeptr, opt, query)
+}
+
+// CompileToGetCodeSetWithQuery is like CompileToGetCodeSetWithOption,
+// but selects the fields by query instead of the field query of opt.
+// It is used to compile the value of an interface operation by the sub query of the operation.
+func CompileToGetCodeSetWithQuery(typeptr uintptr, opt *Option, query *FieldQuery) (*OpcodeSet, error) {
+	key := optionCodeSetKey{query: query.Key()}
+	if (opt.Flag & NamingPolicyOption) != 0 {
+		key.policy = opt.NamingPolicy
+	}
+	if (opt.Flag & IntAsStringOption) != 0 {
+		key.intStringBitSize = opt.IntStringBitSize
+	}
+	key.redact = redactModeOf(opt)
+	key.references = (opt.Flag&PreserveReferencesOption) != 0 && (opt.Flag&CanonicalOption) == 0
+	if key.policy == nil && key.intStringBitSize == 0 && query == nil && key.redact == redactNone && !key.references {
+		return CompileToGetCodeSet(typeptr)
+	}
+	key.typeptr = typeptr
+	codeSet, err := optionCodeSets.LoadOrCompile(key, func() (interface{}, error) {
+		return compileOptionCodeSet(key, query)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return codeSet.(*OpcodeSet), nil
+}
 
+func compileOptionCodeSet(key optionCodeSetKey, query *FieldQuery) (*OpcodeSet, error) {
 	// noescape trick for header.typ ( reflect.*rtype )
+	typeptr := key.typeptr
 	copiedType := *(**runtime.Type)(unsafe.Pointer(&typeptr))
 
 	noescapeKeyCode, err := compileHead(&compileContext{
 		typ:                      copiedType,
-		structTypeToCompiledCode: map[uintptr]*CompiledCode{},
+		structTypeToCompiledCode: map[structCodeKey]*CompiledCode{},
+		namingPolicy:             key.policy,
+		fieldQuery:               query,
+		redact:                   key.redact,
+		preserveReferences:       key.references,
 	})
 	if err != nil {
 		return nil, err
 	}
 	escapeKeyCode, err := compileHead(&compileContext{
 		typ:                      copiedType,
-		structTypeToCompiledCode: map[uintptr]*CompiledCode{},
+		structTypeToCompiledCode: map[structCodeKey]*CompiledCode{},
 		escapeKey:                true,
+		namingPolicy:             key.policy,
+		fieldQuery:               query,
+		redact:                   key.redact,
+		preserveReferences:       key.references,
 	})
 	if err != nil {
 		return nil, err
 	}
+	if key.references {
+		convertDirectReferenceOp(noescapeKeyCode)
+		convertDirectReferenceOp(escapeKeyCode)
+	}
+	if key.intStringBitSize != 0 {
+		convertIntToStringOp(noescapeKeyCode, key.intStringBitSize)
+		convertIntToStringOp(escapeKeyCode, key.intStringBitSize)
+	}
 	noescapeKeyCode = copyOpcode(noescapeKeyCode)
 	escapeKeyCode = copyOpcode(escapeKeyCode)
 	setTotalLengthToInterfaceOp(noescapeKeyCode)
@@ -83,7 +194,7 @@ func compileToGetCodeSetSlowPath(typeptr uintptr) (*OpcodeSet, error) {
 	interfaceNoescapeKeyCode := copyToInterfaceOpcode(noescapeKeyCode)
 	interfaceEscapeKeyCode := copyToInterfaceOpcode(escapeKeyCode)
 	codeLength := noescapeKeyCode.TotalLength()
-	codeSet := &OpcodeSet{
+	return &OpcodeSet{
 		Type:                     copiedType,
 		NoescapeKeyCode:          noescapeKeyCode,
 		EscapeKeyCode:            escapeKeyCode,
@@ -91,14 +202,78 @@ func compileToGetCodeSetSlowPath(typeptr uintptr) (*OpcodeSet, error) {
 		InterfaceEscapeKeyCode:   interfaceEscapeKeyCode,
 		CodeLength:               codeLength,
 		EndCode:                  ToEndCode(interfaceNoescapeKeyCode),
+	}, nil
+}
+
+// intToStringOps maps the integer operations to their *String operations.
+var intToStringOps = func() map[OpType]OpType {
+	ops := map[string]OpType{}
+	for i := range opTypeStrings {
+		ops[OpType(i).String()] = OpType(i)
 	}
-	storeOpcodeSet(typeptr, codeSet, opcodeMap)
-	return codeSet, nil
+	m := map[OpType]OpType{}
+	for name, op := range ops {
+		if !strings.HasSuffix(name, "Int") && !strings.HasSuffix(name, "Uint") &&
+			!strings.HasSuffix(name, "IntPtr") && !strings.HasSuffix(name, "UintPtr") {
+			continue
+		}
+		if stringOp, exists := ops[name+"String"]; exists {
+			m[op] = stringOp
+		}
+	}
+	return m
+}()
+
+// quotedOps maps the operations of the values that the string option quotes to their *String operations.
+var quotedOps = func() map[OpType]OpType {
+	m := map[OpType]OpType{}
+	for _, name := range []string{"Int", "Uint", "Float32", "Float64", "Bool", "String", "Number"} {
+		for _, suffix := range []string{"", "Ptr"} {
+			m[opTypeByName(name+suffix)] = opTypeByName(name + suffix + "String")
+		}
+	}
+	return m
+}()
+
+func opTypeByName(name string) OpType {
+	for i, s := range opTypeStrings {
+		if s == name {
+			return OpType(i)
+		}
+	}
+	panic("encoder: unknown operation " + name)
+}
+
+// convertIntToStringOp converts the integer operations whose bit size is bitSize or more
+// into the *String operations that quote the values exceeding the threshold of IntAsStringOption.
+func convertIntToStringOp(code *Opcode, bitSize uint8) {
+	visited := map[*Opcode]struct{}{}
+	var convert func(*Opcode)
+	convert = func(code *Opcode) {
+		for ; code != nil; code = code.Next {
+			if _, exists := visited[code]; exists {
+				return
+			}
+			visited[code] = struct{}{}
+			if op, exists := intToStringOps[code.Op]; exists && code.NumBitSize >= bitSize {
+				code.Op = op
+				code.Flags |= IntStringFlags
+			}
+			if code.Jmp != nil {
+				convert(code.Jmp.Code)
+			}
+			convert(code.End)
+			convert(code.NextField)
+		}
+	}
+	convert(code)
 }
 
 func compileHead(ctx *compileContext) (*Opcode, error) {
 	typ := ctx.typ
 	switch {
+	case isRedactedType(ctx, typ):
+		return compileRedacted(ctx)
 	case implementsMarshalJSON(typ):
 		return compileMarshalJSON(ctx)
 	case implementsMarshalText(typ):
@@ -149,6 +324,9 @@ func compileHead(ctx *compileContext) (*Opcode, error) {
 		linkRecursiveCode(code)
 		return code, nil
 	case reflect.Struct:
+		if isPtr && isReferenceType(ctx, orgType) {
+			return compileReferenceHead(ctx.withType(orgType))
+		}
 		code, err := compileStruct(ctx.withType(typ), isPtr)
 		if err != nil {
 			return nil, err
@@ -385,6 +563,8 @@ func implementsMarshalText(typ *runtime.Type) bool {
 func compile(ctx *compileContext, isPtr bool) (*Opcode, error) {
 	typ := ctx.typ
 	switch {
+	case isRedactedType(ctx, typ):
+		return compileRedacted(ctx)
 	case implementsMarshalJSON(typ):
 		return compileMarshalJSON(ctx)
 	case implementsMarshalText(typ):
@@ -392,6 +572,9 @@ func compile(ctx *compileContext, isPtr bool) (*Opcode, error) {
 	}
 	switch typ.Kind() {
 	case reflect.Ptr:
+		if isReferenceType(ctx, typ) {
+			return compileReference(ctx)
+		}
 		return compilePtr(ctx)
 	case reflect.Slice:
 		elem := typ.Elem()
@@ -545,6 +728,7 @@ func compileMarshalJSON(ctx *compileContext) (*Opcode, error) {
 	}
 	if typ.Implements(marshalJSONContextType) || runtime.PtrTo(typ).Implements(marshalJSONContextType) {
 		code.Flags |= MarshalerContextFlags
+		setFieldQuery(code, ctx.fieldQuery)
 	}
 	if isNilableType(typ) {
 		code.Flags |= IsNilableTypeFlags
@@ -804,6 +988,7 @@ func compileUint64String(ctx *compileContext) (*Opcode, error) {
 
 func compileFloat32(ctx *compileContext) (*Opcode, error) {
 	code := newOpCode(ctx, OpFloat32)
+	code.FloatFmt, code.FloatPrec = ctx.floatFmt, ctx.floatPrec
 	ctx.incIndex()
 	return code, nil
 }
@@ -819,6 +1004,7 @@ func compileFloat32Ptr(ctx *compileContext) (*Opcode, error) {
 
 func compileFloat64(ctx *compileContext) (*Opcode, error) {
 	code := newOpCode(ctx, OpFloat64)
+	code.FloatFmt, code.FloatPrec = ctx.floatFmt, ctx.floatPrec
 	ctx.incIndex()
 	return code, nil
 }
@@ -889,6 +1075,7 @@ func compileBytesPtr(ctx *compileContext) (*Opcode, error) {
 
 func compileInterface(ctx *compileContext) (*Opcode, error) {
 	code := newInterfaceCode(ctx)
+	setFieldQuery(code, ctx.fieldQuery)
 	ctx.incIndex()
 	return code, nil
 }
@@ -936,6 +1123,8 @@ func compileSlice(ctx *compileContext) (*Opcode, error) {
 func compileListElem(ctx *compileContext) (*Opcode, error) {
 	typ := ctx.typ
 	switch {
+	case isRedactedType(ctx, typ):
+		return compileRedacted(ctx)
 	case isPtrMarshalJSONType(typ):
 		return compileMarshalJSON(ctx)
 	case !typ.Implements(marshalTextType) && runtime.PtrTo(typ).Implements(marshalTextType):
@@ -1048,6 +1237,9 @@ func compileMapValue(ctx *compileContext) (*Opcode, error) {
 }
 
 func optimizeStructHeader(code *Opcode, tag *runtime.StructTag) OpType {
+	if tag.IsOmitZero {
+		return OpStructHeadOmitZero
+	}
 	headType := code.ToHeaderType(tag.IsString)
 	if tag.IsOmitEmpty {
 		headType = headType.HeadToOmitEmptyHead()
@@ -1056,6 +1248,9 @@ func optimizeStructHeader(code *Opcode, tag *runtime.StructTag) OpType {
 }
 
 func optimizeStructField(code *Opcode, tag *runtime.StructTag) OpType {
+	if tag.IsOmitZero {
+		return OpStructFieldOmitZero
+	}
 	fieldType := code.ToFieldType(tag.IsString)
 	if tag.IsOmitEmpty {
 		fieldType = fieldType.FieldToOmitEmptyField()
@@ -1071,8 +1266,8 @@ func recursiveCode(ctx *compileContext, jmp *CompiledCode) *Opcode {
 
 func compiledCode(ctx *compileContext) *Opcode {
 	typ := ctx.typ
-	typeptr := uintptr(unsafe.Pointer(typ))
-	if cc, exists := ctx.structTypeToCompiledCode[typeptr]; exists {
+	key := structCodeKey{typeptr: uintptr(unsafe.Pointer(typ)), query: ctx.fieldQuery}
+	if cc, exists := ctx.structTypeToCompiledCode[key]; exists {
 		return recursiveCode(ctx, cc)
 	}
 	return nil
@@ -1324,9 +1519,9 @@ func compileStruct(ctx *compileContext, isPtr bool) (*Opcode, error) {
 		return code, nil
 	}
 	typ := ctx.typ
-	typeptr := uintptr(unsafe.Pointer(typ))
+	key := structCodeKey{typeptr: uintptr(unsafe.Pointer(typ)), query: ctx.fieldQuery}
 	compiled := &CompiledCode{}
-	ctx.structTypeToCompiledCode[typeptr] = compiled
+	ctx.structTypeToCompiledCode[key] = compiled
 	// header => code => structField => code => end
 	//                        ^          |
 	//                        |__________|
@@ -1341,13 +1536,27 @@ func compileStruct(ctx *compileContext, isPtr bool) (*Opcode, error) {
 	)
 	ctx = ctx.incIndent()
 	tags := runtime.StructTags{}
+	fieldQueries := []*FieldQuery{}
 	anonymousFields := map[string][]structFieldPair{}
 	for i := 0; i < fieldNum; i++ {
 		field := typ.Field(i)
 		if runtime.IsIgnoredStructField(field) {
 			continue
 		}
-		tags = append(tags, runtime.StructTagFromField(field))
+		tag := runtime.StructTagFromFieldWithNamingPolicy(field, ctx.namingPolicy)
+		if isOmittedRedactedField(ctx, tag, runtime.Type2RType(field.Type)) {
+			continue
+		}
+		fieldQuery := ctx.fieldQuery
+		if !isFlattenedField(tag) {
+			query, selected := ctx.fieldQuery.Field(tag.Key)
+			if !selected {
+				continue
+			}
+			fieldQuery = query
+		}
+		tags = append(tags, tag)
+		fieldQueries = append(fieldQueries, fieldQuery)
 	}
 	for i, tag := range tags {
 		field := tag.Field
@@ -1355,19 +1564,33 @@ func compileStruct(ctx *compileContext, isPtr bool) (*Opcode, error) {
 		fieldOpcodeIndex := ctx.opcodeIndex
 		fieldPtrIndex := ctx.ptrIndex
 		ctx.incIndex()
+		valueCtx := ctx.withFieldQuery(fieldQueries[i]).withFloatFormat(tag.FloatFmt, uint8(tag.FloatPrec))
 
 		nilcheck := true
 		addrForMarshaler := false
 		isIndirectSpecialCase := isPtr && i == 0 && fieldNum == 1
 		isNilableType := isNilableType(fieldType)
+		if tag.IsOmitZero && isFlattenedField(tag) {
+			// the fields of the embedded struct are promoted, so it has no value to omit like encoding/json.
+			tag.IsOmitZero = false
+		}
+		// the field of the pointer shape struct is copied to an address by OmitZeroFieldAddress,
+		// so the value of the field is referred by the address like the field of the other structs.
+		omitZeroAddr := tag.IsOmitZero && !indirect
 
 		var valueCode *Opcode
 		switch {
+		case ctx.redact != redactNone && isRedactedField(tag, fieldType):
+			code, err := compileRedacted(valueCtx.withType(fieldType))
+			if err != nil {
+				return nil, err
+			}
+			valueCode = code
 		case isIndirectSpecialCase && !isNilableType && isPtrMarshalJSONType(fieldType):
 			// *struct{ field T } => struct { field *T }
 			// func (*T) MarshalJSON() ([]byte, error)
 			// move pointer position from head to first field
-			code, err := compileMarshalJSON(ctx.withType(fieldType))
+			code, err := compileMarshalJSON(valueCtx.withType(fieldType))
 			if err != nil {
 				return nil, err
 			}
@@ -1380,7 +1603,7 @@ func compileStruct(ctx *compileContext, isPtr bool) (*Opcode, error) {
 			// *struct{ field T } => struct { field *T }
 			// func (*T) MarshalText() ([]byte, error)
 			// move pointer position from head to first field
-			code, err := compileMarshalText(ctx.withType(fieldType))
+			code, err := compileMarshalText(valueCtx.withType(fieldType))
 			if err != nil {
 				return nil, err
 			}
@@ -1392,7 +1615,7 @@ func compileStruct(ctx *compileContext, isPtr bool) (*Opcode, error) {
 		case isPtr && isPtrMarshalJSONType(fieldType):
 			// *struct{ field T }
 			// func (*T) MarshalJSON() ([]byte, error)
-			code, err := compileMarshalJSON(ctx.withType(fieldType))
+			code, err := compileMarshalJSON(valueCtx.withType(fieldType))
 			if err != nil {
 				return nil, err
 			}
@@ -1402,15 +1625,29 @@ func compileStruct(ctx *compileContext, isPtr bool) (*Opcode, error) {
 		case isPtr && isPtrMarshalTextType(fieldType):
 			// *struct{ field T }
 			// func (*T) MarshalText() ([]byte, error)
-			code, err := compileMarshalText(ctx.withType(fieldType))
+			code, err := compileMarshalText(valueCtx.withType(fieldType))
 			if err != nil {
 				return nil, err
 			}
 			addrForMarshaler = true
 			nilcheck = false
 			valueCode = code
+		case isFlattenedField(tag) && isReferenceType(ctx, fieldType):
+			// the fields of the embedded struct are flattened, so the struct can't have the reference.
+			code, err := compilePtr(valueCtx.withType(fieldType))
+			if err != nil {
+				return nil, err
+			}
+			valueCode = code
+		case tag.IsOmitZero && fieldType.Kind() == reflect.Map:
+			// omitzero operations pass the address of the field to the map operation.
+			code, err := compilePtr(valueCtx.withType(runtime.PtrTo(fieldType)))
+			if err != nil {
+				return nil, err
+			}
+			valueCode = code
 		default:
-			code, err := compile(ctx.withType(fieldType), isPtr)
+			code, err := compile(valueCtx.withType(fieldType), isPtr)
 			if err != nil {
 				return nil, err
 			}
@@ -1425,18 +1662,21 @@ func compileStruct(ctx *compileContext, isPtr bool) (*Opcode, error) {
 			for k, v := range anonymousStructFieldPairMap(tags, tagKey, valueCode) {
 				anonymousFields[k] = append(anonymousFields[k], v...)
 			}
-			valueCode.decIndent()
+			if !tag.IsTaggedKey {
+				// the fields of the embedded struct are promoted to the parent
+				valueCode.decIndent()
+			}
 
 			// fix issue144
 			if !(isPtr && strings.Contains(valueCode.Op.String(), "Marshal")) {
-				if indirect {
+				if indirect || omitZeroAddr {
 					valueCode.Flags |= IndirectFlags
 				} else {
 					valueCode.Flags &= ^IndirectFlags
 				}
 			}
 		} else {
-			if indirect {
+			if indirect || omitZeroAddr {
 				// if parent is indirect type, set child indirect property to true
 				valueCode.Flags |= IndirectFlags
 			} else {
@@ -1464,12 +1704,37 @@ func compileStruct(ctx *compileContext, isPtr bool) (*Opcode, error) {
 		if addrForMarshaler {
 			flags |= AddrForMarshalerFlags
 		}
+		if (valueCode.Flags & MarshalerContextFlags) != 0 {
+			flags |= MarshalerContextFlags
+		}
+		if (valueCode.Flags & RedactFlags) != 0 {
+			flags |= RedactFlags
+		}
 		if strings.Contains(valueCode.Op.String(), "Ptr") || valueCode.Op == OpInterface {
 			flags |= IsNextOpPtrTypeFlags
 		}
 		if isNilableType {
 			flags |= IsNilableTypeFlags
 		}
+		codeType := valueCode.Type
+		if tag.IsOmitZero {
+			// omitzero operations check the value of the field type itself.
+			codeType = fieldType
+			if fieldType.Implements(isZeroerType) {
+				flags |= IsZeroerFlags
+			} else if runtime.PtrTo(fieldType).Implements(isZeroerType) {
+				flags |= IsPtrZeroerFlags
+			}
+			if tag.IsOmitEmpty {
+				flags |= IsOmitEmptyFlags
+			}
+		}
+		if tag.IsOmitZero && tag.IsString {
+			// the quoted operations of the headers and the fields can't check omitzero, so the value is quoted instead.
+			if op, exists := quotedOps[valueCode.Op]; exists {
+				valueCode.Op = op
+			}
+		}
 		var key string
 		if ctx.escapeKey {
 			rctx := &RuntimeContext{Option: &Option{Flag: HTMLEscapeOption}}
@@ -1483,11 +1748,23 @@ func compileStruct(ctx *compileContext, isPtr bool) (*Opcode, error) {
 			Flags:      flags,
 			Key:        key,
 			Offset:     uint32(field.Offset),
-			Type:       valueCode.Type,
+			Type:       codeType,
 			DisplayIdx: fieldOpcodeIndex,
 			Indent:     ctx.indent,
 			DisplayKey: tag.Key,
 		}
+		if (flags & MarshalerContextFlags) != 0 {
+			copyFieldQuery(fieldCode, valueCode)
+		}
+		if tag.FloatFmt != 0 || tag.FloatPrec != 0 {
+			fieldCode.FloatFmt, fieldCode.FloatPrec = tag.FloatFmt, uint8(tag.FloatPrec)
+		}
+		if tag.IsNoNil {
+			switch valueCode.Op {
+			case OpSlice, OpSlicePtr, OpMap, OpMapPtr:
+				valueCode.Flags |= NoNilFlags
+			}
+		}
 		if fieldIdx == 0 {
 			code = structHeader(ctx, fieldCode, valueCode, tag)
 			head = fieldCode
@@ -1524,6 +1801,8 @@ func compileStruct(ctx *compileContext, isPtr bool) (*Opcode, error) {
 		}
 		structEndCode.PrevField = head
 		ctx.incIndex()
+		// the end operation was created before the head, so it must not share the index of the head.
+		structEndCode.Next.Idx = opcodeOffset(ctx.ptrIndex)
 		code = head
 	}
 
@@ -1543,7 +1822,7 @@ func compileStruct(ctx *compileContext, isPtr bool) (*Opcode, error) {
 	ret := (*Opcode)(unsafe.Pointer(head))
 	compiled.Code = ret
 
-	delete(ctx.structTypeToCompiledCode, typeptr)
+	delete(ctx.structTypeToCompiledCode, key)
 
 	if !disableIndirectConversion && (head.Flags&IndirectFlags == 0) && isPtr {
 		headCode := head
@@ -1556,6 +1835,19 @@ func compileStruct(ctx *compileContext, isPtr bool) (*Opcode, error) {
 	return ret, nil
 }
 
+// isFlattenedField reports whether the fields of the embedded struct are promoted to the parent.
+// The field query of the parent is applied to the promoted fields.
+func isFlattenedField(tag *runtime.StructTag) bool {
+	if !tag.Field.Anonymous || tag.IsTaggedKey {
+		return false
+	}
+	typ := tag.Field.Type
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ.Kind() == reflect.Struct
+}
+
 func implementsMarshalJSONType(typ *runtime.Type) bool {
 	return typ.Implements(marshalJSONType) || typ.Implements(marshalJSONContextType)
 }
@@ -2,19 +2,33 @@ package encoder
 
 import (
 	"context"
+	"io"
 	"sync"
 	"unsafe"
 
 	"github.com/goccy/go-json/internal/runtime"
 )
 
+// structCodeKey identifies the struct code being compiled.
+// The same struct type is compiled to different codes for each field query.
+type structCodeKey struct {
+	typeptr uintptr
+	query   *FieldQuery
+}
+
 type compileContext struct {
 	typ                      *runtime.Type
 	opcodeIndex              uint32
 	ptrIndex                 int
 	indent                   uint32
 	escapeKey                bool
-	structTypeToCompiledCode map[uintptr]*CompiledCode
+	structTypeToCompiledCode map[structCodeKey]*CompiledCode
+	namingPolicy             *runtime.NamingPolicy
+	fieldQuery               *FieldQuery
+	redact                   redactMode
+	preserveReferences       bool
+	floatFmt                 uint8 // float notation of the field, applied to the elements and the map values, too
+	floatPrec                uint8 // float precision of the field, applied to the elements and the map values, too
 
 	parent *compileContext
 }
@@ -27,6 +41,12 @@ func (c *compileContext) context() *compileContext {
 		indent:                   c.indent,
 		escapeKey:                c.escapeKey,
 		structTypeToCompiledCode: c.structTypeToCompiledCode,
+		namingPolicy:             c.namingPolicy,
+		fieldQuery:               c.fieldQuery,
+		redact:                   c.redact,
+		preserveReferences:       c.preserveReferences,
+		floatFmt:                 c.floatFmt,
+		floatPrec:                c.floatPrec,
 		parent:                   c,
 	}
 }
@@ -37,6 +57,19 @@ func (c *compileContext) withType(typ *runtime.Type) *compileContext {
 	return ctx
 }
 
+func (c *compileContext) withFieldQuery(query *FieldQuery) *compileContext {
+	ctx := c.context()
+	ctx.fieldQuery = query
+	return ctx
+}
+
+func (c *compileContext) withFloatFormat(fmt, prec uint8) *compileContext {
+	ctx := c.context()
+	ctx.floatFmt = fmt
+	ctx.floatPrec = prec
+	return ctx
+}
+
 func (c *compileContext) incIndent() *compileContext {
 	ctx := c.context()
 	ctx.indent++
@@ -115,6 +148,21 @@ type RuntimeContext struct {
 	Prefix     []byte
 	IndentStr  []byte
 	Option     *Option
+
+	// FlushWriter receives the encoded data whenever Buf grows beyond FlushThreshold.
+	FlushWriter    io.Writer
+	FlushThreshold int
+	SortedMapDepth int
+
+	// the following fields are used to sort object members in canonical mode.
+	canonicalFrames  []int
+	canonicalPos     []int
+	canonicalMembers []canonicalMember
+	canonicalBuf     []byte
+
+	// the following fields are used to write the references of pointers in PreserveReferencesOption.
+	referenceIDs    map[referenceKey]string
+	referenceFrames []referenceFrame
 }
 
 func (c *RuntimeContext) Init(p uintptr, codelen int) {
@@ -125,6 +173,13 @@ func (c *RuntimeContext) Init(p uintptr, codelen int) {
 	c.KeepRefs = c.KeepRefs[:0]
 	c.SeenPtr = c.SeenPtr[:0]
 	c.BaseIndent = 0
+	c.SortedMapDepth = 0
+	c.canonicalFrames = c.canonicalFrames[:0]
+	c.canonicalPos = c.canonicalPos[:0]
+	for k := range c.referenceIDs {
+		delete(c.referenceIDs, k)
+	}
+	c.referenceFrames = c.referenceFrames[:0]
 }
 
 func (c *RuntimeContext) Ptr() uintptr {
@@ -132,6 +187,25 @@ func (c *RuntimeContext) Ptr() uintptr {
 	return uintptr(header.Data)
 }
 
+// flushTailLen is the number of trailing bytes that are kept in the buffer on flushing,
+// because the VMs may rewrite the last two bytes ( e.g. the trailing ",\n" ).
+const flushTailLen = 2
+
+// FlushBuffer writes b except its trailing bytes to ctx.FlushWriter if b has grown beyond ctx.FlushThreshold.
+// The buffer isn't flushed while a sorted map, a canonical object or a referenced struct is encoded,
+// because sorting the members and writing the reference id rewrite already encoded data.
+func FlushBuffer(ctx *RuntimeContext, b []byte) ([]byte, error) {
+	if len(b) < ctx.FlushThreshold || len(b) <= flushTailLen || ctx.SortedMapDepth > 0 ||
+		len(ctx.canonicalFrames) > 0 || len(ctx.referenceFrames) > 0 {
+		return b, nil
+	}
+	n := len(b) - flushTailLen
+	if _, err := ctx.FlushWriter.Write(b[:n]); err != nil {
+		return nil, err
+	}
+	return append(b[:0], b[n:]...), nil
+}
+
 func TakeRuntimeContext() *RuntimeContext {
 	return runtimeContextPool.Get().(*RuntimeContext)
 }
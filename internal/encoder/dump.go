@@ -0,0 +1,108 @@
+package encoder
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+var opFlagNames = []struct {
+	flag OpFlags
+	name string
+}{
+	{AnonymousHeadFlags, "AnonymousHead"},
+	{AnonymousKeyFlags, "AnonymousKey"},
+	{IndirectFlags, "Indirect"},
+	{IsTaggedKeyFlags, "IsTaggedKey"},
+	{NilCheckFlags, "NilCheck"},
+	{AddrForMarshalerFlags, "AddrForMarshaler"},
+	{IsNextOpPtrTypeFlags, "IsNextOpPtrType"},
+	{IsNilableTypeFlags, "IsNilableType"},
+	{MarshalerContextFlags, "MarshalerContext"},
+	{IsZeroerFlags, "IsZeroer"},
+	{IsPtrZeroerFlags, "IsPtrZeroer"},
+	{IsOmitEmptyFlags, "IsOmitEmpty"},
+	{IntStringFlags, "IntString"},
+	{NoNilFlags, "NoNil"},
+	{RedactFlags, "Redact"},
+	{ReferenceFlags, "Reference"},
+}
+
+func (f OpFlags) String() string {
+	names := []string{}
+	for _, n := range opFlagNames {
+		if f&n.flag != 0 {
+			names = append(names, n.name)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+// DumpProgram writes every opcode of the program to w, one per line, with the operands and the jumps.
+// The jumps are written as the indexes of the target opcodes, and the recursive call as the head of the called program.
+func (c *Opcode) DumpProgram(w io.Writer) error {
+	for code := c; ; {
+		if _, err := fmt.Fprintln(w, code.programLine()); err != nil {
+			return err
+		}
+		if code.Op == OpEnd || code.Op == OpInterfaceEnd {
+			return nil
+		}
+		switch code.Op.CodeType() {
+		case CodeArrayElem, CodeSliceElem, CodeMapKey:
+			code = code.End
+		default:
+			code = code.Next
+		}
+	}
+}
+
+func (c *Opcode) programLine() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%d]%s%s idx:%d", c.DisplayIdx, strings.Repeat("-", int(c.Indent)), c.Op, c.Idx/uintptrSize)
+	if c.Op.CodeType() == CodeStructField {
+		fmt.Fprintf(&b, " key:%q offset:%d", c.DisplayKey, c.Offset)
+	}
+	if c.PtrNum > 0 {
+		fmt.Fprintf(&b, " ptrNum:%d", c.PtrNum)
+	}
+	if c.Type != nil {
+		fmt.Fprintf(&b, " type:%s", c.Type)
+	}
+	if c.Flags != 0 {
+		fmt.Fprintf(&b, " flags:%s", c.Flags)
+	}
+	for _, jump := range []struct {
+		name string
+		code *Opcode
+	}{
+		{"next", c.Next},
+		{"end", c.End},
+		{"nextField", c.NextField},
+	} {
+		if jump.code != nil {
+			fmt.Fprintf(&b, " %s:%d", jump.name, jump.code.DisplayIdx)
+		}
+	}
+	if query, ok := fieldQueries.Load(c); ok {
+		fmt.Fprintf(&b, " query:%q", query.(*FieldQuery).Key())
+	}
+	if c.Jmp != nil && c.Jmp.Code != nil {
+		fmt.Fprintf(&b, " jmp:[%d]%s", c.Jmp.Code.DisplayIdx, c.Jmp.Code.Op)
+	}
+	return b.String()
+}
+
+// DebugOutput returns the writer of the debug information, which is DebugWriter with DebugTraceOption or os.Stdout.
+func (o *Option) DebugOutput() io.Writer {
+	if (o.Flag&DebugTraceOption) != 0 && o.DebugWriter != nil {
+		return o.DebugWriter
+	}
+	return os.Stdout
+}
+
+// TraceOpcode writes the opcode that the VM is executing to DebugWriter, with the length of the output so far.
+func TraceOpcode(ctx *RuntimeContext, code *Opcode, b []byte) {
+	fmt.Fprintf(ctx.Option.DebugWriter, "%s out:%d\n", code.programLine(), len(b))
+}
@@ -2,6 +2,7 @@ package encoder
 
 import (
 	"bytes"
+	"context"
 	"encoding"
 	"encoding/base64"
 	"encoding/json"
@@ -9,7 +10,6 @@ import (
 	"math"
 	"reflect"
 	"strconv"
-	"strings"
 	"sync"
 	"unsafe"
 
@@ -51,6 +51,8 @@ func (t OpType) IsMultipleOpHead() bool {
 		return true
 	case OpStructHeadOmitEmptyMapPtr:
 		return true
+	case OpStructHeadOmitZero:
+		return true
 	}
 	return false
 }
@@ -89,6 +91,8 @@ func (t OpType) IsMultipleOpField() bool {
 		return true
 	case OpStructFieldOmitEmptyMapPtr:
 		return true
+	case OpStructFieldOmitZero:
+		return true
 	}
 	return false
 }
@@ -208,6 +212,9 @@ type MapItem struct {
 
 type Mapslice struct {
 	Items []MapItem
+
+	// CompareUTF16 sorts keys by UTF-16 code units of their decoded values ( for canonical output ).
+	CompareUTF16 bool
 }
 
 func (m *Mapslice) Len() int {
@@ -215,6 +222,9 @@ func (m *Mapslice) Len() int {
 }
 
 func (m *Mapslice) Less(i, j int) bool {
+	if m.CompareUTF16 {
+		return compareJSONStringUTF16(m.Items[i].Key, m.Items[j].Key) < 0
+	}
 	return bytes.Compare(m.Items[i].Key, m.Items[j].Key) < 0
 }
 
@@ -234,7 +244,7 @@ var mapContextPool = sync.Pool{
 	},
 }
 
-func NewMapContext(mapLen int) *MapContext {
+func NewMapContext(mapLen int, compareUTF16 bool) *MapContext {
 	ctx := mapContextPool.Get().(*MapContext)
 	if ctx.Slice == nil {
 		ctx.Slice = &Mapslice{
@@ -249,6 +259,7 @@ func NewMapContext(mapLen int) *MapContext {
 		ctx.Slice.Items = ctx.Slice.Items[:0]
 	}
 	ctx.Buf = ctx.Buf[:0]
+	ctx.Slice.CompareUTF16 = compareUTF16
 	return ctx
 }
 
@@ -256,9 +267,15 @@ func ReleaseMapContext(c *MapContext) {
 	mapContextPool.Put(c)
 }
 
-//go:linkname MapIterInit reflect.mapiterinit
+//go:linkname mapIterInitShim reflect.mapiterinit
 //go:noescape
-func MapIterInit(mapType *runtime.Type, m unsafe.Pointer) unsafe.Pointer
+func mapIterInitShim(mapType *runtime.Type, m unsafe.Pointer, it unsafe.Pointer)
+
+func MapIterInit(mapType *runtime.Type, m unsafe.Pointer) unsafe.Pointer {
+	it := new([4]unsafe.Pointer)
+	mapIterInitShim(mapType, m, unsafe.Pointer(it))
+	return unsafe.Pointer(it)
+}
 
 //go:linkname MapIterKey reflect.mapiterkey
 //go:noescape
@@ -356,7 +373,41 @@ func AppendNumber(_ *RuntimeContext, b []byte, n json.Number) ([]byte, error) {
 	return b, nil
 }
 
+// contextForMarshaler returns the context passed to MarshalJSON(context.Context).
+// If the fields are selected by a field query, the context has the sub query for the value.
+func contextForMarshaler(ctx *RuntimeContext, code *Opcode) context.Context {
+	if (ctx.Option.Flag & FieldQueryOption) == 0 {
+		return ctx.Option.Context
+	}
+	return SetFieldQueryToContext(ctx.Option.Context, FieldQueryOf(ctx, code))
+}
+
+// AppendMarshalerFunc appends the encoding of v to b with the options of ctx, instead of the MarshalJSON method of v.
+// indent reports whether b is indented, and the lines after the first one are indented by the level of code.
+type AppendMarshalerFunc func(ctx *RuntimeContext, code *Opcode, b []byte, v interface{}, indent bool) ([]byte, error)
+
+var appendMarshalers sync.Map // map[reflect.Type]AppendMarshalerFunc
+
+// RegisterAppendMarshaler registers the function to encode the values of typ with the options of the encoding,
+// which the MarshalJSON methods can't receive. typ is matched exactly.
+func RegisterAppendMarshaler(typ reflect.Type, f AppendMarshalerFunc) {
+	appendMarshalers.Store(typ, f)
+}
+
+func appendMarshalerOf(v interface{}) AppendMarshalerFunc {
+	if f, exists := appendMarshalers.Load(reflect.TypeOf(v)); exists {
+		return f.(AppendMarshalerFunc)
+	}
+	return nil
+}
+
 func AppendMarshalJSON(ctx *RuntimeContext, code *Opcode, b []byte, v interface{}) ([]byte, error) {
+	if (code.Flags & RedactFlags) != 0 {
+		return appendRedacted(ctx, code, b, v), nil
+	}
+	if f := appendMarshalerOf(v); f != nil {
+		return f(ctx, code, b, v, false)
+	}
 	rv := reflect.ValueOf(v) // convert by dynamic interface type
 	if (code.Flags & AddrForMarshalerFlags) != 0 {
 		if rv.CanAddr() {
@@ -374,7 +425,7 @@ func AppendMarshalJSON(ctx *RuntimeContext, code *Opcode, b []byte, v interface{
 		if !ok {
 			return AppendNull(ctx, b), nil
 		}
-		b, err := marshaler.MarshalJSON(ctx.Option.Context)
+		b, err := marshaler.MarshalJSON(contextForMarshaler(ctx, code))
 		if err != nil {
 			return nil, &errors.MarshalerError{Type: reflect.TypeOf(v), Err: err}
 		}
@@ -401,6 +452,12 @@ func AppendMarshalJSON(ctx *RuntimeContext, code *Opcode, b []byte, v interface{
 }
 
 func AppendMarshalJSONIndent(ctx *RuntimeContext, code *Opcode, b []byte, v interface{}) ([]byte, error) {
+	if (code.Flags & RedactFlags) != 0 {
+		return appendRedacted(ctx, code, b, v), nil
+	}
+	if f := appendMarshalerOf(v); f != nil {
+		return f(ctx, code, b, v, true)
+	}
 	rv := reflect.ValueOf(v) // convert by dynamic interface type
 	if (code.Flags & AddrForMarshalerFlags) != 0 {
 		if rv.CanAddr() {
@@ -418,7 +475,7 @@ func AppendMarshalJSONIndent(ctx *RuntimeContext, code *Opcode, b []byte, v inte
 		if !ok {
 			return AppendNull(ctx, b), nil
 		}
-		b, err := marshaler.MarshalJSON(ctx.Option.Context)
+		b, err := marshaler.MarshalJSON(contextForMarshaler(ctx, code))
 		if err != nil {
 			return nil, &errors.MarshalerError{Type: reflect.TypeOf(v), Err: err}
 		}
@@ -439,7 +496,7 @@ func AppendMarshalJSONIndent(ctx *RuntimeContext, code *Opcode, b []byte, v inte
 	indentedBuf, err := doIndent(
 		b,
 		marshalBuf,
-		string(ctx.Prefix)+strings.Repeat(string(ctx.IndentStr), int(ctx.BaseIndent+code.Indent)),
+		IndentPrefix(ctx, code.Indent),
 		string(ctx.IndentStr),
 		(ctx.Option.Flag&HTMLEscapeOption) != 0,
 	)
@@ -531,6 +588,23 @@ func AppendIndent(ctx *RuntimeContext, b []byte, indent uint32) []byte {
 	return b
 }
 
+// IndentPrefix returns the prefix of the lines indented by indent from the current depth of ctx.
+// The values encoded apart from the code set, like the results of the marshalers, are indented with it.
+func IndentPrefix(ctx *RuntimeContext, indent uint32) string {
+	return string(AppendIndent(ctx, nil, indent))
+}
+
+// IndentDiffFromTop returns how much deeper the top code of a code set is indented than the value it encodes.
+// The top codes of the structs and the maps are the fields and the keys indented one more than the value,
+// and the others like the slices and the marshalers are indented the same as the value.
+func IndentDiffFromTop(c *Opcode) uint32 {
+	switch c.Op.CodeType() {
+	case CodeStructField, CodeStructEnd, CodeMapHead:
+		return c.Indent - 1
+	}
+	return c.Indent
+}
+
 func IsNilForMarshaler(v interface{}) bool {
 	rv := reflect.ValueOf(v)
 	switch rv.Kind() {
@@ -549,3 +623,138 @@ func IsNilForMarshaler(v interface{}) bool {
 	}
 	return false
 }
+
+// IsEmptyForMarshaler reports whether the value of the omitempty marshaler operation is omitted.
+// The redacted value is checked before it is masked, like the omitempty field of its own type.
+func IsEmptyForMarshaler(code *Opcode, v interface{}) bool {
+	if (code.Flags & RedactFlags) == 0 {
+		return IsNilForMarshaler(v)
+	}
+	if v == nil {
+		return true
+	}
+	return isEmptyValue(reflect.ValueOf(v))
+}
+
+// IsNilForInterface reports whether the nil pointer of typ held by an interface is encoded as null.
+// The struct having only a pointer shape field is held as the pointer, so the nil pointer is the zero struct.
+func IsNilForInterface(typ *runtime.Type) bool {
+	return typ == nil || typ.Kind() != reflect.Struct
+}
+
+// OmitZeroFieldAddress returns the address of the field of the omitzero operation.
+// The pointer shape struct, which has only a pointer shape field, is the value of the field instead of the address,
+// so the value is copied to a new address that the zero check and the next operation refer to.
+func OmitZeroFieldAddress(ctx *RuntimeContext, code *Opcode, p uintptr) uintptr {
+	if p == 0 || (code.Flags&(IndirectFlags|AddrForMarshalerFlags)) != 0 {
+		return p
+	}
+	addr := new(uintptr)
+	*addr = p
+	ctx.KeepRefs = append(ctx.KeepRefs, unsafe.Pointer(addr))
+	return uintptr(unsafe.Pointer(addr))
+}
+
+// IsZeroField reports whether the field of a struct tagged with omitzero should be omitted.
+// The field is omitted if its IsZero method returns true, or if the field doesn't have IsZero
+// and holds the zero value of its type. If the field is also tagged with omitempty,
+// it is omitted when it is empty, too.
+// p is the address of the field.
+func IsZeroField(code *Opcode, p uintptr) bool {
+	if p == 0 {
+		return true
+	}
+	typ := code.Type
+	if (code.Flags & (IsZeroerFlags | IsPtrZeroerFlags | IsOmitEmptyFlags)) == 0 {
+		return isZeroMemoryOf(typ, p)
+	}
+	return isZeroValue(code, reflect.NewAt(runtime.RType2Type(typ), *(*unsafe.Pointer)(unsafe.Pointer(&p))).Elem())
+}
+
+func isZeroValue(code *Opcode, v reflect.Value) bool {
+	if (code.Flags&IsOmitEmptyFlags) != 0 && isEmptyValue(v) {
+		return true
+	}
+	switch {
+	case (code.Flags & IsZeroerFlags) != 0:
+		switch v.Kind() {
+		case reflect.Ptr, reflect.Interface:
+			if v.IsNil() {
+				return true
+			}
+		}
+		return v.Interface().(isZeroer).IsZero()
+	case (code.Flags & IsPtrZeroerFlags) != 0:
+		return v.Addr().Interface().(isZeroer).IsZero()
+	}
+	return isZeroMemoryOf(runtime.Type2RType(v.Type()), v.UnsafeAddr())
+}
+
+// isZeroMemoryOf reports whether the value of typ at p is the zero value.
+// The floats are compared by value like reflect.Value.IsZero, so -0 is zero.
+func isZeroMemoryOf(typ *runtime.Type, p uintptr) bool {
+	switch typ.Kind() {
+	case reflect.Float32:
+		return **(**float32)(unsafe.Pointer(&p)) == 0
+	case reflect.Float64:
+		return **(**float64)(unsafe.Pointer(&p)) == 0
+	case reflect.Array:
+		elem := typ.Elem()
+		switch elem.Kind() {
+		case reflect.Float32, reflect.Float64, reflect.Array, reflect.Struct:
+			for i := 0; i < typ.Len(); i++ {
+				if !isZeroMemoryOf(elem, p+uintptr(i)*elem.Size()) {
+					return false
+				}
+			}
+			return true
+		}
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if !isZeroMemoryOf(runtime.Type2RType(field.Type), p+field.Offset) {
+				return false
+			}
+		}
+		return true
+	}
+	return isZeroMemory(p, typ.Size())
+}
+
+func isZeroMemory(p uintptr, size uintptr) bool {
+	header := &runtime.SliceHeader{
+		Data: *(*unsafe.Pointer)(unsafe.Pointer(&p)),
+		Len:  int(size),
+		Cap:  int(size),
+	}
+	for _, c := range *(*[]byte)(unsafe.Pointer(header)) {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
+
+// IsNilAsEmpty reports whether a nil slice or map of code is encoded as an empty array or object
+// instead of null.
+func IsNilAsEmpty(ctx *RuntimeContext, code *Opcode) bool {
+	return (ctx.Option.Flag&EmptyCollectionsForNilOption) != 0 || (code.Flags&NoNilFlags) != 0
+}
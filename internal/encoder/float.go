@@ -0,0 +1,112 @@
+package encoder
+
+import (
+	"math"
+	"strconv"
+)
+
+const (
+	defaultMinFixedFloat = 1e-6
+	defaultMaxFixedFloat = 1e21
+)
+
+// FloatStyle controls how floating point numbers are formatted.
+// The zero value formats numbers like encoding/json.
+type FloatStyle struct {
+	// Notation is 'f' for fixed notation, 'e' for exponent notation,
+	// or 0 to choose exponent notation only if the absolute value is
+	// out of the range [MinFixed, MaxFixed).
+	Notation byte
+
+	// Precision is the number of significant digits.
+	// If it is 0, the smallest number of digits necessary to represent the value exactly is used.
+	Precision int
+
+	// MinFixed and MaxFixed are the thresholds of exponent notation when Notation is 0.
+	// Zero values mean 1e-6 and 1e21, the same as encoding/json.
+	MinFixed float64
+	MaxFixed float64
+
+	// TrailingZero appends ".0" to integral numbers in fixed notation, like 1.0 instead of 1.
+	TrailingZero bool
+}
+
+// floatStyle returns the style for code, and false if the default style is used.
+func floatStyle(ctx *RuntimeContext, code *Opcode) (FloatStyle, bool) {
+	var style FloatStyle
+	enabled := false
+	if (ctx.Option.Flag&FloatStyleOption) != 0 && ctx.Option.FloatStyle != nil {
+		style = *ctx.Option.FloatStyle
+		enabled = true
+	}
+	if code != nil && code.FloatFmt != 0 {
+		style.Notation = code.FloatFmt
+		enabled = true
+	}
+	if code != nil && code.FloatPrec != 0 {
+		style.Precision = int(code.FloatPrec)
+		enabled = true
+	}
+	return style, enabled
+}
+
+// AppendStyledFloat32 appends v formatted by the float style of the encode option and code.
+func AppendStyledFloat32(ctx *RuntimeContext, code *Opcode, b []byte, v float32) []byte {
+	style, enabled := floatStyle(ctx, code)
+	if !enabled {
+		return AppendFloat32(ctx, b, v)
+	}
+	return appendFloatWithStyle(b, float64(v), 32, &style)
+}
+
+// AppendStyledFloat64 appends v formatted by the float style of the encode option and code.
+func AppendStyledFloat64(ctx *RuntimeContext, code *Opcode, b []byte, v float64) []byte {
+	style, enabled := floatStyle(ctx, code)
+	if !enabled {
+		return AppendFloat64(ctx, b, v)
+	}
+	return appendFloatWithStyle(b, v, 64, &style)
+}
+
+func appendFloatWithStyle(b []byte, v float64, bitSize int, style *FloatStyle) []byte {
+	if style.Precision > 0 && v != 0 {
+		// round to the significant digits, then format the rounded value with the shortest digits.
+		rounded, err := strconv.ParseFloat(strconv.FormatFloat(v, 'e', style.Precision-1, bitSize), bitSize)
+		if err == nil {
+			v = rounded
+		}
+	}
+	fmt := style.Notation
+	if fmt != 'f' && fmt != 'e' {
+		fmt = 'f'
+		minFixed := style.MinFixed
+		if minFixed == 0 {
+			minFixed = defaultMinFixedFloat
+		}
+		maxFixed := style.MaxFixed
+		if maxFixed == 0 {
+			maxFixed = defaultMaxFixedFloat
+		}
+		abs := math.Abs(v)
+		if bitSize == 32 {
+			// Note: Must use float32 comparisons for underlying float32 value to get precise cutoffs right.
+			f32 := float32(abs)
+			if abs != 0 && (f32 < float32(minFixed) || f32 >= float32(maxFixed)) {
+				fmt = 'e'
+			}
+		} else if abs != 0 && (abs < minFixed || abs >= maxFixed) {
+			fmt = 'e'
+		}
+	}
+	start := len(b)
+	b = strconv.AppendFloat(b, v, fmt, -1, bitSize)
+	if style.TrailingZero && fmt == 'f' {
+		for _, c := range b[start:] {
+			if c == '.' {
+				return b
+			}
+		}
+		b = append(b, '.', '0')
+	}
+	return b
+}
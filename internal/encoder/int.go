@@ -128,3 +128,43 @@ func AppendUint(_ *RuntimeContext, out []byte, u64 uint64, code *Opcode) []byte
 	}
 	return append(out, b[i:]...)
 }
+
+// IsQuotedInt reports whether the signed integer u64 of a *String opcode is quoted.
+// The codes converted by IntAsStringOption are quoted only if the magnitude of the value exceeds the threshold.
+func IsQuotedInt(ctx *RuntimeContext, u64 uint64, code *Opcode) bool {
+	if code.Flags&IntStringFlags == 0 {
+		return true
+	}
+	mask := numMask(code.NumBitSize)
+	n := u64 & mask
+	if (u64>>(code.NumBitSize-1))&1 == 1 {
+		n = -n & mask
+	}
+	return n > ctx.Option.IntStringThreshold
+}
+
+// IsQuotedUint reports whether the unsigned integer u64 of a *String opcode is quoted.
+func IsQuotedUint(ctx *RuntimeContext, u64 uint64, code *Opcode) bool {
+	if code.Flags&IntStringFlags == 0 {
+		return true
+	}
+	return u64&numMask(code.NumBitSize) > ctx.Option.IntStringThreshold
+}
+
+func AppendIntString(ctx *RuntimeContext, out []byte, u64 uint64, code *Opcode) []byte {
+	if !IsQuotedInt(ctx, u64, code) {
+		return AppendInt(ctx, out, u64, code)
+	}
+	out = append(out, '"')
+	out = AppendInt(ctx, out, u64, code)
+	return append(out, '"')
+}
+
+func AppendUintString(ctx *RuntimeContext, out []byte, u64 uint64, code *Opcode) []byte {
+	if !IsQuotedUint(ctx, u64, code) {
+		return AppendUint(ctx, out, u64, code)
+	}
+	out = append(out, '"')
+	out = AppendUint(ctx, out, u64, code)
+	return append(out, '"')
+}
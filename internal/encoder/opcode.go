@@ -22,10 +22,19 @@ const (
 	IsNextOpPtrTypeFlags  OpFlags = 1 << 6
 	IsNilableTypeFlags    OpFlags = 1 << 7
 	MarshalerContextFlags OpFlags = 1 << 8
+	IsZeroerFlags         OpFlags = 1 << 9
+	IsPtrZeroerFlags      OpFlags = 1 << 10
+	IsOmitEmptyFlags      OpFlags = 1 << 11
+	IntStringFlags        OpFlags = 1 << 12
+	NoNilFlags            OpFlags = 1 << 13
+	RedactFlags           OpFlags = 1 << 14
+	ReferenceFlags        OpFlags = 1 << 15
 )
 
 type Opcode struct {
 	Op         OpType  // operation type
+	FloatFmt   uint8   // float notation specified by struct tag
+	FloatPrec  uint8   // float precision specified by struct tag
 	Idx        uint32  // offset to access ptr
 	Next       *Opcode // next opcode
 	End        *Opcode // array/slice/struct/map end
@@ -288,7 +297,7 @@ func copyOpcode(code *Opcode) *Opcode {
 func setTotalLengthToInterfaceOp(code *Opcode) {
 	c := code
 	for c.Op != OpEnd && c.Op != OpInterfaceEnd {
-		if c.Op == OpInterface {
+		if c.Op == OpInterface || c.Op == OpInterfacePtr {
 			c.Length = uint32(code.TotalLength())
 		}
 		switch c.Op.CodeType() {
@@ -364,7 +373,10 @@ func (c *Opcode) copy(codeMap map[uintptr]*Opcode) *Opcode {
 		MapPos:     c.MapPos,
 		Size:       c.Size,
 		Indent:     c.Indent,
+		FloatFmt:   c.FloatFmt,
+		FloatPrec:  c.FloatPrec,
 	}
+	copyFieldQuery(copied, c)
 	codeMap[addr] = copied
 	copied.End = c.End.copy(codeMap)
 	copied.PrevField = c.PrevField.copy(codeMap)
@@ -1,8 +1,13 @@
 package encoder
 
-import "context"
+import (
+	"context"
+	"io"
 
-type OptionFlag uint8
+	"github.com/goccy/go-json/internal/runtime"
+)
+
+type OptionFlag uint16
 
 const (
 	HTMLEscapeOption OptionFlag = 1 << iota
@@ -11,12 +16,37 @@ const (
 	DebugOption
 	ColorizeOption
 	ContextOption
+	CanonicalOption
+	NamingPolicyOption
+	FloatStyleOption
+	IntAsStringOption
+	EmptyCollectionsForNilOption
+	FieldQueryOption
+	RedactOption
+	PreserveReferencesOption
+	DebugTraceOption
 )
 
 type Option struct {
-	Flag        OptionFlag
-	ColorScheme *ColorScheme
-	Context     context.Context
+	Flag         OptionFlag
+	ColorScheme  *ColorScheme
+	Context      context.Context
+	NamingPolicy *runtime.NamingPolicy
+	FloatStyle   *FloatStyle
+	FieldQuery   *FieldQuery
+
+	// RedactPlaceholder is the string encoded instead of the redacted fields by RedactOption.
+	RedactPlaceholder string
+	// RedactOmit omits the redacted fields instead of encoding RedactPlaceholder.
+	RedactOmit bool
+
+	// IntStringBitSize is the minimum bit size of the integer types quoted by IntAsStringOption.
+	IntStringBitSize uint8
+	// IntStringThreshold is the maximum magnitude of the integers encoded without quotes by IntAsStringOption.
+	IntStringThreshold uint64
+
+	// DebugWriter receives the executed opcodes and the debug information on panics with DebugTraceOption.
+	DebugWriter io.Writer
 }
 
 type EncodeFormat struct {
@@ -33,6 +63,14 @@ type EncodeFormatScheme struct {
 	Binary    EncodeFormat
 	ObjectKey EncodeFormat
 	Null      EncodeFormat
+	// Delim, Comma and Colon are the formats of the punctuations, only used by ColorizeBytes.
+	Delim EncodeFormat
+	Comma EncodeFormat
+	Colon EncodeFormat
+
+	// EscapeHTML escapes <, > and & in strings as \u003c, \u003e and \u0026 even if HTML escaping is disabled,
+	// so that the decorated output is safe to embed in HTML.
+	EscapeHTML bool
 }
 
 type (
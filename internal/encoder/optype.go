@@ -22,7 +22,7 @@ const (
 	CodeStructEnd   CodeType = 11
 )
 
-var opTypeStrings = [401]string{
+var opTypeStrings = [404]string{
 	"End",
 	"Interface",
 	"Ptr",
@@ -424,6 +424,9 @@ var opTypeStrings = [401]string{
 	"StructFieldOmitEmpty",
 	"StructEnd",
 	"StructEndOmitEmpty",
+	"StructHeadOmitZero",
+	"StructFieldOmitZero",
+	"StructPtrHeadOmitZero",
 }
 
 type OpType uint16
@@ -830,10 +833,13 @@ const (
 	OpStructFieldOmitEmpty                   OpType = 398
 	OpStructEnd                              OpType = 399
 	OpStructEndOmitEmpty                     OpType = 400
+	OpStructHeadOmitZero                     OpType = 401
+	OpStructFieldOmitZero                    OpType = 402
+	OpStructPtrHeadOmitZero                  OpType = 403
 )
 
 func (t OpType) String() string {
-	if int(t) >= 401 {
+	if int(t) >= 404 {
 		return ""
 	}
 	return opTypeStrings[int(t)]
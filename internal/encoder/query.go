@@ -0,0 +1,166 @@
+package encoder
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// FieldQuery selects the object keys to encode.
+// The query of the root has no Name, and a query without Fields selects
+// the whole value of the field.
+type FieldQuery struct {
+	Name   string
+	Fields []*FieldQuery
+
+	// key is the canonical form of the query set by ParseFieldQuery.
+	key string
+}
+
+// Field returns the sub query of the field named name, and false if the field isn't selected.
+// If q is nil, all fields are selected without sub query.
+func (q *FieldQuery) Field(name string) (*FieldQuery, bool) {
+	if q == nil || len(q.Fields) == 0 {
+		return nil, true
+	}
+	for _, field := range q.Fields {
+		if field.Name == name {
+			if len(field.Fields) == 0 {
+				return nil, true
+			}
+			return field, true
+		}
+	}
+	return nil, false
+}
+
+// String returns the query in the form of "id,name,owner.email".
+func (q *FieldQuery) String() string {
+	if q == nil {
+		return ""
+	}
+	paths := []string{}
+	var walk func(prefix string, q *FieldQuery)
+	walk = func(prefix string, q *FieldQuery) {
+		for _, field := range q.Fields {
+			path := prefix + field.Name
+			if len(field.Fields) == 0 {
+				paths = append(paths, path)
+				continue
+			}
+			walk(path+".", field)
+		}
+	}
+	walk("", q)
+	return strings.Join(paths, ",")
+}
+
+// Key returns the canonical form of the query, which has the sorted paths.
+// The queries that select the same keys have the same Key, so the codes compiled for the query are cached by it.
+func (q *FieldQuery) Key() string {
+	if q == nil {
+		return ""
+	}
+	if q.key != "" {
+		return q.key
+	}
+	paths := strings.Split(q.String(), ",")
+	sort.Strings(paths)
+	return strings.Join(paths, ",")
+}
+
+// ParseFieldQuery parses a comma separated list of dotted key paths like "id,name,owner.email".
+func ParseFieldQuery(query string) (*FieldQuery, error) {
+	root := &FieldQuery{}
+	// whole keeps the queries selecting the whole value even if another path selects their subfields.
+	whole := map[*FieldQuery]bool{}
+	for _, path := range strings.Split(query, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			return nil, fmt.Errorf("json: empty field in query %q", query)
+		}
+		q := root
+		for _, name := range strings.Split(path, ".") {
+			if name == "" {
+				return nil, fmt.Errorf("json: empty field name in query %q", query)
+			}
+			if whole[q] {
+				break
+			}
+			q = q.child(name)
+		}
+		whole[q] = true
+		q.Fields = nil
+	}
+	root.setKeys()
+	return root, nil
+}
+
+// setKeys sets the canonical forms to q and its sub queries.
+func (q *FieldQuery) setKeys() {
+	q.key = q.Key()
+	for _, field := range q.Fields {
+		field.setKeys()
+	}
+}
+
+func (q *FieldQuery) child(name string) *FieldQuery {
+	for _, field := range q.Fields {
+		if field.Name == name {
+			return field
+		}
+	}
+	field := &FieldQuery{Name: name}
+	q.Fields = append(q.Fields, field)
+	return field
+}
+
+// fieldQueries holds the field query for the value of each interface and marshaler operation
+// compiled with a field query. It is kept out of Opcode so that the operations of
+// the encodings without a field query don't pay for it.
+var fieldQueries sync.Map // *Opcode -> *FieldQuery
+
+func setFieldQuery(code *Opcode, query *FieldQuery) {
+	if query != nil {
+		fieldQueries.Store(code, query)
+	}
+}
+
+func copyFieldQuery(dst, src *Opcode) {
+	if query, ok := fieldQueries.Load(src); ok {
+		fieldQueries.Store(dst, query)
+	}
+}
+
+// FieldQueryOf returns the field query for the value of the interface or marshaler operation.
+func FieldQueryOf(ctx *RuntimeContext, code *Opcode) *FieldQuery {
+	if (ctx.Option.Flag & FieldQueryOption) == 0 {
+		return nil
+	}
+	query, ok := fieldQueries.Load(code)
+	if !ok {
+		return nil
+	}
+	return query.(*FieldQuery)
+}
+
+type fieldQueryKey struct{}
+
+// FieldQueryFromContext returns the field query set by SetFieldQueryToContext.
+func FieldQueryFromContext(ctx context.Context) *FieldQuery {
+	if ctx == nil {
+		return nil
+	}
+	query, _ := ctx.Value(fieldQueryKey{}).(*FieldQuery)
+	return query
+}
+
+// SetFieldQueryToContext returns a copy of ctx with query.
+func SetFieldQueryToContext(ctx context.Context, query *FieldQuery) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, fieldQueryKey{}, query)
+}
@@ -0,0 +1,76 @@
+package encoder
+
+import (
+	"sync"
+
+	"github.com/goccy/go-json/internal/runtime"
+)
+
+type redactMode uint8
+
+const (
+	redactNone redactMode = iota
+	redactPlaceholder
+	redactOmit
+)
+
+func redactModeOf(opt *Option) redactMode {
+	if (opt.Flag & RedactOption) == 0 {
+		return redactNone
+	}
+	if opt.RedactOmit {
+		return redactOmit
+	}
+	return redactPlaceholder
+}
+
+var redactors sync.Map // map[*runtime.Type]func(interface{}) string
+
+// RegisterRedactor registers the function to mask the redacted values of typ.
+// The values of typ are redacted by RedactOption wherever they are, even if the fields aren't tagged with redact.
+func RegisterRedactor(typ *runtime.Type, redactor func(interface{}) string) {
+	redactors.Store(typ, redactor)
+}
+
+func redactorOf(typ *runtime.Type) func(interface{}) string {
+	if redactor, exists := redactors.Load(typ); exists {
+		return redactor.(func(interface{}) string)
+	}
+	return nil
+}
+
+// isRedactedType reports whether the values of typ are masked by the registered redactor,
+// like the elements of the slices and the arrays, the values of the maps and the dynamic values of the interfaces.
+func isRedactedType(ctx *compileContext, typ *runtime.Type) bool {
+	return ctx.redact != redactNone && redactorOf(typ) != nil
+}
+
+func isRedactedField(tag *runtime.StructTag, typ *runtime.Type) bool {
+	return tag.IsRedact || redactorOf(typ) != nil
+}
+
+// isOmittedRedactedField reports whether the field is dropped by the omit mode of RedactOption.
+// The fields of the types having a redactor are masked instead.
+func isOmittedRedactedField(ctx *compileContext, tag *runtime.StructTag, typ *runtime.Type) bool {
+	return ctx.redact == redactOmit && tag.IsRedact && redactorOf(typ) == nil
+}
+
+// compileRedacted compiles the value to pass it to appendRedacted by the marshaler operations.
+func compileRedacted(ctx *compileContext) (*Opcode, error) {
+	code := newOpCode(ctx, OpMarshalJSON)
+	code.Flags |= RedactFlags
+	if isNilableType(ctx.typ) {
+		code.Flags |= IsNilableTypeFlags
+	} else {
+		code.Flags &= ^IsNilableTypeFlags
+	}
+	ctx.incIndex()
+	return code, nil
+}
+
+func appendRedacted(ctx *RuntimeContext, code *Opcode, b []byte, v interface{}) []byte {
+	if redactor := redactorOf(code.Type); redactor != nil {
+		return AppendString(ctx, b, redactor(v))
+	}
+	return AppendString(ctx, b, ctx.Option.RedactPlaceholder)
+}
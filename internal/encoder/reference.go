@@ -0,0 +1,127 @@
+package encoder
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"github.com/goccy/go-json/internal/runtime"
+)
+
+var (
+	// ReferenceIDKeyCode is the key of the id written at the first visit of a pointer by PreserveReferencesOption.
+	ReferenceIDKeyCode = &Opcode{Key: `"$id":`, DisplayKey: "$id", Indent: 1}
+	// ReferenceKeyCode is the key of the id written at the later visits of a pointer by PreserveReferencesOption.
+	ReferenceKeyCode = &Opcode{Key: `"$ref":`, DisplayKey: "$ref", Indent: 1}
+)
+
+type referenceKey struct {
+	typ *runtime.Type
+	ptr uintptr
+}
+
+type referenceFrame struct {
+	level   int
+	pos     int
+	headLen int
+}
+
+// isReferenceType reports whether the values of typ are encoded with their ids by PreserveReferencesOption.
+// The pointers to structs are the targets, the other values are encoded normally.
+func isReferenceType(ctx *compileContext, typ *runtime.Type) bool {
+	return ctx.preserveReferences && typ.Kind() == reflect.Ptr && typ.Elem().Kind() == reflect.Struct
+}
+
+// compileReference compiles the pointer to a struct that is encoded by the code set of the struct at runtime,
+// so that the struct is written only at the first visit of the pointer.
+// The operation receives the address of the pointer.
+func compileReference(ctx *compileContext) (*Opcode, error) {
+	code, err := compileReferenceHead(ctx)
+	if err != nil {
+		return nil, err
+	}
+	code.Op = OpInterfacePtr
+	code.PtrNum = 1
+	return code, nil
+}
+
+// compileReferenceHead is like compileReference, but the operation receives the pointer itself.
+func compileReferenceHead(ctx *compileContext) (*Opcode, error) {
+	code := newInterfaceCode(ctx)
+	code.Flags |= ReferenceFlags
+	setFieldQuery(code, ctx.fieldQuery)
+	ctx.incIndex()
+	return code, nil
+}
+
+// ReferenceID returns the id of the pointer p to typ and true if p has been visited already.
+// Otherwise, it assigns a new id to p.
+func (c *RuntimeContext) ReferenceID(typ *runtime.Type, p uintptr) (string, bool) {
+	key := referenceKey{typ: typ, ptr: p}
+	if id, exists := c.referenceIDs[key]; exists {
+		return id, true
+	}
+	if c.referenceIDs == nil {
+		c.referenceIDs = map[referenceKey]string{}
+	}
+	id := strconv.Itoa(len(c.referenceIDs) + 1)
+	c.referenceIDs[key] = id
+	return id, false
+}
+
+// PushReference records that the struct of the recursive level begins at pos of the buffer,
+// after the object head of headLen bytes and the id written by the VM.
+func (c *RuntimeContext) PushReference(level, pos, headLen int) {
+	c.referenceFrames = append(c.referenceFrames, referenceFrame{level: level, pos: pos, headLen: headLen})
+}
+
+// PopReference returns the position and the head length recorded by PushReference,
+// and false if the recursive level doesn't encode a referenced struct.
+func (c *RuntimeContext) PopReference(level int) (int, int, bool) {
+	n := len(c.referenceFrames)
+	if n == 0 || c.referenceFrames[n-1].level != level {
+		return 0, 0, false
+	}
+	frame := c.referenceFrames[n-1]
+	c.referenceFrames = c.referenceFrames[:n-1]
+	return frame.pos, frame.headLen, true
+}
+
+// convertDirectReferenceOp makes the reference operations following the struct heads without IndirectFlags
+// receive the pointer itself, because the heads pass the value of the field instead of its address.
+// It happens on the struct holding only a pointer, which is stored in the interface directly.
+func convertDirectReferenceOp(code *Opcode) {
+	visited := map[*Opcode]struct{}{}
+	var convert func(*Opcode)
+	convert = func(code *Opcode) {
+		for ; code != nil; code = code.Next {
+			if _, exists := visited[code]; exists {
+				return
+			}
+			visited[code] = struct{}{}
+			next := code.Next
+			if next != nil && (next.Flags&ReferenceFlags) != 0 && next.Op == OpInterfacePtr &&
+				(code.Flags&IndirectFlags) == 0 && strings.Contains(code.Op.String(), "Head") {
+				next.PtrNum--
+			}
+			if code.Jmp != nil {
+				convert(code.Jmp.Code)
+			}
+			convert(code.End)
+			convert(code.NextField)
+		}
+	}
+	convert(code)
+}
+
+// ReferencedStruct returns the type and the interface data of the struct pointed by p
+// to encode it by the code set of the struct type.
+func ReferencedStruct(code *Opcode, p uintptr) (*runtime.Type, unsafe.Pointer) {
+	typ := code.Type.Elem()
+	if runtime.IfaceIndir(typ) {
+		return typ, *(*unsafe.Pointer)(unsafe.Pointer(&p))
+	}
+	// the struct holding only a pointer is stored in the interface directly.
+	return typ, **(**unsafe.Pointer)(unsafe.Pointer(&p))
+}
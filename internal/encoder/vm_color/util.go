@@ -97,17 +97,35 @@ func appendUint(ctx *encoder.RuntimeContext, b []byte, v uint64, code *encoder.O
 	return append(b, format.Footer...)
 }
 
-func appendFloat32(ctx *encoder.RuntimeContext, b []byte, v float32) []byte {
+func appendIntString(ctx *encoder.RuntimeContext, b []byte, v uint64, code *encoder.Opcode) []byte {
+	if !encoder.IsQuotedInt(ctx, v, code) {
+		return appendInt(ctx, b, v, code)
+	}
+	b = append(b, '"')
+	b = appendInt(ctx, b, v, code)
+	return append(b, '"')
+}
+
+func appendUintString(ctx *encoder.RuntimeContext, b []byte, v uint64, code *encoder.Opcode) []byte {
+	if !encoder.IsQuotedUint(ctx, v, code) {
+		return appendUint(ctx, b, v, code)
+	}
+	b = append(b, '"')
+	b = appendUint(ctx, b, v, code)
+	return append(b, '"')
+}
+
+func appendFloat32(ctx *encoder.RuntimeContext, code *encoder.Opcode, b []byte, v float32) []byte {
 	format := ctx.Option.ColorScheme.Float
 	b = append(b, format.Header...)
-	b = encoder.AppendFloat32(ctx, b, v)
+	b = encoder.AppendStyledFloat32(ctx, code, b, v)
 	return append(b, format.Footer...)
 }
 
-func appendFloat64(ctx *encoder.RuntimeContext, b []byte, v float64) []byte {
+func appendFloat64(ctx *encoder.RuntimeContext, code *encoder.Opcode, b []byte, v float64) []byte {
 	format := ctx.Option.ColorScheme.Float
 	b = append(b, format.Header...)
-	b = encoder.AppendFloat64(ctx, b, v)
+	b = encoder.AppendStyledFloat64(ctx, code, b, v)
 	return append(b, format.Footer...)
 }
 
@@ -19,8 +19,12 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 	} else {
 		code = codeSet.NoescapeKeyCode
 	}
+	tracing := (ctx.Option.Flag & encoder.DebugTraceOption) != 0
 
 	for {
+		if tracing {
+			encoder.TraceOpcode(ctx, code, b)
+		}
 		switch code.Op {
 		default:
 			return nil, errUnimplementedOp(code.Op)
@@ -56,18 +60,119 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			b = appendUint(ctx, b, ptrToUint64(load(ctxptr, code.Idx)), code)
 			b = appendComma(ctx, b)
 			code = code.Next
+		case encoder.OpIntPtrString:
+			p := loadNPtr(ctxptr, code.Idx, code.PtrNum)
+			if p == 0 {
+				b = appendNull(ctx, b)
+				b = appendComma(ctx, b)
+				code = code.Next
+				break
+			}
+			store(ctxptr, code.Idx, p)
+			fallthrough
 		case encoder.OpIntString:
+			b = appendIntString(ctx, b, ptrToUint64(load(ctxptr, code.Idx)), code)
+			b = appendComma(ctx, b)
+			code = code.Next
+		case encoder.OpUintPtrString:
+			p := loadNPtr(ctxptr, code.Idx, code.PtrNum)
+			if p == 0 {
+				b = appendNull(ctx, b)
+				b = appendComma(ctx, b)
+				code = code.Next
+				break
+			}
+			store(ctxptr, code.Idx, p)
+			fallthrough
+		case encoder.OpUintString:
+			b = appendUintString(ctx, b, ptrToUint64(load(ctxptr, code.Idx)), code)
+			b = appendComma(ctx, b)
+			code = code.Next
+		case encoder.OpFloat32PtrString:
+			p := loadNPtr(ctxptr, code.Idx, code.PtrNum)
+			if p == 0 {
+				b = appendNull(ctx, b)
+				b = appendComma(ctx, b)
+				code = code.Next
+				break
+			}
+			store(ctxptr, code.Idx, p)
+			fallthrough
+		case encoder.OpFloat32String:
 			b = append(b, '"')
-			b = appendInt(ctx, b, ptrToUint64(load(ctxptr, code.Idx)), code)
+			b = appendFloat32(ctx, code, b, ptrToFloat32(load(ctxptr, code.Idx)))
 			b = append(b, '"')
 			b = appendComma(ctx, b)
 			code = code.Next
-		case encoder.OpUintString:
+		case encoder.OpFloat64PtrString:
+			p := loadNPtr(ctxptr, code.Idx, code.PtrNum)
+			if p == 0 {
+				b = appendNull(ctx, b)
+				b = appendComma(ctx, b)
+				code = code.Next
+				break
+			}
+			store(ctxptr, code.Idx, p)
+			fallthrough
+		case encoder.OpFloat64String:
+			v := ptrToFloat64(load(ctxptr, code.Idx))
+			if math.IsInf(v, 0) || math.IsNaN(v) {
+				return nil, errUnsupportedFloat(v)
+			}
 			b = append(b, '"')
-			b = appendUint(ctx, b, ptrToUint64(load(ctxptr, code.Idx)), code)
+			b = appendFloat64(ctx, code, b, v)
 			b = append(b, '"')
 			b = appendComma(ctx, b)
 			code = code.Next
+		case encoder.OpBoolPtrString:
+			p := loadNPtr(ctxptr, code.Idx, code.PtrNum)
+			if p == 0 {
+				b = appendNull(ctx, b)
+				b = appendComma(ctx, b)
+				code = code.Next
+				break
+			}
+			store(ctxptr, code.Idx, p)
+			fallthrough
+		case encoder.OpBoolString:
+			b = append(b, '"')
+			b = appendBool(ctx, b, ptrToBool(load(ctxptr, code.Idx)))
+			b = append(b, '"')
+			b = appendComma(ctx, b)
+			code = code.Next
+		case encoder.OpStringPtrString:
+			p := loadNPtr(ctxptr, code.Idx, code.PtrNum)
+			if p == 0 {
+				b = appendNull(ctx, b)
+				b = appendComma(ctx, b)
+				code = code.Next
+				break
+			}
+			store(ctxptr, code.Idx, p)
+			fallthrough
+		case encoder.OpStringString:
+			b = appendString(ctx, b, string(appendString(ctx, []byte{}, ptrToString(load(ctxptr, code.Idx)))))
+			b = appendComma(ctx, b)
+			code = code.Next
+		case encoder.OpNumberPtrString:
+			p := loadNPtr(ctxptr, code.Idx, code.PtrNum)
+			if p == 0 {
+				b = appendNull(ctx, b)
+				b = appendComma(ctx, b)
+				code = code.Next
+				break
+			}
+			store(ctxptr, code.Idx, p)
+			fallthrough
+		case encoder.OpNumberString:
+			b = append(b, '"')
+			bb, err := appendNumber(ctx, b, ptrToNumber(load(ctxptr, code.Idx)))
+			if err != nil {
+				return nil, err
+			}
+			b = append(bb, '"')
+			b = appendComma(ctx, b)
+			code = code.Next
 		case encoder.OpFloat32Ptr:
 			p := loadNPtr(ctxptr, code.Idx, code.PtrNum)
 			if p == 0 {
@@ -79,7 +184,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			store(ctxptr, code.Idx, p)
 			fallthrough
 		case encoder.OpFloat32:
-			b = appendFloat32(ctx, b, ptrToFloat32(load(ctxptr, code.Idx)))
+			b = appendFloat32(ctx, code, b, ptrToFloat32(load(ctxptr, code.Idx)))
 			b = appendComma(ctx, b)
 			code = code.Next
 		case encoder.OpFloat64Ptr:
@@ -97,7 +202,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			if math.IsInf(v, 0) || math.IsNaN(v) {
 				return nil, errUnsupportedFloat(v)
 			}
-			b = appendFloat64(ctx, b, v)
+			b = appendFloat64(ctx, code, b, v)
 			b = appendComma(ctx, b)
 			code = code.Next
 		case encoder.OpStringPtr:
@@ -184,9 +289,31 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 					}
 				}
 			}
+			var referenceID string
+			if (code.Flags & encoder.ReferenceFlags) != 0 {
+				id, visited := ctx.ReferenceID(code.Type, p)
+				if visited {
+					oldBaseIndent := ctx.BaseIndent
+					ctx.BaseIndent += code.Indent
+					b = appendStructHead(ctx, b)
+					b = appendStructKey(ctx, encoder.ReferenceKeyCode, b)
+					b = appendString(ctx, b, id)
+					b = appendComma(ctx, b)
+					b = appendStructEndSkipLast(ctx, encoder.ReferenceKeyCode, b)
+					ctx.BaseIndent = oldBaseIndent
+					code = code.Next
+					break
+				}
+				referenceID = id
+			}
 			ctx.SeenPtr = append(ctx.SeenPtr, p)
 			iface := (*emptyInterface)(ptrToUnsafePtr(p))
-			if iface.ptr == nil {
+			if referenceID != "" {
+				// p is the pointer to the struct that is encoded by the code set of the struct type.
+				typ, ptr := encoder.ReferencedStruct(code, p)
+				iface = &emptyInterface{typ: typ, ptr: ptr}
+			}
+			if iface.ptr == nil && encoder.IsNilForInterface(iface.typ) {
 				b = appendNull(ctx, b)
 				b = appendComma(ctx, b)
 				code = code.Next
@@ -194,7 +321,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			}
 
 			ctx.KeepRefs = append(ctx.KeepRefs, unsafe.Pointer(iface))
-			ifaceCodeSet, err := encoder.CompileToGetCodeSet(uintptr(unsafe.Pointer(iface.typ)))
+			ifaceCodeSet, err := encoder.CompileToGetCodeSetWithQuery(uintptr(unsafe.Pointer(iface.typ)), ctx.Option, encoder.FieldQueryOf(ctx, code))
 			if err != nil {
 				return nil, err
 			}
@@ -213,8 +340,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			oldOffset := ptrOffset
 			ptrOffset += totalLength * uintptrSize
 			oldBaseIndent := ctx.BaseIndent
-			indentDiffFromTop := c.Indent - 1
-			ctx.BaseIndent += code.Indent - indentDiffFromTop
+			ctx.BaseIndent += code.Indent - encoder.IndentDiffFromTop(c)
 
 			newLen := offsetNum + totalLength + nextTotalLength
 			if curlen < newLen {
@@ -222,6 +348,17 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			}
 			ctxptr = ctx.Ptr() + ptrOffset // assign new ctxptr
 
+			if referenceID != "" {
+				// write the id as the first field, and remove the head of the struct at OpInterfaceEnd.
+				start := len(b)
+				b = appendStructHead(ctx, b)
+				headLen := len(b) - start
+				b = appendStructKey(ctx, encoder.ReferenceIDKeyCode, b)
+				b = appendString(ctx, b, referenceID)
+				b = appendComma(ctx, b)
+				ctx.PushReference(recursiveLevel+1, len(b), headLen)
+			}
+
 			end := ifaceCodeSet.EndCode
 			store(ctxptr, c.Idx, uintptr(iface.ptr))
 			store(ctxptr, end.Idx, oldOffset)
@@ -230,6 +367,14 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			code = c
 			recursiveLevel++
 		case encoder.OpInterfaceEnd:
+			if pos, headLen, ok := ctx.PopReference(recursiveLevel); ok {
+				if b[pos+1] == '}' {
+					// the struct has no fields to encode.
+					b = appendStructEndSkipLast(ctx, encoder.ReferenceIDKeyCode, b[:pos])
+				} else {
+					b = append(b[:pos], b[pos+headLen:]...)
+				}
+			}
 			recursiveLevel--
 
 			// restore ctxptr
@@ -309,8 +454,12 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			p := load(ctxptr, code.Idx)
 			slice := ptrToSlice(p)
 			if p == 0 || slice.Data == nil {
-				b = appendNull(ctx, b)
-				b = appendComma(ctx, b)
+				if p != 0 && encoder.IsNilAsEmpty(ctx, code) {
+					b = appendEmptyArray(ctx, b)
+				} else {
+					b = appendNull(ctx, b)
+					b = appendComma(ctx, b)
+				}
 				code = code.End.Next
 				break
 			}
@@ -330,6 +479,13 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			length := load(ctxptr, code.Length)
 			idx++
 			if idx < length {
+				if ctx.FlushWriter != nil {
+					bb, err := encoder.FlushBuffer(ctx, b)
+					if err != nil {
+						return nil, err
+					}
+					b = bb
+				}
 				b = appendArrayElemIndent(ctx, code, b)
 				store(ctxptr, code.ElemIdx, idx)
 				data := load(ctxptr, code.Idx)
@@ -371,6 +527,13 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			idx := load(ctxptr, code.ElemIdx)
 			idx++
 			if idx < uintptr(code.Length) {
+				if ctx.FlushWriter != nil {
+					bb, err := encoder.FlushBuffer(ctx, b)
+					if err != nil {
+						return nil, err
+					}
+					b = bb
+				}
 				b = appendArrayElemIndent(ctx, code, b)
 				store(ctxptr, code.ElemIdx, idx)
 				p := load(ctxptr, code.Idx)
@@ -384,8 +547,13 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 		case encoder.OpMapPtr:
 			p := loadNPtr(ctxptr, code.Idx, code.PtrNum)
 			if p == 0 {
-				b = appendNull(ctx, b)
-				b = appendComma(ctx, b)
+				if encoder.IsNilAsEmpty(ctx, code) && code.PtrNum > 0 && loadNPtr(ctxptr, code.Idx, code.PtrNum-1) != 0 {
+					// the pointer is not nil but the map is nil.
+					b = appendEmptyObject(ctx, b)
+				} else {
+					b = appendNull(ctx, b)
+					b = appendComma(ctx, b)
+				}
 				code = code.End.Next
 				break
 			}
@@ -394,8 +562,12 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 		case encoder.OpMap:
 			p := load(ctxptr, code.Idx)
 			if p == 0 {
-				b = appendNull(ctx, b)
-				b = appendComma(ctx, b)
+				if encoder.IsNilAsEmpty(ctx, code) {
+					b = appendEmptyObject(ctx, b)
+				} else {
+					b = appendNull(ctx, b)
+					b = appendComma(ctx, b)
+				}
 				code = code.End.Next
 				break
 			}
@@ -415,8 +587,10 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			if (ctx.Option.Flag & encoder.UnorderedMapOption) != 0 {
 				b = appendMapKeyIndent(ctx, code.Next, b)
 			} else {
-				mapCtx := encoder.NewMapContext(mlen)
+				mapCtx := encoder.NewMapContext(mlen, (ctx.Option.Flag&encoder.CanonicalOption) != 0)
 				mapCtx.Pos = append(mapCtx.Pos, len(b))
+				// buffer positions are kept until OpMapEnd, so the buffer must not be flushed.
+				ctx.SortedMapDepth++
 				ctx.KeepRefs = append(ctx.KeepRefs, unsafe.Pointer(mapCtx))
 				store(ctxptr, code.End.MapPos, uintptr(unsafe.Pointer(mapCtx)))
 			}
@@ -429,6 +603,13 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			idx++
 			if (ctx.Option.Flag & encoder.UnorderedMapOption) != 0 {
 				if idx < length {
+					if ctx.FlushWriter != nil {
+						bb, err := encoder.FlushBuffer(ctx, b)
+						if err != nil {
+							return nil, err
+						}
+						b = bb
+					}
 					b = appendMapKeyIndent(ctx, code, b)
 					store(ctxptr, code.ElemIdx, idx)
 					ptr := load(ctxptr, code.MapIter)
@@ -499,6 +680,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			b = append(b, buf...)
 			mapCtx.Buf = buf
 			encoder.ReleaseMapContext(mapCtx)
+			ctx.SortedMapDepth--
 			code = code.Next
 		case encoder.OpRecursivePtr:
 			p := load(ctxptr, code.Idx)
@@ -617,6 +799,39 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 				code = code.Next
 				store(ctxptr, code.Idx, p)
 			}
+		case encoder.OpStructPtrHeadOmitZero:
+			p := load(ctxptr, code.Idx)
+			if p == 0 {
+				if code.Flags&encoder.AnonymousHeadFlags == 0 {
+					b = appendNull(ctx, b)
+					b = appendComma(ctx, b)
+				}
+				code = code.End.Next
+				break
+			}
+			store(ctxptr, code.Idx, ptrToNPtr(p, code.PtrNum))
+			fallthrough
+		case encoder.OpStructHeadOmitZero:
+			p := load(ctxptr, code.Idx)
+			if p == 0 && ((code.Flags&encoder.IndirectFlags) != 0 || code.Next.Op == encoder.OpStructEnd) {
+				if code.Flags&encoder.AnonymousHeadFlags == 0 {
+					b = appendNull(ctx, b)
+					b = appendComma(ctx, b)
+				}
+				code = code.End.Next
+				break
+			}
+			if code.Flags&encoder.AnonymousHeadFlags == 0 {
+				b = appendStructHead(ctx, b)
+			}
+			p = encoder.OmitZeroFieldAddress(ctx, code, p+uintptr(code.Offset))
+			if encoder.IsZeroField(code, p) {
+				code = code.NextField
+			} else {
+				b = appendStructKey(ctx, code, b)
+				code = code.Next
+				store(ctxptr, code.Idx, p)
+			}
 		case encoder.OpStructPtrHeadInt:
 			if (code.Flags & encoder.IndirectFlags) != 0 {
 				p := load(ctxptr, code.Idx)
@@ -713,9 +928,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 				b = appendStructHead(ctx, b)
 			}
 			b = appendStructKey(ctx, code, b)
-			b = append(b, '"')
-			b = appendInt(ctx, b, ptrToUint64(p+uintptr(code.Offset)), code)
-			b = append(b, '"')
+			b = appendIntString(ctx, b, ptrToUint64(p+uintptr(code.Offset)), code)
 			b = appendComma(ctx, b)
 			code = code.Next
 		case encoder.OpStructPtrHeadOmitEmptyIntString:
@@ -751,9 +964,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 				code = code.NextField
 			} else {
 				b = appendStructKey(ctx, code, b)
-				b = append(b, '"')
-				b = appendInt(ctx, b, u64, code)
-				b = append(b, '"')
+				b = appendIntString(ctx, b, u64, code)
 				b = appendComma(ctx, b)
 				code = code.Next
 			}
@@ -859,9 +1070,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			if p == 0 {
 				b = appendNull(ctx, b)
 			} else {
-				b = append(b, '"')
-				b = appendInt(ctx, b, ptrToUint64(p), code)
-				b = append(b, '"')
+				b = appendIntString(ctx, b, ptrToUint64(p), code)
 			}
 			b = appendComma(ctx, b)
 			code = code.Next
@@ -895,9 +1104,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			}
 			if p != 0 {
 				b = appendStructKey(ctx, code, b)
-				b = append(b, '"')
-				b = appendInt(ctx, b, ptrToUint64(p), code)
-				b = append(b, '"')
+				b = appendIntString(ctx, b, ptrToUint64(p), code)
 				b = appendComma(ctx, b)
 			}
 			code = code.Next
@@ -997,9 +1204,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 				b = appendStructHead(ctx, b)
 			}
 			b = appendStructKey(ctx, code, b)
-			b = append(b, '"')
-			b = appendUint(ctx, b, ptrToUint64(p+uintptr(code.Offset)), code)
-			b = append(b, '"')
+			b = appendUintString(ctx, b, ptrToUint64(p+uintptr(code.Offset)), code)
 			b = appendComma(ctx, b)
 			code = code.Next
 		case encoder.OpStructPtrHeadOmitEmptyUintString:
@@ -1035,9 +1240,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 				code = code.NextField
 			} else {
 				b = appendStructKey(ctx, code, b)
-				b = append(b, '"')
-				b = appendUint(ctx, b, u64, code)
-				b = append(b, '"')
+				b = appendUintString(ctx, b, u64, code)
 				b = appendComma(ctx, b)
 				code = code.Next
 			}
@@ -1143,9 +1346,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			if p == 0 {
 				b = appendNull(ctx, b)
 			} else {
-				b = append(b, '"')
-				b = appendUint(ctx, b, ptrToUint64(p), code)
-				b = append(b, '"')
+				b = appendUintString(ctx, b, ptrToUint64(p), code)
 			}
 			b = appendComma(ctx, b)
 			code = code.Next
@@ -1179,9 +1380,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			}
 			if p != 0 {
 				b = appendStructKey(ctx, code, b)
-				b = append(b, '"')
-				b = appendUint(ctx, b, ptrToUint64(p), code)
-				b = append(b, '"')
+				b = appendUintString(ctx, b, ptrToUint64(p), code)
 				b = appendComma(ctx, b)
 			}
 			code = code.Next
@@ -1213,7 +1412,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 				b = appendStructHead(ctx, b)
 			}
 			b = appendStructKey(ctx, code, b)
-			b = appendFloat32(ctx, b, ptrToFloat32(p+uintptr(code.Offset)))
+			b = appendFloat32(ctx, code, b, ptrToFloat32(p+uintptr(code.Offset)))
 			b = appendComma(ctx, b)
 			code = code.Next
 		case encoder.OpStructPtrHeadOmitEmptyFloat32:
@@ -1248,7 +1447,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 				code = code.NextField
 			} else {
 				b = appendStructKey(ctx, code, b)
-				b = appendFloat32(ctx, b, v)
+				b = appendFloat32(ctx, code, b, v)
 				b = appendComma(ctx, b)
 				code = code.Next
 			}
@@ -1281,7 +1480,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			}
 			b = appendStructKey(ctx, code, b)
 			b = append(b, '"')
-			b = appendFloat32(ctx, b, ptrToFloat32(p+uintptr(code.Offset)))
+			b = appendFloat32(ctx, code, b, ptrToFloat32(p+uintptr(code.Offset)))
 			b = append(b, '"')
 			b = appendComma(ctx, b)
 			code = code.Next
@@ -1318,7 +1517,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			} else {
 				b = appendStructKey(ctx, code, b)
 				b = append(b, '"')
-				b = appendFloat32(ctx, b, v)
+				b = appendFloat32(ctx, code, b, v)
 				b = append(b, '"')
 				b = appendComma(ctx, b)
 				code = code.Next
@@ -1355,7 +1554,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			if p == 0 {
 				b = appendNull(ctx, b)
 			} else {
-				b = appendFloat32(ctx, b, ptrToFloat32(p))
+				b = appendFloat32(ctx, code, b, ptrToFloat32(p))
 			}
 			b = appendComma(ctx, b)
 			code = code.Next
@@ -1389,7 +1588,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			}
 			if p != 0 {
 				b = appendStructKey(ctx, code, b)
-				b = appendFloat32(ctx, b, ptrToFloat32(p))
+				b = appendFloat32(ctx, code, b, ptrToFloat32(p))
 				b = appendComma(ctx, b)
 			}
 			code = code.Next
@@ -1426,7 +1625,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 				b = appendNull(ctx, b)
 			} else {
 				b = append(b, '"')
-				b = appendFloat32(ctx, b, ptrToFloat32(p))
+				b = appendFloat32(ctx, code, b, ptrToFloat32(p))
 				b = append(b, '"')
 			}
 			b = appendComma(ctx, b)
@@ -1462,7 +1661,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			if p != 0 {
 				b = appendStructKey(ctx, code, b)
 				b = append(b, '"')
-				b = appendFloat32(ctx, b, ptrToFloat32(p))
+				b = appendFloat32(ctx, code, b, ptrToFloat32(p))
 				b = append(b, '"')
 				b = appendComma(ctx, b)
 			}
@@ -1499,7 +1698,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 				b = appendStructHead(ctx, b)
 			}
 			b = appendStructKey(ctx, code, b)
-			b = appendFloat64(ctx, b, v)
+			b = appendFloat64(ctx, code, b, v)
 			b = appendComma(ctx, b)
 			code = code.Next
 		case encoder.OpStructPtrHeadOmitEmptyFloat64:
@@ -1537,7 +1736,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 					return nil, errUnsupportedFloat(v)
 				}
 				b = appendStructKey(ctx, code, b)
-				b = appendFloat64(ctx, b, v)
+				b = appendFloat64(ctx, code, b, v)
 				b = appendComma(ctx, b)
 				code = code.Next
 			}
@@ -1574,7 +1773,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			}
 			b = appendStructKey(ctx, code, b)
 			b = append(b, '"')
-			b = appendFloat64(ctx, b, v)
+			b = appendFloat64(ctx, code, b, v)
 			b = append(b, '"')
 			b = appendComma(ctx, b)
 			code = code.Next
@@ -1614,7 +1813,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 				}
 				b = appendStructKey(ctx, code, b)
 				b = append(b, '"')
-				b = appendFloat64(ctx, b, v)
+				b = appendFloat64(ctx, code, b, v)
 				b = append(b, '"')
 				b = appendComma(ctx, b)
 				code = code.Next
@@ -1655,7 +1854,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 				if math.IsInf(v, 0) || math.IsNaN(v) {
 					return nil, errUnsupportedFloat(v)
 				}
-				b = appendFloat64(ctx, b, v)
+				b = appendFloat64(ctx, code, b, v)
 			}
 			b = appendComma(ctx, b)
 			code = code.Next
@@ -1693,7 +1892,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 				if math.IsInf(v, 0) || math.IsNaN(v) {
 					return nil, errUnsupportedFloat(v)
 				}
-				b = appendFloat64(ctx, b, v)
+				b = appendFloat64(ctx, code, b, v)
 				b = appendComma(ctx, b)
 			}
 			code = code.Next
@@ -1734,7 +1933,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 				if math.IsInf(v, 0) || math.IsNaN(v) {
 					return nil, errUnsupportedFloat(v)
 				}
-				b = appendFloat64(ctx, b, v)
+				b = appendFloat64(ctx, code, b, v)
 				b = append(b, '"')
 			}
 			b = appendComma(ctx, b)
@@ -1774,7 +1973,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 				if math.IsInf(v, 0) || math.IsNaN(v) {
 					return nil, errUnsupportedFloat(v)
 				}
-				b = appendFloat64(ctx, b, v)
+				b = appendFloat64(ctx, code, b, v)
 				b = append(b, '"')
 				b = appendComma(ctx, b)
 			}
@@ -3176,7 +3375,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 				}
 			}
 			iface := ptrToInterface(code, p)
-			if (code.Flags&encoder.NilCheckFlags) != 0 && encoder.IsNilForMarshaler(iface) {
+			if (code.Flags&encoder.NilCheckFlags) != 0 && encoder.IsEmptyForMarshaler(code, iface) {
 				code = code.NextField
 			} else {
 				b = appendStructKey(ctx, code, b)
@@ -3453,6 +3652,16 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 				code = code.Next
 				store(ctxptr, code.Idx, p)
 			}
+		case encoder.OpStructFieldOmitZero:
+			p := load(ctxptr, code.Idx)
+			p += uintptr(code.Offset)
+			if encoder.IsZeroField(code, p) {
+				code = code.NextField
+			} else {
+				b = appendStructKey(ctx, code, b)
+				code = code.Next
+				store(ctxptr, code.Idx, p)
+			}
 		case encoder.OpStructFieldInt:
 			p := load(ctxptr, code.Idx)
 			b = appendStructKey(ctx, code, b)
@@ -3472,9 +3681,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 		case encoder.OpStructFieldIntString:
 			p := load(ctxptr, code.Idx)
 			b = appendStructKey(ctx, code, b)
-			b = append(b, '"')
-			b = appendInt(ctx, b, ptrToUint64(p+uintptr(code.Offset)), code)
-			b = append(b, '"')
+			b = appendIntString(ctx, b, ptrToUint64(p+uintptr(code.Offset)), code)
 			b = appendComma(ctx, b)
 			code = code.Next
 		case encoder.OpStructFieldOmitEmptyIntString:
@@ -3483,9 +3690,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			v := u64 & ((1 << code.NumBitSize) - 1)
 			if v != 0 {
 				b = appendStructKey(ctx, code, b)
-				b = append(b, '"')
-				b = appendInt(ctx, b, u64, code)
-				b = append(b, '"')
+				b = appendIntString(ctx, b, u64, code)
 				b = appendComma(ctx, b)
 			}
 			code = code.Next
@@ -3516,9 +3721,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			if p == 0 {
 				b = appendNull(ctx, b)
 			} else {
-				b = append(b, '"')
-				b = appendInt(ctx, b, ptrToUint64(p), code)
-				b = append(b, '"')
+				b = appendIntString(ctx, b, ptrToUint64(p), code)
 			}
 			b = appendComma(ctx, b)
 			code = code.Next
@@ -3527,9 +3730,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			p = ptrToNPtr(p+uintptr(code.Offset), code.PtrNum)
 			if p != 0 {
 				b = appendStructKey(ctx, code, b)
-				b = append(b, '"')
-				b = appendInt(ctx, b, ptrToUint64(p), code)
-				b = append(b, '"')
+				b = appendIntString(ctx, b, ptrToUint64(p), code)
 				b = appendComma(ctx, b)
 			}
 			code = code.Next
@@ -3552,9 +3753,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 		case encoder.OpStructFieldUintString:
 			p := load(ctxptr, code.Idx)
 			b = appendStructKey(ctx, code, b)
-			b = append(b, '"')
-			b = appendUint(ctx, b, ptrToUint64(p+uintptr(code.Offset)), code)
-			b = append(b, '"')
+			b = appendUintString(ctx, b, ptrToUint64(p+uintptr(code.Offset)), code)
 			b = appendComma(ctx, b)
 			code = code.Next
 		case encoder.OpStructFieldOmitEmptyUintString:
@@ -3563,9 +3762,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			v := u64 & ((1 << code.NumBitSize) - 1)
 			if v != 0 {
 				b = appendStructKey(ctx, code, b)
-				b = append(b, '"')
-				b = appendUint(ctx, b, u64, code)
-				b = append(b, '"')
+				b = appendUintString(ctx, b, u64, code)
 				b = appendComma(ctx, b)
 			}
 			code = code.Next
@@ -3596,9 +3793,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			if p == 0 {
 				b = appendNull(ctx, b)
 			} else {
-				b = append(b, '"')
-				b = appendUint(ctx, b, ptrToUint64(p), code)
-				b = append(b, '"')
+				b = appendUintString(ctx, b, ptrToUint64(p), code)
 			}
 			b = appendComma(ctx, b)
 			code = code.Next
@@ -3607,16 +3802,14 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			p = ptrToNPtr(p+uintptr(code.Offset), code.PtrNum)
 			if p != 0 {
 				b = appendStructKey(ctx, code, b)
-				b = append(b, '"')
-				b = appendUint(ctx, b, ptrToUint64(p), code)
-				b = append(b, '"')
+				b = appendUintString(ctx, b, ptrToUint64(p), code)
 				b = appendComma(ctx, b)
 			}
 			code = code.Next
 		case encoder.OpStructFieldFloat32:
 			p := load(ctxptr, code.Idx)
 			b = appendStructKey(ctx, code, b)
-			b = appendFloat32(ctx, b, ptrToFloat32(p+uintptr(code.Offset)))
+			b = appendFloat32(ctx, code, b, ptrToFloat32(p+uintptr(code.Offset)))
 			b = appendComma(ctx, b)
 			code = code.Next
 		case encoder.OpStructFieldOmitEmptyFloat32:
@@ -3624,7 +3817,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			v := ptrToFloat32(p + uintptr(code.Offset))
 			if v != 0 {
 				b = appendStructKey(ctx, code, b)
-				b = appendFloat32(ctx, b, v)
+				b = appendFloat32(ctx, code, b, v)
 				b = appendComma(ctx, b)
 			}
 			code = code.Next
@@ -3632,7 +3825,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			p := load(ctxptr, code.Idx)
 			b = appendStructKey(ctx, code, b)
 			b = append(b, '"')
-			b = appendFloat32(ctx, b, ptrToFloat32(p+uintptr(code.Offset)))
+			b = appendFloat32(ctx, code, b, ptrToFloat32(p+uintptr(code.Offset)))
 			b = append(b, '"')
 			b = appendComma(ctx, b)
 			code = code.Next
@@ -3642,7 +3835,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			if v != 0 {
 				b = appendStructKey(ctx, code, b)
 				b = append(b, '"')
-				b = appendFloat32(ctx, b, v)
+				b = appendFloat32(ctx, code, b, v)
 				b = append(b, '"')
 				b = appendComma(ctx, b)
 			}
@@ -3654,7 +3847,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			if p == 0 {
 				b = appendNull(ctx, b)
 			} else {
-				b = appendFloat32(ctx, b, ptrToFloat32(p))
+				b = appendFloat32(ctx, code, b, ptrToFloat32(p))
 			}
 			b = appendComma(ctx, b)
 			code = code.Next
@@ -3663,7 +3856,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			p = ptrToNPtr(p+uintptr(code.Offset), code.PtrNum)
 			if p != 0 {
 				b = appendStructKey(ctx, code, b)
-				b = appendFloat32(ctx, b, ptrToFloat32(p))
+				b = appendFloat32(ctx, code, b, ptrToFloat32(p))
 				b = appendComma(ctx, b)
 			}
 			code = code.Next
@@ -3675,7 +3868,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 				b = appendNull(ctx, b)
 			} else {
 				b = append(b, '"')
-				b = appendFloat32(ctx, b, ptrToFloat32(p))
+				b = appendFloat32(ctx, code, b, ptrToFloat32(p))
 				b = append(b, '"')
 			}
 			b = appendComma(ctx, b)
@@ -3686,7 +3879,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			if p != 0 {
 				b = appendStructKey(ctx, code, b)
 				b = append(b, '"')
-				b = appendFloat32(ctx, b, ptrToFloat32(p))
+				b = appendFloat32(ctx, code, b, ptrToFloat32(p))
 				b = append(b, '"')
 				b = appendComma(ctx, b)
 			}
@@ -3698,7 +3891,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			if math.IsInf(v, 0) || math.IsNaN(v) {
 				return nil, errUnsupportedFloat(v)
 			}
-			b = appendFloat64(ctx, b, v)
+			b = appendFloat64(ctx, code, b, v)
 			b = appendComma(ctx, b)
 			code = code.Next
 		case encoder.OpStructFieldOmitEmptyFloat64:
@@ -3709,7 +3902,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 					return nil, errUnsupportedFloat(v)
 				}
 				b = appendStructKey(ctx, code, b)
-				b = appendFloat64(ctx, b, v)
+				b = appendFloat64(ctx, code, b, v)
 				b = appendComma(ctx, b)
 			}
 			code = code.Next
@@ -3721,7 +3914,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			}
 			b = appendStructKey(ctx, code, b)
 			b = append(b, '"')
-			b = appendFloat64(ctx, b, v)
+			b = appendFloat64(ctx, code, b, v)
 			b = append(b, '"')
 			b = appendComma(ctx, b)
 			code = code.Next
@@ -3734,7 +3927,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 				}
 				b = appendStructKey(ctx, code, b)
 				b = append(b, '"')
-				b = appendFloat64(ctx, b, v)
+				b = appendFloat64(ctx, code, b, v)
 				b = append(b, '"')
 				b = appendComma(ctx, b)
 			}
@@ -3753,7 +3946,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			if math.IsInf(v, 0) || math.IsNaN(v) {
 				return nil, errUnsupportedFloat(v)
 			}
-			b = appendFloat64(ctx, b, v)
+			b = appendFloat64(ctx, code, b, v)
 			b = appendComma(ctx, b)
 			code = code.Next
 		case encoder.OpStructFieldOmitEmptyFloat64Ptr:
@@ -3765,7 +3958,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 				if math.IsInf(v, 0) || math.IsNaN(v) {
 					return nil, errUnsupportedFloat(v)
 				}
-				b = appendFloat64(ctx, b, v)
+				b = appendFloat64(ctx, code, b, v)
 				b = appendComma(ctx, b)
 			}
 			code = code.Next
@@ -3781,7 +3974,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 					return nil, errUnsupportedFloat(v)
 				}
 				b = append(b, '"')
-				b = appendFloat64(ctx, b, v)
+				b = appendFloat64(ctx, code, b, v)
 				b = append(b, '"')
 			}
 			b = appendComma(ctx, b)
@@ -3796,7 +3989,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 				if math.IsInf(v, 0) || math.IsNaN(v) {
 					return nil, errUnsupportedFloat(v)
 				}
-				b = appendFloat64(ctx, b, v)
+				b = appendFloat64(ctx, code, b, v)
 				b = append(b, '"')
 				b = appendComma(ctx, b)
 			}
@@ -4117,7 +4310,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 				break
 			}
 			iface := ptrToInterface(code, p)
-			if (code.Flags&encoder.NilCheckFlags) != 0 && encoder.IsNilForMarshaler(iface) {
+			if (code.Flags&encoder.NilCheckFlags) != 0 && encoder.IsEmptyForMarshaler(code, iface) {
 				code = code.NextField
 				break
 			}
@@ -4298,9 +4491,13 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			b = appendStructKey(ctx, code, b)
 			p := load(ctxptr, code.Idx)
 			p = ptrToPtr(p + uintptr(code.Offset))
-			if p != 0 {
-				p = ptrToNPtr(p, code.PtrNum)
+			if p == 0 {
+				b = appendNull(ctx, b)
+				b = appendComma(ctx, b)
+				code = code.NextField
+				break
 			}
+			p = ptrToNPtr(p, code.PtrNum)
 			code = code.Next
 			store(ctxptr, code.Idx, p)
 		case encoder.OpStructFieldOmitEmptyMapPtr:
@@ -4358,9 +4555,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 		case encoder.OpStructEndIntString:
 			p := load(ctxptr, code.Idx)
 			b = appendStructKey(ctx, code, b)
-			b = append(b, '"')
-			b = appendInt(ctx, b, ptrToUint64(p+uintptr(code.Offset)), code)
-			b = append(b, '"')
+			b = appendIntString(ctx, b, ptrToUint64(p+uintptr(code.Offset)), code)
 			b = appendStructEnd(ctx, code, b)
 			code = code.Next
 		case encoder.OpStructEndOmitEmptyIntString:
@@ -4369,9 +4564,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			v := u64 & ((1 << code.NumBitSize) - 1)
 			if v != 0 {
 				b = appendStructKey(ctx, code, b)
-				b = append(b, '"')
-				b = appendInt(ctx, b, u64, code)
-				b = append(b, '"')
+				b = appendIntString(ctx, b, u64, code)
 				b = appendStructEnd(ctx, code, b)
 			} else {
 				b = appendStructEndSkipLast(ctx, code, b)
@@ -4406,9 +4599,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			if p == 0 {
 				b = appendNull(ctx, b)
 			} else {
-				b = append(b, '"')
-				b = appendInt(ctx, b, ptrToUint64(p), code)
-				b = append(b, '"')
+				b = appendIntString(ctx, b, ptrToUint64(p), code)
 			}
 			b = appendStructEnd(ctx, code, b)
 			code = code.Next
@@ -4417,9 +4608,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			p = ptrToNPtr(p+uintptr(code.Offset), code.PtrNum)
 			if p != 0 {
 				b = appendStructKey(ctx, code, b)
-				b = append(b, '"')
-				b = appendInt(ctx, b, ptrToUint64(p), code)
-				b = append(b, '"')
+				b = appendIntString(ctx, b, ptrToUint64(p), code)
 				b = appendStructEnd(ctx, code, b)
 			} else {
 				b = appendStructEndSkipLast(ctx, code, b)
@@ -4446,9 +4635,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 		case encoder.OpStructEndUintString:
 			p := load(ctxptr, code.Idx)
 			b = appendStructKey(ctx, code, b)
-			b = append(b, '"')
-			b = appendUint(ctx, b, ptrToUint64(p+uintptr(code.Offset)), code)
-			b = append(b, '"')
+			b = appendUintString(ctx, b, ptrToUint64(p+uintptr(code.Offset)), code)
 			b = appendStructEnd(ctx, code, b)
 			code = code.Next
 		case encoder.OpStructEndOmitEmptyUintString:
@@ -4457,9 +4644,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			v := u64 & ((1 << code.NumBitSize) - 1)
 			if v != 0 {
 				b = appendStructKey(ctx, code, b)
-				b = append(b, '"')
-				b = appendUint(ctx, b, u64, code)
-				b = append(b, '"')
+				b = appendUintString(ctx, b, u64, code)
 				b = appendStructEnd(ctx, code, b)
 			} else {
 				b = appendStructEndSkipLast(ctx, code, b)
@@ -4494,9 +4679,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			if p == 0 {
 				b = appendNull(ctx, b)
 			} else {
-				b = append(b, '"')
-				b = appendUint(ctx, b, ptrToUint64(p), code)
-				b = append(b, '"')
+				b = appendUintString(ctx, b, ptrToUint64(p), code)
 			}
 			b = appendStructEnd(ctx, code, b)
 			code = code.Next
@@ -4505,9 +4688,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			p = ptrToNPtr(p+uintptr(code.Offset), code.PtrNum)
 			if p != 0 {
 				b = appendStructKey(ctx, code, b)
-				b = append(b, '"')
-				b = appendUint(ctx, b, ptrToUint64(p), code)
-				b = append(b, '"')
+				b = appendUintString(ctx, b, ptrToUint64(p), code)
 				b = appendStructEnd(ctx, code, b)
 			} else {
 				b = appendStructEndSkipLast(ctx, code, b)
@@ -4516,7 +4697,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 		case encoder.OpStructEndFloat32:
 			p := load(ctxptr, code.Idx)
 			b = appendStructKey(ctx, code, b)
-			b = appendFloat32(ctx, b, ptrToFloat32(p+uintptr(code.Offset)))
+			b = appendFloat32(ctx, code, b, ptrToFloat32(p+uintptr(code.Offset)))
 			b = appendStructEnd(ctx, code, b)
 			code = code.Next
 		case encoder.OpStructEndOmitEmptyFloat32:
@@ -4524,7 +4705,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			v := ptrToFloat32(p + uintptr(code.Offset))
 			if v != 0 {
 				b = appendStructKey(ctx, code, b)
-				b = appendFloat32(ctx, b, v)
+				b = appendFloat32(ctx, code, b, v)
 				b = appendStructEnd(ctx, code, b)
 			} else {
 				b = appendStructEndSkipLast(ctx, code, b)
@@ -4534,7 +4715,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			p := load(ctxptr, code.Idx)
 			b = appendStructKey(ctx, code, b)
 			b = append(b, '"')
-			b = appendFloat32(ctx, b, ptrToFloat32(p+uintptr(code.Offset)))
+			b = appendFloat32(ctx, code, b, ptrToFloat32(p+uintptr(code.Offset)))
 			b = append(b, '"')
 			b = appendStructEnd(ctx, code, b)
 			code = code.Next
@@ -4544,7 +4725,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			if v != 0 {
 				b = appendStructKey(ctx, code, b)
 				b = append(b, '"')
-				b = appendFloat32(ctx, b, v)
+				b = appendFloat32(ctx, code, b, v)
 				b = append(b, '"')
 				b = appendStructEnd(ctx, code, b)
 			} else {
@@ -4558,7 +4739,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			if p == 0 {
 				b = appendNull(ctx, b)
 			} else {
-				b = appendFloat32(ctx, b, ptrToFloat32(p))
+				b = appendFloat32(ctx, code, b, ptrToFloat32(p))
 			}
 			b = appendStructEnd(ctx, code, b)
 			code = code.Next
@@ -4567,7 +4748,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			p = ptrToNPtr(p+uintptr(code.Offset), code.PtrNum)
 			if p != 0 {
 				b = appendStructKey(ctx, code, b)
-				b = appendFloat32(ctx, b, ptrToFloat32(p))
+				b = appendFloat32(ctx, code, b, ptrToFloat32(p))
 				b = appendStructEnd(ctx, code, b)
 			} else {
 				b = appendStructEndSkipLast(ctx, code, b)
@@ -4581,7 +4762,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 				b = appendNull(ctx, b)
 			} else {
 				b = append(b, '"')
-				b = appendFloat32(ctx, b, ptrToFloat32(p))
+				b = appendFloat32(ctx, code, b, ptrToFloat32(p))
 				b = append(b, '"')
 			}
 			b = appendStructEnd(ctx, code, b)
@@ -4592,7 +4773,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			if p != 0 {
 				b = appendStructKey(ctx, code, b)
 				b = append(b, '"')
-				b = appendFloat32(ctx, b, ptrToFloat32(p))
+				b = appendFloat32(ctx, code, b, ptrToFloat32(p))
 				b = append(b, '"')
 				b = appendStructEnd(ctx, code, b)
 			} else {
@@ -4606,7 +4787,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 				return nil, errUnsupportedFloat(v)
 			}
 			b = appendStructKey(ctx, code, b)
-			b = appendFloat64(ctx, b, v)
+			b = appendFloat64(ctx, code, b, v)
 			b = appendStructEnd(ctx, code, b)
 			code = code.Next
 		case encoder.OpStructEndOmitEmptyFloat64:
@@ -4617,7 +4798,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 					return nil, errUnsupportedFloat(v)
 				}
 				b = appendStructKey(ctx, code, b)
-				b = appendFloat64(ctx, b, v)
+				b = appendFloat64(ctx, code, b, v)
 				b = appendStructEnd(ctx, code, b)
 			} else {
 				b = appendStructEndSkipLast(ctx, code, b)
@@ -4631,7 +4812,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			}
 			b = appendStructKey(ctx, code, b)
 			b = append(b, '"')
-			b = appendFloat64(ctx, b, v)
+			b = appendFloat64(ctx, code, b, v)
 			b = append(b, '"')
 			b = appendStructEnd(ctx, code, b)
 			code = code.Next
@@ -4644,7 +4825,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 				}
 				b = appendStructKey(ctx, code, b)
 				b = append(b, '"')
-				b = appendFloat64(ctx, b, v)
+				b = appendFloat64(ctx, code, b, v)
 				b = append(b, '"')
 				b = appendStructEnd(ctx, code, b)
 			} else {
@@ -4665,7 +4846,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 			if math.IsInf(v, 0) || math.IsNaN(v) {
 				return nil, errUnsupportedFloat(v)
 			}
-			b = appendFloat64(ctx, b, v)
+			b = appendFloat64(ctx, code, b, v)
 			b = appendStructEnd(ctx, code, b)
 			code = code.Next
 		case encoder.OpStructEndOmitEmptyFloat64Ptr:
@@ -4677,7 +4858,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 				if math.IsInf(v, 0) || math.IsNaN(v) {
 					return nil, errUnsupportedFloat(v)
 				}
-				b = appendFloat64(ctx, b, v)
+				b = appendFloat64(ctx, code, b, v)
 				b = appendStructEnd(ctx, code, b)
 			} else {
 				b = appendStructEndSkipLast(ctx, code, b)
@@ -4695,7 +4876,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 				if math.IsInf(v, 0) || math.IsNaN(v) {
 					return nil, errUnsupportedFloat(v)
 				}
-				b = appendFloat64(ctx, b, v)
+				b = appendFloat64(ctx, code, b, v)
 				b = append(b, '"')
 			}
 			b = appendStructEnd(ctx, code, b)
@@ -4710,7 +4891,7 @@ func Run(ctx *encoder.RuntimeContext, b []byte, codeSet *encoder.OpcodeSet) ([]b
 					return nil, errUnsupportedFloat(v)
 				}
 				b = append(b, '"')
-				b = appendFloat64(ctx, b, v)
+				b = appendFloat64(ctx, code, b, v)
 				b = append(b, '"')
 				b = appendStructEnd(ctx, code, b)
 			} else {
@@ -2,8 +2,8 @@ package vm_indent
 
 import (
 	// HACK: compile order
-	// `vm`, `vm_indent`, `vm_color`, `vm_color_indent` packages uses a lot of memory to compile,
+	// `vm`, `vm_indent`, `vm_color`, `vm_color_indent`, `vm_canonical` packages uses a lot of memory to compile,
 	// so forcibly make dependencies and avoid compiling in concurrent.
-	// dependency order: vm => vm_indent => vm_color => vm_color_indent
+	// dependency order: vm => vm_indent => vm_color => vm_color_indent => vm_canonical
 	_ "github.com/goccy/go-json/internal/encoder/vm_color"
 )
@@ -14,8 +14,10 @@ const uintptrSize = 4 << (^uintptr(0) >> 63)
 var (
 	appendInt           = encoder.AppendInt
 	appendUint          = encoder.AppendUint
-	appendFloat32       = encoder.AppendFloat32
-	appendFloat64       = encoder.AppendFloat64
+	appendIntString     = encoder.AppendIntString
+	appendUintString    = encoder.AppendUintString
+	appendFloat32       = encoder.AppendStyledFloat32
+	appendFloat64       = encoder.AppendStyledFloat64
 	appendString        = encoder.AppendString
 	appendByteSlice     = encoder.AppendByteSlice
 	appendNumber        = encoder.AppendNumber
@@ -0,0 +1,124 @@
+package runtime
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// codeCacheLimit is the maximum number of the entries of every CodeCache. 0 means no limit.
+var codeCacheLimit int64
+
+// SetCodeCacheLimit sets the maximum number of the entries of every CodeCache.
+// The caches that have more entries than n evict the entries on the next stores.
+func SetCodeCacheLimit(n int) {
+	if n < 0 {
+		n = 0
+	}
+	atomic.StoreInt64(&codeCacheLimit, int64(n))
+}
+
+// CompileGroup deduplicates the concurrent compilations of the same key.
+// The zero value is ready to use.
+type CompileGroup struct {
+	mu    sync.Mutex
+	calls map[interface{}]*compileCall
+}
+
+type compileCall struct {
+	wg  sync.WaitGroup
+	val interface{}
+	err error
+}
+
+// Do calls compile and returns the result, but the callers with the same key
+// while the compilation is in progress wait for it and share the result instead.
+// compile must not call Do with the same key.
+func (g *CompileGroup) Do(key interface{}, compile func() (interface{}, error)) (interface{}, error) {
+	g.mu.Lock()
+	if call, exists := g.calls[key]; exists {
+		g.mu.Unlock()
+		call.wg.Wait()
+		return call.val, call.err
+	}
+	if g.calls == nil {
+		g.calls = map[interface{}]*compileCall{}
+	}
+	call := &compileCall{}
+	call.wg.Add(1)
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		call.wg.Done()
+	}()
+	call.val, call.err = compile()
+	return call.val, call.err
+}
+
+// CodeCache caches the compiled codes of the keys, like the types that are out of the range of TypeAddr
+// or the pairs of the types and the options.
+// Unlike the copy-on-write map, storing a code doesn't copy the whole cache, and
+// the concurrent compilations of the same key are deduplicated.
+// The zero value is ready to use.
+type CodeCache struct {
+	m     sync.Map
+	group CompileGroup
+
+	mu    sync.Mutex // guards the stores and count
+	count int64
+}
+
+// Load returns the cached code of key.
+func (c *CodeCache) Load(key interface{}) (interface{}, bool) {
+	return c.m.Load(key)
+}
+
+// LoadOrCompile returns the cached code of key, or compiles and caches it.
+func (c *CodeCache) LoadOrCompile(key interface{}, compile func() (interface{}, error)) (interface{}, error) {
+	if code, exists := c.m.Load(key); exists {
+		return code, nil
+	}
+	return c.group.Do(key, func() (interface{}, error) {
+		if code, exists := c.m.Load(key); exists {
+			// compiled by the other caller between Load and Do.
+			return code, nil
+		}
+		code, err := compile()
+		if err != nil {
+			return nil, err
+		}
+		c.store(key, code)
+		return code, nil
+	})
+}
+
+func (c *CodeCache) store(key, code interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if limit := atomic.LoadInt64(&codeCacheLimit); limit > 0 {
+		for c.count >= limit {
+			c.evict()
+		}
+	}
+	if _, loaded := c.m.LoadOrStore(key, code); !loaded {
+		c.count++
+	}
+}
+
+// evict deletes an arbitrary entry. It is called with mu held.
+func (c *CodeCache) evict() {
+	evicted := false
+	c.m.Range(func(key, _ interface{}) bool {
+		c.m.Delete(key)
+		evicted = true
+		return false
+	})
+	if evicted {
+		c.count--
+	} else {
+		c.count = 0
+	}
+}
@@ -0,0 +1,84 @@
+package runtime
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestCompileGroup(t *testing.T) {
+	var (
+		group    CompileGroup
+		compiled int32
+		started  = make(chan struct{})
+		release  = make(chan struct{})
+		wg       sync.WaitGroup
+	)
+	results := make([]interface{}, 8)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		results[0], _ = group.Do("key", func() (interface{}, error) {
+			close(started)
+			<-release
+			atomic.AddInt32(&compiled, 1)
+			return "code", nil
+		})
+	}()
+	<-started
+	for i := 1; i < len(results); i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = group.Do("key", func() (interface{}, error) {
+				atomic.AddInt32(&compiled, 1)
+				return "code", nil
+			})
+		}(i)
+	}
+	close(release)
+	wg.Wait()
+	for _, result := range results {
+		if result != "code" {
+			t.Fatalf("unexpected result %v", result)
+		}
+	}
+	// the callers that called Do after the first compilation finished compile again.
+	if n := atomic.LoadInt32(&compiled); n < 1 || n > int32(len(results)) {
+		t.Fatalf("unexpected number of compilations %d", n)
+	}
+}
+
+func TestCodeCacheLimit(t *testing.T) {
+	SetCodeCacheLimit(2)
+	defer SetCodeCacheLimit(0)
+
+	var cache CodeCache
+	compiled := 0
+	for _, key := range []int{1, 2, 3, 3, 4} {
+		code, err := cache.LoadOrCompile(key, func() (interface{}, error) {
+			compiled++
+			return key * 10, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != key*10 {
+			t.Fatalf("unexpected code %v for %d", code, key)
+		}
+	}
+	if compiled != 4 {
+		t.Fatalf("expected 4 compilations but got %d", compiled)
+	}
+	entries := 0
+	cache.m.Range(func(_, _ interface{}) bool {
+		entries++
+		return true
+	})
+	if entries != 2 || cache.count != 2 {
+		t.Fatalf("expected 2 entries but got %d ( count %d )", entries, cache.count)
+	}
+	if _, exists := cache.Load(4); !exists {
+		t.Fatal("the last code must be cached")
+	}
+}
@@ -2,6 +2,7 @@ package runtime
 
 import (
 	"reflect"
+	"strconv"
 	"strings"
 	"unicode"
 )
@@ -29,10 +30,21 @@ type StructTag struct {
 	Key         string
 	IsTaggedKey bool
 	IsOmitEmpty bool
+	IsOmitZero  bool
 	IsString    bool
+	IsNoNil     bool
+	IsRedact    bool
+	FloatFmt    byte // 'f' or 'e' specified by fmt=
+	FloatPrec   int  // significant digits specified by precision=
 	Field       reflect.StructField
 }
 
+// NamingPolicy converts the name of a struct field that has no key in its tag into the object key.
+// Compiled codes are cached for every NamingPolicy pointer.
+type NamingPolicy struct {
+	Convert func(string) string
+}
+
 type StructTags []*StructTag
 
 func (t StructTags) ExistsKey(key string) bool {
@@ -44,7 +56,8 @@ func (t StructTags) ExistsKey(key string) bool {
 	return false
 }
 
-func isValidTag(s string) bool {
+// IsValidTag reports whether s can be the key of the json tag.
+func IsValidTag(s string) bool {
 	if s == "" {
 		return false
 	}
@@ -67,7 +80,7 @@ func StructTagFromField(field reflect.StructField) *StructTag {
 	st := &StructTag{Field: field}
 	opts := strings.Split(tag, ",")
 	if len(opts) > 0 {
-		if opts[0] != "" && isValidTag(opts[0]) {
+		if opts[0] != "" && IsValidTag(opts[0]) {
 			keyName = opts[0]
 			st.IsTaggedKey = true
 		}
@@ -78,10 +91,35 @@ func StructTagFromField(field reflect.StructField) *StructTag {
 			switch opt {
 			case "omitempty":
 				st.IsOmitEmpty = true
+			case "omitzero":
+				st.IsOmitZero = true
 			case "string":
 				st.IsString = true
+			case "nonil":
+				st.IsNoNil = true
+			case "redact":
+				st.IsRedact = true
+			default:
+				switch {
+				case strings.HasPrefix(opt, "precision="):
+					if prec, err := strconv.Atoi(opt[len("precision="):]); err == nil && prec > 0 && prec <= 255 {
+						st.FloatPrec = prec
+					}
+				case opt == "fmt=f" || opt == "fmt=e":
+					st.FloatFmt = opt[len(opt)-1]
+				}
 			}
 		}
 	}
 	return st
 }
+
+// StructTagFromFieldWithNamingPolicy is like StructTagFromField,
+// but the key of the untagged field is converted by policy.
+func StructTagFromFieldWithNamingPolicy(field reflect.StructField, policy *NamingPolicy) *StructTag {
+	st := StructTagFromField(field)
+	if policy != nil && !st.IsTaggedKey {
+		st.Key = policy.Convert(field.Name)
+	}
+	return st
+}
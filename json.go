@@ -84,6 +84,31 @@ type UnmarshalerContext interface {
 // false, 0, a nil pointer, a nil interface value, and any empty array,
 // slice, map, or string.
 //
+// The "omitzero" option specifies that the field should be omitted
+// from the encoding if the field has a zero value. If the field type
+// has an "IsZero() bool" method, that will be used to determine whether
+// the value is zero, so a zero time.Time is omitted. Otherwise, the value
+// is zero if it is the zero value of its type, including structs and arrays,
+// and a floating point -0 is zero like 0. The option of an embedded struct
+// whose fields are promoted is ignored, as in encoding/json.
+// If both "omitempty" and "omitzero" are specified, the field is omitted
+// if the value is either empty or zero (or both).
+//
+// The "precision=N" option rounds a floating point field to N significant digits,
+// and the "fmt=f" or "fmt=e" option always formats it in fixed or exponent notation:
+//
+//   Price float64 `json:"price,precision=4,fmt=f"`
+//
+// The options also apply to the floating point elements of a slice or array field
+// and the values of a map field, but not to the fields of the structs in them.
+//
+// The "nonil" option encodes a nil slice field as [] and a nil map field as {}
+// instead of null. See also EmptyCollectionsForNil.
+//
+// The "redact" option marks a field holding a secret, like `json:"password,redact"`.
+// The field is encoded normally unless the Redact options are given to
+// MarshalWithOption, which replace it with a placeholder or omit it.
+//
 // As a special case, if the field tag is "-", the field is always omitted.
 // Note that a field with name "-" can still be generated using the tag "-,".
 //
@@ -334,6 +359,15 @@ func Indent(dst *bytes.Buffer, src []byte, prefix, indent string) error {
 	return encoder.Indent(dst, src, prefix, indent)
 }
 
+// Canonicalize returns the canonical form ( RFC 8785, JSON Canonicalization Scheme ) of the JSON-encoded src.
+// Object members are sorted by the UTF-16 code units of their keys, numbers are formatted
+// like ECMAScript's Number.prototype.toString and strings are minimally escaped.
+// Integer literals up to 21 digits are kept exact, as Marshal with Canonical does for Go integers.
+// Duplicate object keys are reported as an error.
+func Canonicalize(src []byte) ([]byte, error) {
+	return encoder.Canonicalize(nil, src)
+}
+
 // HTMLEscape appends to dst the JSON-encoded src with <, >, &, U+2028 and U+2029
 // characters inside string literals changed to \u003c, \u003e, \u0026, \u2028, \u2029
 // so that the JSON will be safe to embed inside HTML <script> tags.
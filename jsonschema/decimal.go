@@ -0,0 +1,227 @@
+package jsonschema
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// decimal is the exact value of a number literal, ±0.digits × 10^exp.
+// The exponent is kept as a decimal integer string, so that the numbers like 1e1000000000000
+// are compared by their digits and exponents without expanding them.
+type decimal struct {
+	neg    bool
+	digits string // the significant digits without the leading and trailing zeros, empty for zero
+	exp    string // the signed exponent without the leading zeros
+}
+
+// parseDecimal converts the literal that is checked by parseNumber. It takes the time linear to the literal.
+func parseDecimal(literal string) decimal {
+	var d decimal
+	s := literal
+	if s[0] == '-' {
+		d.neg = true
+		s = s[1:]
+	}
+	mantissa, exp := s, "0"
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		mantissa, exp = s[:i], s[i+1:]
+	}
+	intPart, frac := mantissa, ""
+	if i := strings.IndexByte(mantissa, '.'); i >= 0 {
+		intPart, frac = mantissa[:i], mantissa[i+1:]
+	}
+	digits := intPart + frac
+	trimmed := strings.TrimLeft(digits, "0")
+	d.digits = strings.TrimRight(trimmed, "0")
+	if d.digits == "" {
+		return decimal{exp: "0"}
+	}
+	// 0.digits has the point before the first significant digit.
+	point := len(intPart) - (len(digits) - len(trimmed))
+	expNeg := strings.HasPrefix(exp, "-")
+	d.exp = addInt(withSign(expNeg, trimInt(strings.TrimLeft(exp, "+-"))), strconv.Itoa(point))
+	return d
+}
+
+func (d decimal) sign() int {
+	switch {
+	case d.digits == "":
+		return 0
+	case d.neg:
+		return -1
+	}
+	return 1
+}
+
+// cmp compares the values of d and x, and returns -1, 0 or +1.
+func (d decimal) cmp(x decimal) int {
+	ds, xs := d.sign(), x.sign()
+	if ds != xs {
+		if ds < xs {
+			return -1
+		}
+		return 1
+	}
+	if ds == 0 {
+		return 0
+	}
+	c := compareInt(d.exp, x.exp)
+	if c == 0 {
+		c = strings.Compare(d.digits, x.digits)
+	}
+	return c * ds
+}
+
+// isInteger reports whether d has no fraction.
+func (d decimal) isInteger() bool {
+	return d.digits == "" || compareInt(d.exp, strconv.Itoa(len(d.digits))) >= 0
+}
+
+// int64 returns the value of d if it's an integer in the range of int64.
+func (d decimal) int64() (int64, bool) {
+	if !d.isInteger() || compareInt(d.exp, "19") > 0 {
+		return 0, false
+	}
+	if d.digits == "" {
+		return 0, true
+	}
+	exp, _ := strconv.Atoi(d.exp)
+	n, err := strconv.ParseInt(withSign(d.neg, d.digits+strings.Repeat("0", exp-len(d.digits))), 10, 64)
+	return n, err == nil
+}
+
+// isMultipleOf reports whether d is an integer multiple of m that is greater than 0.
+func (d decimal) isMultipleOf(m decimal) bool {
+	if d.digits == "" {
+		return true
+	}
+	// d/m is D/M × 10^shift for the integers D and M of the digits.
+	// D/M × 10^shift is less than 1 if the shift is -len(D) or less, and 10^shift has only the factors 2 and 5,
+	// so the shift more than 4 × len(M), which is greater than the number of the factors 2 of M, doesn't change the result.
+	shift := addInt(addInt(d.exp, negInt(m.exp)), strconv.Itoa(len(m.digits)-len(d.digits)))
+	if compareInt(shift, strconv.Itoa(-len(d.digits))) <= 0 {
+		return false
+	}
+	n := 4 * len(m.digits)
+	if compareInt(shift, strconv.Itoa(n)) < 0 {
+		n, _ = strconv.Atoi(shift)
+	}
+	num, _ := new(big.Int).SetString(d.digits, 10)
+	den, _ := new(big.Int).SetString(m.digits, 10)
+	pow := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(n))), nil)
+	if n >= 0 {
+		num.Mul(num, pow)
+	} else {
+		den.Mul(den, pow)
+	}
+	return new(big.Int).Rem(num, den).Sign() == 0
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// compareInt compares the signed decimal integers without the leading zeros.
+func compareInt(a, b string) int {
+	aNeg, bNeg := strings.HasPrefix(a, "-"), strings.HasPrefix(b, "-")
+	if aNeg != bNeg {
+		if aNeg {
+			return -1
+		}
+		return 1
+	}
+	c := compareMagnitude(strings.TrimPrefix(a, "-"), strings.TrimPrefix(b, "-"))
+	if aNeg {
+		return -c
+	}
+	return c
+}
+
+func compareMagnitude(a, b string) int {
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// addInt returns a+b of the signed decimal integers in the time linear to their lengths.
+func addInt(a, b string) string {
+	aNeg, bNeg := strings.HasPrefix(a, "-"), strings.HasPrefix(b, "-")
+	am, bm := strings.TrimPrefix(a, "-"), strings.TrimPrefix(b, "-")
+	if aNeg == bNeg {
+		return withSign(aNeg, addMagnitudes(am, bm))
+	}
+	switch compareMagnitude(am, bm) {
+	case 0:
+		return "0"
+	case 1:
+		return withSign(aNeg, subMagnitudes(am, bm))
+	}
+	return withSign(bNeg, subMagnitudes(bm, am))
+}
+
+func negInt(a string) string {
+	if strings.HasPrefix(a, "-") {
+		return a[1:]
+	}
+	return withSign(true, a)
+}
+
+func addMagnitudes(a, b string) string {
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	buf := make([]byte, len(a)+1)
+	var carry byte
+	for i := 0; i < len(a); i++ {
+		c := a[len(a)-1-i] - '0' + carry
+		if i < len(b) {
+			c += b[len(b)-1-i] - '0'
+		}
+		carry = c / 10
+		buf[len(buf)-1-i] = c%10 + '0'
+	}
+	buf[0] = carry + '0'
+	return trimInt(string(buf))
+}
+
+// subMagnitudes returns a-b for a >= b.
+func subMagnitudes(a, b string) string {
+	buf := make([]byte, len(a))
+	var borrow byte
+	for i := 0; i < len(a); i++ {
+		c := int(a[len(a)-1-i]-'0') - int(borrow)
+		if i < len(b) {
+			c -= int(b[len(b)-1-i] - '0')
+		}
+		borrow = 0
+		if c < 0 {
+			c += 10
+			borrow = 1
+		}
+		buf[len(a)-1-i] = byte(c) + '0'
+	}
+	return trimInt(string(buf))
+}
+
+func trimInt(s string) string {
+	s = strings.TrimLeft(s, "0")
+	if s == "" {
+		return "0"
+	}
+	return s
+}
+
+func withSign(neg bool, magnitude string) string {
+	if neg && magnitude != "0" {
+		return "-" + magnitude
+	}
+	return magnitude
+}
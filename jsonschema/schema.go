@@ -0,0 +1,403 @@
+// Package jsonschema validates JSON documents by JSON Schema ( draft 2020-12 ).
+//
+// The numbers of the documents are checked by the grammar of JSON and compared by their exact values,
+// so the numbers out of the range of float64, like 1e400, are valid. The exponents are compared
+// without expanding the numbers, so the numbers like 1e1000000000000 take the time linear to their literals.
+// The strings are read by the decoder of go-json, and the last member of the duplicate keys wins like Unmarshal.
+//
+// The schemas that apply themselves to the same value through $ref, allOf, anyOf, oneOf and not,
+// like {"$ref": "#"}, are rejected by Compile because their validation never ends.
+//
+// The supported keywords are type, enum, const, properties, patternProperties,
+// additionalProperties, required, minProperties, maxProperties, items, prefixItems,
+// minItems, maxItems, uniqueItems, minimum, maximum, exclusiveMinimum, exclusiveMaximum,
+// multipleOf, minLength, maxLength, pattern, allOf, anyOf, oneOf, not, $defs and $ref
+// to the JSON Pointer within the document. The other keywords are ignored.
+// The patterns are the regular expressions of the regexp package.
+package jsonschema
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Schema is a compiled JSON Schema. It is safe for concurrent use.
+type Schema struct {
+	root *schema
+}
+
+// schema is a compiled schema object or boolean schema.
+type schema struct {
+	pointer string
+	always  *bool
+
+	ref *schema
+
+	types                []valueKind
+	integer              bool // "integer" is in the types
+	enum                 []*value
+	constValue           *value
+	properties           []property
+	patternProperties    []patternProperty
+	additionalProperties *schema
+	required             []string
+	minProperties        int
+	maxProperties        int
+	items                *schema
+	prefixItems          []*schema
+	minItems             int
+	maxItems             int
+	uniqueItems          bool
+	minimum              *limit
+	maximum              *limit
+	exclusiveMinimum     *limit
+	exclusiveMaximum     *limit
+	multipleOf           *limit
+	minLength            int
+	maxLength            int
+	pattern              *regexp.Regexp
+	allOf                []*schema
+	anyOf                []*schema
+	oneOf                []*schema
+	not                  *schema
+}
+
+type property struct {
+	name   string
+	schema *schema
+}
+
+type patternProperty struct {
+	pattern *regexp.Regexp
+	schema  *schema
+}
+
+// Compile compiles the JSON Schema document.
+func Compile(data []byte) (*Schema, error) {
+	doc, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("jsonschema: failed to parse the schema: %v", err)
+	}
+	c := &compiler{doc: doc, compiled: map[*value]*schema{}}
+	root, err := c.compile(doc, "")
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkCycles(); err != nil {
+		return nil, err
+	}
+	return &Schema{root: root}, nil
+}
+
+// MustCompile is like Compile but panics if the schema can't be compiled.
+func MustCompile(data []byte) *Schema {
+	s, err := Compile(data)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+type compiler struct {
+	doc      *value
+	compiled map[*value]*schema
+}
+
+func invalidKeyword(pointer, keyword, reason string) error {
+	return fmt.Errorf("jsonschema: invalid %q keyword at %q: %s", keyword, pointer, reason)
+}
+
+func (c *compiler) compile(v *value, pointer string) (*schema, error) {
+	if s, exists := c.compiled[v]; exists {
+		return s, nil
+	}
+	s := &schema{pointer: pointer, minProperties: -1, maxProperties: -1, minItems: -1, maxItems: -1, minLength: -1, maxLength: -1}
+	c.compiled[v] = s
+	switch v.kind {
+	case boolKind:
+		always := v.boolean
+		s.always = &always
+		return s, nil
+	case objectKind:
+	default:
+		return nil, fmt.Errorf("jsonschema: schema at %q must be an object or a boolean", pointer)
+	}
+	for _, keyword := range v.keys() {
+		kv, _ := v.get(keyword)
+		if err := c.compileKeyword(s, keyword, kv, pointer+"/"+pointerToken(keyword)); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (c *compiler) compileKeyword(s *schema, keyword string, v *value, pointer string) error {
+	var err error
+	switch keyword {
+	case "$ref":
+		if v.kind != stringKind {
+			return invalidKeyword(pointer, keyword, "must be a string")
+		}
+		s.ref, err = c.resolve(v.str, pointer)
+	case "type":
+		err = c.compileType(s, v, pointer)
+	case "enum":
+		if v.kind != arrayKind {
+			return invalidKeyword(pointer, keyword, "must be an array")
+		}
+		s.enum = v.elems
+	case "const":
+		s.constValue = v
+	case "properties":
+		if v.kind != objectKind {
+			return invalidKeyword(pointer, keyword, "must be an object")
+		}
+		for _, name := range v.keys() {
+			pv, _ := v.get(name)
+			ps, err := c.compile(pv, pointer+"/"+pointerToken(name))
+			if err != nil {
+				return err
+			}
+			s.properties = append(s.properties, property{name: name, schema: ps})
+		}
+	case "patternProperties":
+		if v.kind != objectKind {
+			return invalidKeyword(pointer, keyword, "must be an object")
+		}
+		for _, expr := range v.keys() {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return invalidKeyword(pointer, keyword, err.Error())
+			}
+			pv, _ := v.get(expr)
+			ps, err := c.compile(pv, pointer+"/"+pointerToken(expr))
+			if err != nil {
+				return err
+			}
+			s.patternProperties = append(s.patternProperties, patternProperty{pattern: re, schema: ps})
+		}
+	case "additionalProperties":
+		s.additionalProperties, err = c.compile(v, pointer)
+	case "required":
+		if v.kind != arrayKind {
+			return invalidKeyword(pointer, keyword, "must be an array of strings")
+		}
+		for _, elem := range v.elems {
+			if elem.kind != stringKind {
+				return invalidKeyword(pointer, keyword, "must be an array of strings")
+			}
+			s.required = append(s.required, elem.str)
+		}
+	case "minProperties":
+		s.minProperties, err = nonNegativeInt(v, pointer, keyword)
+	case "maxProperties":
+		s.maxProperties, err = nonNegativeInt(v, pointer, keyword)
+	case "items":
+		s.items, err = c.compile(v, pointer)
+	case "prefixItems":
+		s.prefixItems, err = c.compileArray(v, pointer, keyword)
+	case "minItems":
+		s.minItems, err = nonNegativeInt(v, pointer, keyword)
+	case "maxItems":
+		s.maxItems, err = nonNegativeInt(v, pointer, keyword)
+	case "uniqueItems":
+		if v.kind != boolKind {
+			return invalidKeyword(pointer, keyword, "must be a boolean")
+		}
+		s.uniqueItems = v.boolean
+	case "minimum":
+		s.minimum, err = number(v, pointer, keyword)
+	case "maximum":
+		s.maximum, err = number(v, pointer, keyword)
+	case "exclusiveMinimum":
+		s.exclusiveMinimum, err = number(v, pointer, keyword)
+	case "exclusiveMaximum":
+		s.exclusiveMaximum, err = number(v, pointer, keyword)
+	case "multipleOf":
+		s.multipleOf, err = number(v, pointer, keyword)
+		if err == nil && s.multipleOf.num.sign() <= 0 {
+			return invalidKeyword(pointer, keyword, "must be greater than 0")
+		}
+	case "minLength":
+		s.minLength, err = nonNegativeInt(v, pointer, keyword)
+	case "maxLength":
+		s.maxLength, err = nonNegativeInt(v, pointer, keyword)
+	case "pattern":
+		if v.kind != stringKind {
+			return invalidKeyword(pointer, keyword, "must be a string")
+		}
+		s.pattern, err = regexp.Compile(v.str)
+		if err != nil {
+			return invalidKeyword(pointer, keyword, err.Error())
+		}
+	case "allOf":
+		s.allOf, err = c.compileArray(v, pointer, keyword)
+	case "anyOf":
+		s.anyOf, err = c.compileArray(v, pointer, keyword)
+	case "oneOf":
+		s.oneOf, err = c.compileArray(v, pointer, keyword)
+	case "not":
+		s.not, err = c.compile(v, pointer)
+	case "$defs", "definitions":
+		// the definitions are compiled when they are referred.
+	}
+	return err
+}
+
+func (c *compiler) compileType(s *schema, v *value, pointer string) error {
+	names := []*value{v}
+	if v.kind == arrayKind {
+		names = v.elems
+	}
+	for _, name := range names {
+		if name.kind != stringKind {
+			return invalidKeyword(pointer, "type", "must be a string or an array of strings")
+		}
+		switch name.str {
+		case "integer":
+			s.integer = true
+			continue
+		case "null", "boolean", "number", "string", "array", "object":
+		default:
+			return invalidKeyword(pointer, "type", fmt.Sprintf("unknown type %q", name.str))
+		}
+		for kind, kindName := range kindNames {
+			if kindName == name.str {
+				s.types = append(s.types, valueKind(kind))
+			}
+		}
+	}
+	return nil
+}
+
+func (c *compiler) compileArray(v *value, pointer, keyword string) ([]*schema, error) {
+	if v.kind != arrayKind || len(v.elems) == 0 {
+		return nil, invalidKeyword(pointer, keyword, "must be a non-empty array")
+	}
+	schemas := make([]*schema, 0, len(v.elems))
+	for i, elem := range v.elems {
+		s, err := c.compile(elem, pointer+"/"+indexToken(i))
+		if err != nil {
+			return nil, err
+		}
+		schemas = append(schemas, s)
+	}
+	return schemas, nil
+}
+
+// resolve compiles the schema referred by the JSON Pointer in the fragment of ref.
+func (c *compiler) resolve(ref, pointer string) (*schema, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, invalidKeyword(pointer, "$ref", fmt.Sprintf("%q isn't a reference within the document", ref))
+	}
+	target := c.doc
+	fragment := ref[1:]
+	if fragment != "" {
+		if fragment[0] != '/' {
+			return nil, invalidKeyword(pointer, "$ref", fmt.Sprintf("%q isn't a JSON Pointer", ref))
+		}
+		unescape := strings.NewReplacer("~1", "/", "~0", "~")
+		for _, token := range strings.Split(fragment[1:], "/") {
+			token = unescape.Replace(token)
+			var next *value
+			switch target.kind {
+			case objectKind:
+				next, _ = target.get(token)
+			case arrayKind:
+				if i, err := strconv.Atoi(token); err == nil && i >= 0 && i < len(target.elems) {
+					next = target.elems[i]
+				}
+			}
+			if next == nil {
+				return nil, invalidKeyword(pointer, "$ref", fmt.Sprintf("%q is not found", ref))
+			}
+			target = next
+		}
+	}
+	return c.compile(target, fragment)
+}
+
+// checkCycles reports the schemas that apply themselves to the same value, like {"$ref": "#"},
+// through $ref, allOf, anyOf, oneOf and not, because their validation never ends.
+// The references through the other keywords are fine, because they validate the members or the elements.
+func (c *compiler) checkCycles() error {
+	schemas := make([]*schema, 0, len(c.compiled))
+	for _, s := range c.compiled {
+		schemas = append(schemas, s)
+	}
+	// start from the schemas in the order of the pointers, so that the same cycle is reported every time.
+	sort.Slice(schemas, func(i, j int) bool {
+		return schemas[i].pointer < schemas[j].pointer
+	})
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := make(map[*schema]int, len(schemas))
+	var visit func(s *schema) error
+	visit = func(s *schema) error {
+		switch state[s] {
+		case visiting:
+			return fmt.Errorf("jsonschema: schema at %q refers to itself without validating a member or an element", s.pointer)
+		case visited:
+			return nil
+		}
+		state[s] = visiting
+		for _, sub := range s.inPlaceSchemas() {
+			if err := visit(sub); err != nil {
+				return err
+			}
+		}
+		state[s] = visited
+		return nil
+	}
+	for _, s := range schemas {
+		if err := visit(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// inPlaceSchemas returns the subschemas that validate the same value as s.
+func (s *schema) inPlaceSchemas() []*schema {
+	var subs []*schema
+	if s.ref != nil {
+		subs = append(subs, s.ref)
+	}
+	subs = append(subs, s.allOf...)
+	subs = append(subs, s.anyOf...)
+	subs = append(subs, s.oneOf...)
+	if s.not != nil {
+		subs = append(subs, s.not)
+	}
+	return subs
+}
+
+func nonNegativeInt(v *value, pointer, keyword string) (int, error) {
+	var n int64
+	ok := v.kind == numberKind
+	if ok {
+		n, ok = v.decimal().int64()
+	}
+	if !ok || n < 0 || int64(int(n)) != n {
+		return 0, invalidKeyword(pointer, keyword, "must be a non-negative integer")
+	}
+	return int(n), nil
+}
+
+// limit is the number of minimum, maximum, exclusiveMinimum, exclusiveMaximum and multipleOf.
+type limit struct {
+	str string // the literal for the messages
+	num decimal
+}
+
+func number(v *value, pointer, keyword string) (*limit, error) {
+	if v.kind != numberKind {
+		return nil, invalidKeyword(pointer, keyword, "must be a number")
+	}
+	return &limit{str: v.str, num: v.decimal()}, nil
+}
@@ -0,0 +1,251 @@
+package jsonschema_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/goccy/go-json/jsonschema"
+)
+
+const testSchema = `{
+  "$defs": {
+    "tag": {"type": "string", "minLength": 1, "maxLength": 3, "pattern": "^[a-z]+$"}
+  },
+  "type": "object",
+  "properties": {
+    "id": {"type": "integer", "minimum": 1},
+    "price": {"type": "number", "exclusiveMinimum": 0, "multipleOf": 0.01},
+    "tags": {"type": "array", "items": {"$ref": "#/$defs/tag"}, "uniqueItems": true, "maxItems": 3},
+    "kind": {"enum": ["a", "b", {"c": [1.0]}]},
+    "version": {"const": 2},
+    "point": {"type": "array", "prefixItems": [{"type": "number"}, {"type": "number"}], "items": false},
+    "owner": {"oneOf": [{"type": "null"}, {"type": "string"}, {"type": "object", "required": ["name"]}]},
+    "note": {"not": {"type": "null"}}
+  },
+  "patternProperties": {"^x-": {"type": "boolean"}},
+  "additionalProperties": false,
+  "required": ["id", "kind"]
+}`
+
+func TestValidate(t *testing.T) {
+	schema, err := jsonschema.Compile([]byte(testSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		doc        string
+		violations []jsonschema.Violation
+	}{
+		{
+			name: "valid",
+			doc:  `{"id": 1e2, "price": 1.25, "tags": ["ab", "c"], "kind": {"c": [1]}, "version": 2.0, "point": [1, 2], "owner": {"name": "x"}, "x-flag": true}`,
+		},
+		{
+			name: "duplicate keys are decided by the last member",
+			doc:  `{"id": 0, "kind": "a", "id": 1}`,
+		},
+		{
+			name: "violations",
+			doc:  `{"id": 1.5, "price": 0.001, "tags": ["ab", "ab", "ABCD"], "version": 3, "point": [1, 2, 3], "owner": 1, "note": null, "x-flag": 1, "extra": 1}`,
+			violations: []jsonschema.Violation{
+				{Pointer: "", Offset: 0, SchemaPointer: "/required", Keyword: "required"},
+				{Pointer: "/id", Offset: 7, SchemaPointer: "/properties/id/type", Keyword: "type"},
+				{Pointer: "/price", Offset: 21, SchemaPointer: "/properties/price/multipleOf", Keyword: "multipleOf"},
+				{Pointer: "/tags", Offset: 36, SchemaPointer: "/properties/tags/uniqueItems", Keyword: "uniqueItems"},
+				{Pointer: "/tags/2", Offset: 49, SchemaPointer: "/$defs/tag/maxLength", Keyword: "maxLength"},
+				{Pointer: "/tags/2", Offset: 49, SchemaPointer: "/$defs/tag/pattern", Keyword: "pattern"},
+				{Pointer: "/version", Offset: 69, SchemaPointer: "/properties/version/const", Keyword: "const"},
+				{Pointer: "/point/2", Offset: 88, SchemaPointer: "/properties/point/items", Keyword: "items"},
+				{Pointer: "/owner", Offset: 101, SchemaPointer: "/properties/owner/oneOf", Keyword: "oneOf"},
+				{Pointer: "/note", Offset: 112, SchemaPointer: "/properties/note/not", Keyword: "not"},
+				{Pointer: "/x-flag", Offset: 128, SchemaPointer: "/patternProperties/^x-/type", Keyword: "type"},
+				{Pointer: "/extra", Offset: 140, SchemaPointer: "/additionalProperties", Keyword: "additionalProperties"},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := schema.Validate([]byte(test.doc))
+			if test.violations == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			validationErr, ok := err.(*jsonschema.ValidationError)
+			if !ok {
+				t.Fatalf("expected ValidationError but got %v", err)
+			}
+			var got []jsonschema.Violation
+			for _, v := range validationErr.Violations {
+				if v.Message == "" {
+					t.Fatalf("violation without message: %+v", v)
+				}
+				v.Message = ""
+				got = append(got, v)
+			}
+			if !reflect.DeepEqual(got, test.violations) {
+				t.Fatalf("unexpected violations:\n%v\nexpected:\n%v", got, test.violations)
+			}
+		})
+	}
+}
+
+func TestValidateSyntaxError(t *testing.T) {
+	schema := jsonschema.MustCompile([]byte(`true`))
+	for _, doc := range []string{`[1 2]`, `[1,]`, `{"a":1,}`, `1 2`, `"\x"`, `01`, `1.`, `-`, `.5`, `1e+`, `[1`, `{"a"}`, `tru`, `"a`} {
+		err := schema.Validate([]byte(doc))
+		if _, ok := err.(*json.SyntaxError); !ok {
+			t.Fatalf("expected SyntaxError for %s but got %v", doc, err)
+		}
+	}
+	if err := jsonschema.MustCompile([]byte(`false`)).Validate([]byte(`null`)); err == nil {
+		t.Fatal("expected error by false schema")
+	}
+}
+
+func TestCompileError(t *testing.T) {
+	for _, schema := range []string{
+		`1`,
+		`{"type": "int"}`,
+		`{"minLength": -1}`,
+		`{"pattern": "("}`,
+		`{"$ref": "#/$defs/unknown"}`,
+		`{"$ref": "other.json"}`,
+		`{"anyOf": []}`,
+		`{"properties": {"a": 1}}`,
+		`{"$ref": "#"}`,
+		`{"$defs": {"a": {"$ref": "#/$defs/b"}, "b": {"$ref": "#/$defs/a"}}, "$ref": "#/$defs/a"}`,
+		`{"$defs": {"a": {"not": {"allOf": [{"$ref": "#/$defs/a"}]}}}, "properties": {"a": {"$ref": "#/$defs/a"}}}`,
+	} {
+		if _, err := jsonschema.Compile([]byte(schema)); err == nil {
+			t.Fatalf("expected error for %s", schema)
+		}
+	}
+}
+
+func TestValidateLargeNumbers(t *testing.T) {
+	schema := jsonschema.MustCompile([]byte(`{"items": {"minimum": 1e100000}}`))
+	if err := schema.Validate([]byte(`[1e100000, 2e100000]`)); err != nil {
+		t.Fatal(err)
+	}
+	err := schema.Validate([]byte(`[1e100000, 1e99999, -1e100000]`))
+	validationErr, ok := err.(*jsonschema.ValidationError)
+	if !ok || len(validationErr.Violations) != 2 {
+		t.Fatalf("expected 2 violations but got %v", err)
+	}
+
+	// the exponents are compared without expanding the numbers.
+	for _, test := range []struct {
+		schema string
+		valid  []string
+		errors []string
+	}{
+		{
+			schema: `{"maximum": 1e99999999999}`,
+			valid:  []string{`1e99999999999`, `10e99999999998`, `0.1e100000000000`, `-1e100000000000`, `1e-99999999999`},
+			errors: []string{`2e99999999999`, `1.00000000001e99999999999`, `1e100000000000`},
+		},
+		{
+			schema: `{"exclusiveMinimum": -1e-99999999999999999999}`,
+			valid:  []string{`0`, `-0.0`, `-1e-100000000000000000000`},
+			errors: []string{`-1e-99999999999999999999`, `-1e-99999999999999999998`, `-1`},
+		},
+		{
+			schema: `{"enum": [1e99999999999, 0.5e-1]}`,
+			valid:  []string{`10e99999999998`, `1E+99999999999`, `5e-2`, `0.050`},
+			errors: []string{`1e99999999998`, `5e-1`},
+		},
+		{
+			schema: `{"multipleOf": 0.01}`,
+			valid:  []string{`1e999999`, `1e99999999999`, `0`, `-1.5`},
+			errors: []string{`1e-999999`, `0.001`},
+		},
+		{
+			schema: `{"multipleOf": 3}`,
+			valid:  []string{`3e99999999999`, `9`},
+			errors: []string{`1e99999999999`, `1e-99999999999`},
+		},
+		{
+			schema: `{"multipleOf": 1e-99999999999}`,
+			valid:  []string{`1`, `2e-99999999999`, `1e99999999999`},
+			errors: []string{`1e-100000000000`},
+		},
+		{
+			schema: `{"type": "integer"}`,
+			valid:  []string{`1e99999999999`, `1.5e1`},
+			errors: []string{`1e-99999999999`, `1.55e1`},
+		},
+	} {
+		schema := jsonschema.MustCompile([]byte(test.schema))
+		for _, doc := range test.valid {
+			if err := schema.Validate([]byte(doc)); err != nil {
+				t.Fatalf("%s: unexpected error for %s: %v", test.schema, doc, err)
+			}
+		}
+		for _, doc := range test.errors {
+			if err := schema.Validate([]byte(doc)); err == nil {
+				t.Fatalf("%s: expected error for %s", test.schema, doc)
+			}
+		}
+	}
+	if _, err := jsonschema.Compile([]byte(`{"minItems": 1e99999999999}`)); err == nil {
+		t.Fatal("expected error for minItems out of the range")
+	}
+
+	// the numbers with the large exponents don't take the time to be expanded.
+	doc := "[" + strings.TrimSuffix(strings.Repeat("1e999999,", 1000), ",") + "]"
+	schema = jsonschema.MustCompile([]byte(`{"items": {"minimum": 0, "multipleOf": 0.5, "enum": [1e999999]}}`))
+	if err := schema.Validate([]byte(doc)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestValidateRecursiveSchema(t *testing.T) {
+	schema := jsonschema.MustCompile([]byte(`{"anyOf": [{"type": "integer"}, {"type": "array", "items": {"$ref": "#"}}]}`))
+	if err := schema.Validate([]byte(`[1, [2, [3]]]`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := schema.Validate([]byte(`[1, [2, ["3"]]]`)); err == nil {
+		t.Fatal("expected error for the nested string")
+	}
+}
+
+func TestValidateGeneratedSchema(t *testing.T) {
+	type Item struct {
+		Name  string   `json:"name"`
+		Count int8     `json:"count,omitempty"`
+		Tags  []string `json:"tags,nonil"`
+		Next  *Item    `json:"next"`
+	}
+	generated, err := json.Schema(reflect.TypeOf(Item{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema, err := jsonschema.Compile(generated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, err := json.Marshal(Item{Name: "a", Next: &Item{Name: "b", Count: 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := schema.Validate(encoded); err != nil {
+		t.Fatal(err)
+	}
+	err = schema.Validate([]byte(`{"name":"a","count":300,"tags":null,"next":{"name":1,"tags":[]}}`))
+	validationErr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		t.Fatalf("expected ValidationError but got %v", err)
+	}
+	var pointers []string
+	for _, v := range validationErr.Violations {
+		pointers = append(pointers, v.Pointer)
+	}
+	if !reflect.DeepEqual(pointers, []string{"/count", "/tags", "/next"}) {
+		t.Fatalf("unexpected violations: %v", validationErr)
+	}
+}
@@ -0,0 +1,274 @@
+package jsonschema
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Violation is a value of the document that doesn't satisfy a keyword of the schema.
+type Violation struct {
+	// Pointer is the JSON Pointer to the value in the document.
+	Pointer string
+	// Offset is the byte offset of the value in the document.
+	Offset int64
+	// SchemaPointer is the JSON Pointer to the keyword in the schema.
+	SchemaPointer string
+	// Keyword is the keyword of the schema, like "required".
+	Keyword string
+	Message string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%q (offset %d): %s", v.Pointer, v.Offset, v.Message)
+}
+
+// ValidationError reports all the violations of the document.
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		msgs = append(msgs, v.String())
+	}
+	return "jsonschema: " + strings.Join(msgs, "; ")
+}
+
+// Validate validates the JSON document.
+// It returns the syntax error of go-json if the document is invalid JSON,
+// or *ValidationError that has all the violations of the schema.
+func (s *Schema) Validate(data []byte) error {
+	doc, err := parse(data)
+	if err != nil {
+		return err
+	}
+	var violations []Violation
+	s.root.validate(doc, "", &violations)
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+func (s *schema) violate(out *[]Violation, v *value, pointer, keyword, format string, args ...interface{}) {
+	schemaPointer := s.pointer
+	if keyword != "" {
+		schemaPointer += "/" + keyword
+	}
+	*out = append(*out, Violation{
+		Pointer:       pointer,
+		Offset:        v.offset,
+		SchemaPointer: schemaPointer,
+		Keyword:       keyword,
+		Message:       fmt.Sprintf(format, args...),
+	})
+}
+
+// valid reports whether v satisfies s, without collecting the violations.
+func (s *schema) valid(v *value, pointer string) bool {
+	var violations []Violation
+	s.validate(v, pointer, &violations)
+	return len(violations) == 0
+}
+
+func (s *schema) validate(v *value, pointer string, out *[]Violation) {
+	if s.always != nil {
+		if !*s.always {
+			s.violate(out, v, pointer, "", "no value is allowed")
+		}
+		return
+	}
+	if s.ref != nil {
+		s.ref.validate(v, pointer, out)
+	}
+	if (len(s.types) > 0 || s.integer) && !s.matchType(v) {
+		s.violate(out, v, pointer, "type", "expected %s but got %s", s.typeNames(), v.kind)
+		return
+	}
+	if s.enum != nil {
+		found := false
+		for _, e := range s.enum {
+			if equal(e, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			s.violate(out, v, pointer, "enum", "value is not one of the enum")
+		}
+	}
+	if s.constValue != nil && !equal(s.constValue, v) {
+		s.violate(out, v, pointer, "const", "value is not the const")
+	}
+	switch v.kind {
+	case objectKind:
+		s.validateObject(v, pointer, out)
+	case arrayKind:
+		s.validateArray(v, pointer, out)
+	case numberKind:
+		s.validateNumber(v, pointer, out)
+	case stringKind:
+		s.validateString(v, pointer, out)
+	}
+	for _, sub := range s.allOf {
+		sub.validate(v, pointer, out)
+	}
+	if s.anyOf != nil {
+		matched := false
+		for _, sub := range s.anyOf {
+			if sub.valid(v, pointer) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			s.violate(out, v, pointer, "anyOf", "value doesn't match any of the schemas")
+		}
+	}
+	if s.oneOf != nil {
+		matched := 0
+		for _, sub := range s.oneOf {
+			if sub.valid(v, pointer) {
+				matched++
+			}
+		}
+		if matched != 1 {
+			s.violate(out, v, pointer, "oneOf", "value matches %d of the schemas instead of exactly one", matched)
+		}
+	}
+	if s.not != nil && s.not.valid(v, pointer) {
+		s.violate(out, v, pointer, "not", "value must not match the schema")
+	}
+}
+
+func (s *schema) matchType(v *value) bool {
+	for _, kind := range s.types {
+		if kind == v.kind {
+			return true
+		}
+	}
+	return s.integer && v.isInteger()
+}
+
+func (s *schema) typeNames() string {
+	names := make([]string, 0, len(s.types)+1)
+	for _, kind := range s.types {
+		names = append(names, kind.String())
+	}
+	if s.integer {
+		names = append(names, "integer")
+	}
+	return strings.Join(names, " or ")
+}
+
+func (s *schema) validateObject(v *value, pointer string, out *[]Violation) {
+	keys := v.keys()
+	if s.minProperties >= 0 && len(keys) < s.minProperties {
+		s.violate(out, v, pointer, "minProperties", "object has %d properties, less than %d", len(keys), s.minProperties)
+	}
+	if s.maxProperties >= 0 && len(keys) > s.maxProperties {
+		s.violate(out, v, pointer, "maxProperties", "object has %d properties, more than %d", len(keys), s.maxProperties)
+	}
+	for _, name := range s.required {
+		if _, exists := v.get(name); !exists {
+			s.violate(out, v, pointer, "required", "property %q is required", name)
+		}
+	}
+	for _, key := range keys {
+		value, _ := v.get(key)
+		valuePointer := pointer + "/" + pointerToken(key)
+		evaluated := false
+		for _, p := range s.properties {
+			if p.name == key {
+				p.schema.validate(value, valuePointer, out)
+				evaluated = true
+			}
+		}
+		for _, p := range s.patternProperties {
+			if p.pattern.MatchString(key) {
+				p.schema.validate(value, valuePointer, out)
+				evaluated = true
+			}
+		}
+		if !evaluated && s.additionalProperties != nil {
+			if s.additionalProperties.always != nil && !*s.additionalProperties.always {
+				s.violate(out, value, valuePointer, "additionalProperties", "property %q is not allowed", key)
+				continue
+			}
+			s.additionalProperties.validate(value, valuePointer, out)
+		}
+	}
+}
+
+func (s *schema) validateArray(v *value, pointer string, out *[]Violation) {
+	if s.minItems >= 0 && len(v.elems) < s.minItems {
+		s.violate(out, v, pointer, "minItems", "array has %d items, less than %d", len(v.elems), s.minItems)
+	}
+	if s.maxItems >= 0 && len(v.elems) > s.maxItems {
+		s.violate(out, v, pointer, "maxItems", "array has %d items, more than %d", len(v.elems), s.maxItems)
+	}
+	if s.uniqueItems {
+	UNIQUE:
+		for i := 1; i < len(v.elems); i++ {
+			for j := 0; j < i; j++ {
+				if equal(v.elems[i], v.elems[j]) {
+					s.violate(out, v, pointer, "uniqueItems", "items %d and %d are equal", j, i)
+					break UNIQUE
+				}
+			}
+		}
+	}
+	for i, elem := range v.elems {
+		elemPointer := pointer + "/" + indexToken(i)
+		if i < len(s.prefixItems) {
+			s.prefixItems[i].validate(elem, elemPointer, out)
+			continue
+		}
+		if s.items != nil {
+			if s.items.always != nil && !*s.items.always {
+				s.violate(out, elem, elemPointer, "items", "array must have at most %d items", len(s.prefixItems))
+				continue
+			}
+			s.items.validate(elem, elemPointer, out)
+		}
+	}
+}
+
+func (s *schema) validateNumber(v *value, pointer string, out *[]Violation) {
+	if s.minimum == nil && s.maximum == nil && s.exclusiveMinimum == nil && s.exclusiveMaximum == nil && s.multipleOf == nil {
+		return
+	}
+	n := v.decimal()
+	if s.minimum != nil && n.cmp(s.minimum.num) < 0 {
+		s.violate(out, v, pointer, "minimum", "%s is less than %s", v.str, s.minimum.str)
+	}
+	if s.maximum != nil && n.cmp(s.maximum.num) > 0 {
+		s.violate(out, v, pointer, "maximum", "%s is greater than %s", v.str, s.maximum.str)
+	}
+	if s.exclusiveMinimum != nil && n.cmp(s.exclusiveMinimum.num) <= 0 {
+		s.violate(out, v, pointer, "exclusiveMinimum", "%s is not greater than %s", v.str, s.exclusiveMinimum.str)
+	}
+	if s.exclusiveMaximum != nil && n.cmp(s.exclusiveMaximum.num) >= 0 {
+		s.violate(out, v, pointer, "exclusiveMaximum", "%s is not less than %s", v.str, s.exclusiveMaximum.str)
+	}
+	if s.multipleOf != nil && !n.isMultipleOf(s.multipleOf.num) {
+		s.violate(out, v, pointer, "multipleOf", "%s is not a multiple of %s", v.str, s.multipleOf.str)
+	}
+}
+
+func (s *schema) validateString(v *value, pointer string, out *[]Violation) {
+	if s.minLength >= 0 || s.maxLength >= 0 {
+		length := utf8.RuneCountInString(v.str)
+		if s.minLength >= 0 && length < s.minLength {
+			s.violate(out, v, pointer, "minLength", "string has %d characters, less than %d", length, s.minLength)
+		}
+		if s.maxLength >= 0 && length > s.maxLength {
+			s.violate(out, v, pointer, "maxLength", "string has %d characters, more than %d", length, s.maxLength)
+		}
+	}
+	if s.pattern != nil && !s.pattern.MatchString(v.str) {
+		s.violate(out, v, pointer, "pattern", "string doesn't match the pattern %q", s.pattern)
+	}
+}
@@ -0,0 +1,372 @@
+package jsonschema
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"github.com/goccy/go-json/internal/errors"
+)
+
+type valueKind int
+
+const (
+	nullKind valueKind = iota
+	boolKind
+	numberKind
+	stringKind
+	arrayKind
+	objectKind
+)
+
+var kindNames = [...]string{
+	nullKind:   "null",
+	boolKind:   "boolean",
+	numberKind: "number",
+	stringKind: "string",
+	arrayKind:  "array",
+	objectKind: "object",
+}
+
+func (k valueKind) String() string {
+	return kindNames[k]
+}
+
+// value is a JSON value of the document with its byte offset.
+type value struct {
+	kind    valueKind
+	offset  int64
+	boolean bool
+	str     string // the string, or the literal of the number
+	elems   []*value
+	members []member
+}
+
+type member struct {
+	key   string
+	value *value
+}
+
+// get returns the value of the key. The last member wins like the decoder.
+func (v *value) get(key string) (*value, bool) {
+	for i := len(v.members) - 1; i >= 0; i-- {
+		if v.members[i].key == key {
+			return v.members[i].value, true
+		}
+	}
+	return nil, false
+}
+
+// keys returns the distinct keys of the object in the order of the document.
+func (v *value) keys() []string {
+	keys := make([]string, 0, len(v.members))
+	seen := make(map[string]struct{}, len(v.members))
+	for _, m := range v.members {
+		if _, exists := seen[m.key]; exists {
+			continue
+		}
+		seen[m.key] = struct{}{}
+		keys = append(keys, m.key)
+	}
+	return keys
+}
+
+// decimal returns the exact value of the number. It's converted from the literal when the value is compared,
+// so that the numbers that aren't compared, like the numbers without the number keywords, cost nothing.
+func (v *value) decimal() decimal {
+	return parseDecimal(v.str)
+}
+
+// isInteger reports whether the number has no fraction, like 1, 1.0 and 1e2.
+func (v *value) isInteger() bool {
+	return v.kind == numberKind && v.decimal().isInteger()
+}
+
+// parse parses the JSON document.
+// The numbers are checked by the grammar of JSON, so the numbers out of the range of float64 are valid.
+// The strings are converted by the decoder of go-json, so that the validator reads the same strings as Unmarshal.
+func parse(data []byte) (*value, error) {
+	p := &parser{data: data}
+	v, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	p.skipWhiteSpace()
+	if p.cursor < len(p.data) {
+		return nil, errors.ErrSyntax(fmt.Sprintf("invalid character '%c' after top-level value", p.data[p.cursor]), int64(p.cursor)+1)
+	}
+	return v, nil
+}
+
+// parser reads the values from the document.
+type parser struct {
+	data   []byte
+	cursor int
+}
+
+func (p *parser) skipWhiteSpace() {
+	for p.cursor < len(p.data) {
+		switch p.data[p.cursor] {
+		case ' ', '\t', '\n', '\r':
+			p.cursor++
+		default:
+			return
+		}
+	}
+}
+
+// expect skips the white spaces and c, or returns the syntax error.
+func (p *parser) expect(c byte, context string) error {
+	p.skipWhiteSpace()
+	if p.cursor >= len(p.data) {
+		return errors.ErrUnexpectedEndOfJSON(context, int64(p.cursor))
+	}
+	if p.data[p.cursor] != c {
+		return errors.ErrInvalidCharacter(p.data[p.cursor], context, int64(p.cursor))
+	}
+	p.cursor++
+	return nil
+}
+
+func (p *parser) parseValue() (*value, error) {
+	p.skipWhiteSpace()
+	if p.cursor >= len(p.data) {
+		return nil, errors.ErrUnexpectedEndOfJSON("value", int64(p.cursor))
+	}
+	v := &value{offset: int64(p.cursor)}
+	switch c := p.data[p.cursor]; c {
+	case '{':
+		v.kind = objectKind
+		p.cursor++
+		p.skipWhiteSpace()
+		if p.cursor < len(p.data) && p.data[p.cursor] == '}' {
+			p.cursor++
+			return v, nil
+		}
+		for {
+			p.skipWhiteSpace()
+			if p.cursor >= len(p.data) {
+				return nil, errors.ErrUnexpectedEndOfJSON("object key", int64(p.cursor))
+			}
+			if p.data[p.cursor] != '"' {
+				return nil, errors.ErrInvalidCharacter(p.data[p.cursor], "object key", int64(p.cursor))
+			}
+			key, err := p.parseString()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(':', "colon after object key"); err != nil {
+				return nil, err
+			}
+			elem, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			v.members = append(v.members, member{key: key, value: elem})
+			if end, err := p.parseSeparator('}', "object"); err != nil || end {
+				return v, err
+			}
+		}
+	case '[':
+		v.kind = arrayKind
+		p.cursor++
+		p.skipWhiteSpace()
+		if p.cursor < len(p.data) && p.data[p.cursor] == ']' {
+			p.cursor++
+			return v, nil
+		}
+		for {
+			elem, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			v.elems = append(v.elems, elem)
+			if end, err := p.parseSeparator(']', "array"); err != nil || end {
+				return v, err
+			}
+		}
+	case '"':
+		str, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		v.kind = stringKind
+		v.str = str
+		return v, nil
+	case 't':
+		v.kind = boolKind
+		v.boolean = true
+		return v, p.parseLiteral("true")
+	case 'f':
+		v.kind = boolKind
+		return v, p.parseLiteral("false")
+	case 'n':
+		v.kind = nullKind
+		return v, p.parseLiteral("null")
+	default:
+		str, err := p.parseNumber()
+		if err != nil {
+			return nil, err
+		}
+		v.kind = numberKind
+		v.str = str
+		return v, nil
+	}
+}
+
+// parseSeparator reads the comma or end after the member or the element, and reports whether it is end.
+func (p *parser) parseSeparator(end byte, context string) (bool, error) {
+	p.skipWhiteSpace()
+	if p.cursor >= len(p.data) {
+		return false, errors.ErrUnexpectedEndOfJSON(context, int64(p.cursor))
+	}
+	switch c := p.data[p.cursor]; c {
+	case end:
+		p.cursor++
+		return true, nil
+	case ',':
+		p.cursor++
+		return false, nil
+	default:
+		return false, errors.ErrExpected(fmt.Sprintf("comma or %c after %s value", end, context), int64(p.cursor))
+	}
+}
+
+func (p *parser) parseLiteral(literal string) error {
+	if !bytes.HasPrefix(p.data[p.cursor:], []byte(literal)) {
+		return errors.ErrSyntax(fmt.Sprintf("invalid literal, expected %s", literal), int64(p.cursor))
+	}
+	p.cursor += len(literal)
+	return nil
+}
+
+// parseNumber reads the number by the grammar: -?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?
+func (p *parser) parseNumber() (string, error) {
+	start := p.cursor
+	digits := func() int {
+		n := 0
+		for p.cursor < len(p.data) && '0' <= p.data[p.cursor] && p.data[p.cursor] <= '9' {
+			p.cursor++
+			n++
+		}
+		return n
+	}
+	invalid := func() (string, error) {
+		if p.cursor >= len(p.data) {
+			return "", errors.ErrUnexpectedEndOfJSON("number", int64(p.cursor))
+		}
+		return "", errors.ErrInvalidCharacter(p.data[p.cursor], "number", int64(p.cursor))
+	}
+	if p.data[p.cursor] == '-' {
+		p.cursor++
+	}
+	if p.cursor < len(p.data) && p.data[p.cursor] == '0' {
+		p.cursor++
+	} else if digits() == 0 {
+		return invalid()
+	}
+	if p.cursor < len(p.data) && p.data[p.cursor] == '.' {
+		p.cursor++
+		if digits() == 0 {
+			return invalid()
+		}
+	}
+	if p.cursor < len(p.data) && (p.data[p.cursor] == 'e' || p.data[p.cursor] == 'E') {
+		p.cursor++
+		if p.cursor < len(p.data) && (p.data[p.cursor] == '+' || p.data[p.cursor] == '-') {
+			p.cursor++
+		}
+		if digits() == 0 {
+			return invalid()
+		}
+	}
+	return string(p.data[start:p.cursor]), nil
+}
+
+func (p *parser) parseString() (string, error) {
+	start := p.cursor
+	p.cursor++
+	simple := true
+	for {
+		if p.cursor >= len(p.data) {
+			return "", errors.ErrUnexpectedEndOfJSON("string", int64(p.cursor))
+		}
+		c := p.data[p.cursor]
+		if c == '\\' {
+			simple = false
+			p.cursor += 2
+			continue
+		}
+		p.cursor++
+		if c == '"' {
+			break
+		}
+		if c >= 0x80 {
+			simple = false
+		}
+	}
+	literal := p.data[start:p.cursor]
+	if simple {
+		return string(literal[1 : len(literal)-1]), nil
+	}
+	// the escapes and the invalid UTF-8 sequences are converted by the decoder.
+	var s string
+	if err := json.Unmarshal(literal, &s); err != nil {
+		if syntaxErr, ok := err.(*json.SyntaxError); ok {
+			syntaxErr.Offset += int64(start)
+		}
+		return "", err
+	}
+	return s, nil
+}
+
+// pointerToken escapes the key or the index as a token of JSON Pointer.
+func pointerToken(key string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(key)
+}
+
+func indexToken(i int) string {
+	return strconv.Itoa(i)
+}
+
+// equal reports whether the values are equal as JSON values.
+// The numbers are compared by their values, and the members of the objects regardless of their order.
+func equal(x, y *value) bool {
+	if x.kind != y.kind {
+		return false
+	}
+	switch x.kind {
+	case nullKind:
+		return true
+	case boolKind:
+		return x.boolean == y.boolean
+	case numberKind:
+		return x.str == y.str || x.decimal().cmp(y.decimal()) == 0
+	case stringKind:
+		return x.str == y.str
+	case arrayKind:
+		if len(x.elems) != len(y.elems) {
+			return false
+		}
+		for i := range x.elems {
+			if !equal(x.elems[i], y.elems[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	xKeys, yKeys := x.keys(), y.keys()
+	if len(xKeys) != len(yKeys) {
+		return false
+	}
+	for _, key := range xKeys {
+		xv, _ := x.get(key)
+		yv, exists := y.get(key)
+		if !exists || !equal(xv, yv) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,152 @@
+package json
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// A LineError describes an error that occurred while decoding a line of a JSON Lines stream.
+type LineError struct {
+	Line int // 1-based line number
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("json: line %d: %s", e.Line, e.Err.Error())
+}
+
+// Unwrap returns the underlying error.
+func (e *LineError) Unwrap() error { return e.Err }
+
+// A LinesDecoder reads JSON Lines ( NDJSON ) from an input stream.
+// Each non-blank line must contain exactly one JSON value.
+type LinesDecoder struct {
+	r                     *bufio.Reader
+	line                  int
+	skipInvalid           bool
+	onInvalid             func(*LineError)
+	useNumber             bool
+	disallowUnknownFields bool
+}
+
+// NewLinesDecoder returns a new decoder that reads JSON Lines from r.
+func NewLinesDecoder(r io.Reader) *LinesDecoder {
+	return &LinesDecoder{r: bufio.NewReader(r)}
+}
+
+// SkipInvalidLines makes the decoder skip lines that fail to decode
+// and continue at the next line instead of returning the error.
+// If fn isn't nil, it is called with every skipped line's error.
+func (d *LinesDecoder) SkipInvalidLines(fn func(*LineError)) {
+	d.skipInvalid = true
+	d.onInvalid = fn
+}
+
+// UseNumber causes the decoder to unmarshal a number into an interface{} as a
+// Number instead of as a float64.
+func (d *LinesDecoder) UseNumber() {
+	d.useNumber = true
+}
+
+// DisallowUnknownFields causes the decoder to return an error when the destination
+// is a struct and the input contains object keys which do not match any
+// non-ignored, exported fields in the destination.
+func (d *LinesDecoder) DisallowUnknownFields() {
+	d.disallowUnknownFields = true
+}
+
+// Line returns the number of the line that was read last.
+func (d *LinesDecoder) Line() int {
+	return d.line
+}
+
+// Decode reads the next line and stores the decoded value in the value pointed to by v.
+// Blank lines are ignored. It returns io.EOF when there are no more lines.
+// Decoding errors are returned as *LineError.
+func (d *LinesDecoder) Decode(v interface{}) error {
+	return d.DecodeWithOption(v)
+}
+
+// DecodeWithOption is like Decode but applies DecodeOption.
+func (d *LinesDecoder) DecodeWithOption(v interface{}, optFuncs ...DecodeOptionFunc) error {
+	for {
+		line, err := d.readLine()
+		if err != nil {
+			return err
+		}
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		if err := d.decodeLine(line, v, optFuncs...); err != nil {
+			lineErr := &LineError{Line: d.line, Err: err}
+			if !d.skipInvalid {
+				return lineErr
+			}
+			if d.onInvalid != nil {
+				d.onInvalid(lineErr)
+			}
+			continue
+		}
+		return nil
+	}
+}
+
+func (d *LinesDecoder) decodeLine(line []byte, v interface{}, optFuncs ...DecodeOptionFunc) error {
+	if !d.useNumber && !d.disallowUnknownFields {
+		return unmarshal(line, v, optFuncs...)
+	}
+	dec := NewDecoder(bytes.NewReader(line))
+	if d.useNumber {
+		dec.UseNumber()
+	}
+	if d.disallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+	if err := dec.DecodeWithOption(v, optFuncs...); err != nil {
+		return err
+	}
+	// only whitespace can follow the value, like Unmarshal.
+	src := make([]byte, len(line)+1) // append nul byte to the end
+	copy(src, line)
+	return validateEndBuf(src, dec.InputOffset())
+}
+
+func (d *LinesDecoder) readLine() ([]byte, error) {
+	line, err := d.r.ReadBytes('\n')
+	if len(line) > 0 {
+		d.line++
+		return line, nil
+	}
+	if err == nil {
+		return nil, io.EOF
+	}
+	return nil, err
+}
+
+// A LinesEncoder writes JSON Lines ( NDJSON ) to an output stream.
+// Every value is written in compact form followed by a single newline character.
+type LinesEncoder struct {
+	enc *Encoder
+}
+
+// NewLinesEncoder returns a new encoder that writes JSON Lines to w.
+func NewLinesEncoder(w io.Writer) *LinesEncoder {
+	return &LinesEncoder{enc: NewEncoder(w)}
+}
+
+// SetEscapeHTML specifies whether problematic HTML characters should be escaped inside JSON quoted strings.
+func (e *LinesEncoder) SetEscapeHTML(on bool) {
+	e.enc.SetEscapeHTML(on)
+}
+
+// Encode writes the JSON encoding of v as a single line.
+func (e *LinesEncoder) Encode(v interface{}) error {
+	return e.enc.EncodeWithOption(v)
+}
+
+// EncodeWithOption call Encode with EncodeOption.
+func (e *LinesEncoder) EncodeWithOption(v interface{}, optFuncs ...EncodeOptionFunc) error {
+	return e.enc.EncodeWithOption(v, optFuncs...)
+}
@@ -0,0 +1,106 @@
+package json_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+)
+
+type linesRecord struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestLinesDecoder(t *testing.T) {
+	src := "{\"id\":1,\"name\":\"a\"}\n\n{\"id\":2,\"name\":\"b\"}\r\n{\"id\":3,\"name\":\"c\"}"
+	t.Run("decode", func(t *testing.T) {
+		dec := json.NewLinesDecoder(strings.NewReader(src))
+		var ids []int
+		for {
+			var v linesRecord
+			if err := dec.Decode(&v); err != nil {
+				if err == io.EOF {
+					break
+				}
+				t.Fatal(err)
+			}
+			ids = append(ids, v.ID)
+		}
+		assertEq(t, "ids", 3, len(ids))
+		assertEq(t, "last id", 3, ids[2])
+		assertEq(t, "line", 4, dec.Line())
+	})
+	t.Run("error with line number", func(t *testing.T) {
+		dec := json.NewLinesDecoder(strings.NewReader("{\"id\":1}\n{\"id\":\n{\"id\":3}\n"))
+		var v linesRecord
+		assertErr(t, dec.Decode(&v))
+		err := dec.Decode(&v)
+		lineErr, ok := err.(*json.LineError)
+		if !ok {
+			t.Fatalf("expected LineError but got %v", err)
+		}
+		assertEq(t, "line", 2, lineErr.Line)
+	})
+	t.Run("skip invalid lines", func(t *testing.T) {
+		dec := json.NewLinesDecoder(strings.NewReader("{\"id\":1}\n{\"id\":\n[1,2]\n{\"id\":4} x\n{\"id\":5}\n"))
+		var skipped []int
+		dec.SkipInvalidLines(func(err *json.LineError) {
+			skipped = append(skipped, err.Line)
+		})
+		var ids []int
+		for {
+			var v linesRecord
+			if err := dec.Decode(&v); err != nil {
+				if err == io.EOF {
+					break
+				}
+				t.Fatal(err)
+			}
+			ids = append(ids, v.ID)
+		}
+		assertEq(t, "decoded", 2, len(ids))
+		assertEq(t, "last id", 5, ids[1])
+		assertEq(t, "skipped", 3, len(skipped))
+		assertEq(t, "first skipped line", 2, skipped[0])
+	})
+	t.Run("disallow unknown fields", func(t *testing.T) {
+		dec := json.NewLinesDecoder(strings.NewReader("{\"id\":1,\"unknown\":2}\n"))
+		dec.DisallowUnknownFields()
+		var v linesRecord
+		if err := dec.Decode(&v); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+	t.Run("trailing characters", func(t *testing.T) {
+		for _, line := range []string{"{\"id\":1}]\n", "{\"id\":1}}\n", "{\"id\":1} 2\n"} {
+			dec := json.NewLinesDecoder(strings.NewReader(line))
+			dec.UseNumber()
+			var v linesRecord
+			if _, ok := dec.Decode(&v).(*json.LineError); !ok {
+				t.Errorf("%q: expected LineError", line)
+			}
+		}
+		dec := json.NewLinesDecoder(strings.NewReader("{\"id\":1} \t\r\n"))
+		dec.UseNumber()
+		var v linesRecord
+		if err := dec.Decode(&v); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestLinesEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	enc := json.NewLinesEncoder(&buf)
+	assertErr(t, enc.Encode(linesRecord{ID: 1, Name: "multi\nline"}))
+	assertErr(t, enc.Encode([]interface{}{1, json.RawMessage("{\n\"a\": 1\n}")}))
+	assertErr(t, enc.Encode(nil))
+	expected := `{"id":1,"name":"multi\nline"}
+[1,{"a":1}]
+null
+`
+	assertEq(t, "lines", expected, buf.String())
+}
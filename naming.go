@@ -0,0 +1,112 @@
+package json
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/goccy/go-json/internal/decoder"
+	"github.com/goccy/go-json/internal/encoder"
+	"github.com/goccy/go-json/internal/runtime"
+)
+
+// A NamingPolicy derives the object key of a struct field from its Go name
+// when the field has no key in its json tag. Explicitly tagged keys are always used as is.
+//
+// Encoded and decoded types are compiled for every NamingPolicy,
+// so create a policy once and reuse it.
+type NamingPolicy = runtime.NamingPolicy
+
+var (
+	// SnakeCase converts field names like "UserID" into "user_id".
+	SnakeCase = NewNamingPolicy(func(name string) string {
+		return joinWords(splitWords(name), '_', strings.ToLower)
+	})
+
+	// CamelCase converts field names like "UserID" into "userId".
+	CamelCase = NewNamingPolicy(func(name string) string {
+		words := splitWords(name)
+		if len(words) == 0 {
+			return name
+		}
+		return strings.ToLower(words[0]) + joinWords(words[1:], 0, titleWord)
+	})
+
+	// PascalCase converts field names like "UserID" into "UserId".
+	PascalCase = NewNamingPolicy(func(name string) string {
+		return joinWords(splitWords(name), 0, titleWord)
+	})
+
+	// KebabCase converts field names like "UserID" into "user-id".
+	KebabCase = NewNamingPolicy(func(name string) string {
+		return joinWords(splitWords(name), '-', strings.ToLower)
+	})
+)
+
+// NewNamingPolicy returns a NamingPolicy that converts field names by convert.
+func NewNamingPolicy(convert func(fieldName string) string) *NamingPolicy {
+	return &NamingPolicy{Convert: convert}
+}
+
+// FieldNaming derives the object keys of untagged struct fields by policy when encoding.
+func FieldNaming(policy *NamingPolicy) EncodeOptionFunc {
+	return func(opt *EncodeOption) {
+		opt.Flag |= encoder.NamingPolicyOption
+		opt.NamingPolicy = policy
+	}
+}
+
+// DecodeFieldNaming derives the object keys of untagged struct fields by policy when decoding.
+// Like the Go field names, the derived keys are matched case-insensitively.
+func DecodeFieldNaming(policy *NamingPolicy) DecodeOptionFunc {
+	return func(opt *DecodeOption) {
+		opt.Flags |= decoder.NamingPolicyOption
+		opt.NamingPolicy = policy
+	}
+}
+
+// splitWords splits a Go identifier into words.
+// An acronym is treated as a word: "HTTPServerID" is split into "HTTP", "Server" and "ID".
+// Digits belong to the preceding word and underscores separate words.
+func splitWords(name string) []string {
+	runes := []rune(name)
+	words := []string{}
+	start := 0
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == '_' {
+			if start < i {
+				words = append(words, string(runes[start:i]))
+			}
+			start = i + 1
+			continue
+		}
+		if i == start || !unicode.IsUpper(runes[i]) {
+			continue
+		}
+		prev := runes[i-1]
+		if !unicode.IsUpper(prev) || (i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+	return words
+}
+
+func joinWords(words []string, sep byte, conv func(string) string) string {
+	var b strings.Builder
+	for i, word := range words {
+		if i > 0 && sep != 0 {
+			b.WriteByte(sep)
+		}
+		b.WriteString(conv(word))
+	}
+	return b.String()
+}
+
+func titleWord(word string) string {
+	runes := []rune(strings.ToLower(word))
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
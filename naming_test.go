@@ -0,0 +1,78 @@
+package json_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+)
+
+type namingUser struct {
+	UserID       int
+	HTTPServer   string
+	Name         string `json:"display_name"`
+	Address2Line string `json:",omitempty"`
+	Inner        *namingUser
+}
+
+func TestFieldNaming(t *testing.T) {
+	v := namingUser{UserID: 1, HTTPServer: "s", Name: "n", Inner: &namingUser{UserID: 2}}
+	tests := []struct {
+		name     string
+		policy   *json.NamingPolicy
+		expected string
+	}{
+		{"snake", json.SnakeCase, `{"user_id":1,"http_server":"s","display_name":"n","inner":{"user_id":2,"http_server":"","display_name":"","inner":null}}`},
+		{"camel", json.CamelCase, `{"userId":1,"httpServer":"s","display_name":"n","inner":{"userId":2,"httpServer":"","display_name":"","inner":null}}`},
+		{"pascal", json.PascalCase, `{"UserId":1,"HttpServer":"s","display_name":"n","Inner":{"UserId":2,"HttpServer":"","display_name":"","Inner":null}}`},
+		{"kebab", json.KebabCase, `{"user-id":1,"http-server":"s","display_name":"n","inner":{"user-id":2,"http-server":"","display_name":"","inner":null}}`},
+		{"custom", json.NewNamingPolicy(strings.ToUpper), `{"USERID":1,"HTTPSERVER":"s","display_name":"n","INNER":{"USERID":2,"HTTPSERVER":"","display_name":"","INNER":null}}`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b, err := json.MarshalWithOption(v, json.FieldNaming(test.policy))
+			assertErr(t, err)
+			assertEq(t, "encode", test.expected, string(b))
+
+			var decoded namingUser
+			assertErr(t, json.UnmarshalWithOption(b, &decoded, json.DecodeFieldNaming(test.policy)))
+			assertEq(t, "decode", v.UserID, decoded.UserID)
+			assertEq(t, "decode nested", 2, decoded.Inner.UserID)
+			assertEq(t, "decode tagged", "n", decoded.Name)
+		})
+	}
+	t.Run("default names aren't affected", func(t *testing.T) {
+		bytes, err := json.Marshal(namingUser{UserID: 1})
+		assertErr(t, err)
+		assertEq(t, "encode", `{"UserID":1,"HTTPServer":"","display_name":"","Inner":null}`, string(bytes))
+	})
+	t.Run("interface", func(t *testing.T) {
+		bytes, err := json.MarshalWithOption([]interface{}{namingUser{UserID: 1}}, json.FieldNaming(json.SnakeCase))
+		assertErr(t, err)
+		assertEq(t, "encode", `[{"user_id":1,"http_server":"","display_name":"","inner":null}]`, string(bytes))
+	})
+}
+
+func TestFrozenConfig(t *testing.T) {
+	api := json.Config{FieldNaming: json.SnakeCase}.Froze()
+	b, err := api.Marshal(namingUser{UserID: 1})
+	assertErr(t, err)
+	assertEq(t, "marshal", `{"user_id":1,"http_server":"","display_name":"","inner":null}`, string(b))
+
+	var v namingUser
+	assertErr(t, api.Unmarshal([]byte(`{"user_id":3,"HTTP_SERVER":"s"}`), &v))
+	assertEq(t, "unmarshal", 3, v.UserID)
+	assertEq(t, "case insensitive", "s", v.HTTPServer)
+
+	var buf bytes.Buffer
+	assertErr(t, api.NewEncoder(&buf).Encode(namingUser{Address2Line: "a"}))
+	assertEq(t, "encoder", `{"user_id":0,"http_server":"","display_name":"","address2_line":"a","inner":null}`+"\n", buf.String())
+
+	dec := api.NewDecoder(strings.NewReader(`{"user_id":4} {"user_id":5}`))
+	for _, expected := range []int{4, 5} {
+		var v namingUser
+		assertErr(t, dec.Decode(&v))
+		assertEq(t, "decoder", expected, v.UserID)
+	}
+}
@@ -0,0 +1,86 @@
+package json_test
+
+import (
+	"testing"
+
+	"github.com/goccy/go-json"
+)
+
+func TestEmptyCollectionsForNil(t *testing.T) {
+	type T struct {
+		PM *map[string]int `json:"pm"`
+		S  []int           `json:"s"`
+		M  map[string]int  `json:"m"`
+		PS *[]int          `json:"ps"`
+		I  interface{}     `json:"i"`
+		P  *int            `json:"p"`
+		O  []int           `json:"o,omitempty"`
+	}
+	var (
+		nilMap   map[string]int
+		nilSlice []int
+	)
+	tests := []struct {
+		name     string
+		v        interface{}
+		expected string
+	}{
+		{"slice", nilSlice, `[]`},
+		{"map", nilMap, `{}`},
+		{"pointer to nil slice", &nilSlice, `[]`},
+		{"pointer to nil map", &nilMap, `{}`},
+		{"nil pointer to map", (*map[string]int)(nil), `null`},
+		{"nil pointer to slice", (*[]int)(nil), `null`},
+		{"elements", []interface{}{[]map[string]int{nil}, map[string][]int{"a": nil}, []*map[string]int{nil, &nilMap}}, `[[{}],{"a":[]},[null,{}]]`},
+		{"struct", T{}, `{"pm":null,"s":[],"m":{},"ps":null,"i":null,"p":null}`},
+		{"struct pointers", &T{PM: &nilMap, PS: &nilSlice, I: nilSlice}, `{"pm":{},"s":[],"m":{},"ps":[],"i":[],"p":null}`},
+		{"nil interface", []interface{}{nil}, `[null]`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b, err := json.MarshalWithOption(test.v, json.EmptyCollectionsForNil())
+			assertErr(t, err)
+			assertEq(t, "compact", test.expected, string(b))
+
+			b, err = json.MarshalIndentWithOption(test.v, "", "", json.EmptyCollectionsForNil())
+			assertErr(t, err)
+			got, err := json.MarshalWithOption(json.RawMessage(b), json.Canonical())
+			assertErr(t, err)
+			expected, err := json.Canonicalize([]byte(test.expected))
+			assertErr(t, err)
+			assertEq(t, "indent", string(expected), string(got))
+		})
+	}
+	t.Run("colorized", func(t *testing.T) {
+		b, err := json.MarshalWithOption(T{}, json.EmptyCollectionsForNil(), json.Colorize(json.DefaultColorScheme))
+		assertErr(t, err)
+		assertNeq(t, "colorized", "", string(b))
+	})
+	t.Run("disabled", func(t *testing.T) {
+		b, err := json.Marshal(T{})
+		assertErr(t, err)
+		assertEq(t, "struct", `{"pm":null,"s":null,"m":null,"ps":null,"i":null,"p":null}`, string(b))
+	})
+}
+
+func TestNoNilTag(t *testing.T) {
+	type T struct {
+		S  []string          `json:"s,nonil"`
+		M  map[string]string `json:"m,nonil"`
+		PS *[]string         `json:"ps,nonil"`
+		N  []string          `json:"n"`
+		O  []string          `json:"o,omitempty,nonil"`
+	}
+	var nilSlice []string
+	b, err := json.Marshal(T{})
+	assertErr(t, err)
+	assertEq(t, "zero", `{"s":[],"m":{},"ps":null,"n":null}`, string(b))
+
+	b, err = json.Marshal(&T{PS: &nilSlice})
+	assertErr(t, err)
+	assertEq(t, "pointer", `{"s":[],"m":{},"ps":[],"n":null}`, string(b))
+
+	b, err = json.MarshalIndent(T{}, "", " ")
+	assertErr(t, err)
+	assertEq(t, "indent", "{\n \"s\": [],\n \"m\": {},\n \"ps\": null,\n \"n\": null\n}", string(b))
+}
@@ -1,6 +1,8 @@
 package json
 
 import (
+	"io"
+
 	"github.com/goccy/go-json/internal/decoder"
 	"github.com/goccy/go-json/internal/encoder"
 )
@@ -22,7 +24,18 @@ func Debug() EncodeOptionFunc {
 	}
 }
 
+// DebugWriter writes every opcode executed during encoding to w, in the format of DumpEncoderProgram
+// followed by the length of the output so far. The debug information of Debug is also written to w.
+// It's slow, and intended to investigate the encoded results.
+func DebugWriter(w io.Writer) EncodeOptionFunc {
+	return func(opt *EncodeOption) {
+		opt.Flag |= encoder.DebugOption | encoder.DebugTraceOption
+		opt.DebugWriter = w
+	}
+}
+
 // Colorize add an identifier for coloring to the string of the encoded result.
+// If scheme.EscapeHTML is set, HTML characters in strings are escaped regardless of the other options.
 func Colorize(scheme *ColorScheme) EncodeOptionFunc {
 	return func(opt *EncodeOption) {
 		opt.Flag |= encoder.ColorizeOption
@@ -30,6 +43,77 @@ func Colorize(scheme *ColorScheme) EncodeOptionFunc {
 	}
 }
 
+// Canonical outputs the canonical form defined by RFC 8785 ( JSON Canonicalization Scheme ),
+// which is suitable for signing and hashing.
+// Members of structs and maps are sorted by the UTF-16 code units of their keys,
+// numbers, including the integers, are formatted like ECMAScript's Number.prototype.toString
+// of the nearest IEEE 754 double precision values, so the integers beyond 2^53 may be rounded,
+// and strings are minimally escaped and HTML characters are not escaped.
+// The output is always compact, so indentation and Colorize are ignored.
+func Canonical() EncodeOptionFunc {
+	return func(opt *EncodeOption) {
+		opt.Flag |= encoder.CanonicalOption
+	}
+}
+
+// FloatStyle controls how floating point numbers are formatted.
+// The zero value formats numbers like encoding/json.
+type FloatStyle = encoder.FloatStyle
+
+// FloatFormat formats floating point numbers by style.
+// The "precision=N" and "fmt=f" ( or "fmt=e" ) options of a struct field tag
+// override the precision and the notation of the style for the field.
+func FloatFormat(style FloatStyle) EncodeOptionFunc {
+	return func(opt *EncodeOption) {
+		opt.Flag |= encoder.FloatStyleOption
+		opt.FloatStyle = &style
+	}
+}
+
+// EmptyCollectionsForNil encodes nil slices as [] and nil maps as {} instead of null.
+// Nil pointers and nil interfaces are still encoded as null.
+// The "nonil" option of struct field tag enables the same behavior for the field only.
+func EmptyCollectionsForNil() EncodeOptionFunc {
+	return func(opt *EncodeOption) {
+		opt.Flag |= encoder.EmptyCollectionsForNilOption
+	}
+}
+
+// PreserveReferences encodes the object graphs that share or cycle pointers to structs.
+// The first visit of a pointer writes the struct with an "$id" member like {"$id":"1","name":"a"},
+// and the later visits write only the reference to it like {"$ref":"1"}.
+// The pointers to structs that implement json.Marshaler or encoding.TextMarshaler are encoded normally.
+// DecodePreserveReferences restores the shared pointers. It is ignored with Canonical.
+func PreserveReferences() EncodeOptionFunc {
+	return func(opt *EncodeOption) {
+		opt.Flag |= encoder.PreserveReferencesOption
+	}
+}
+
+// MaxSafeInteger is the maximum integer that JavaScript can represent exactly ( 2^53 - 1 ).
+// It is the typical threshold of Int64AsString.
+const MaxSafeInteger = 1<<53 - 1
+
+// Int64AsString quotes the 64-bit integer values ( int64, uint64, int and uint on 64-bit platforms )
+// whose magnitude exceeds threshold, like the ",string" option of struct field tag.
+// The other integers are encoded as numbers.
+func Int64AsString(threshold uint64) EncodeOptionFunc {
+	return intAsString(64, threshold)
+}
+
+// IntAsString is like Int64AsString but applies to integer values of any size.
+func IntAsString(threshold uint64) EncodeOptionFunc {
+	return intAsString(8, threshold)
+}
+
+func intAsString(bitSize uint8, threshold uint64) EncodeOptionFunc {
+	return func(opt *EncodeOption) {
+		opt.Flag |= encoder.IntAsStringOption
+		opt.IntStringBitSize = bitSize
+		opt.IntStringThreshold = threshold
+	}
+}
+
 type DecodeOption = decoder.Option
 type DecodeOptionFunc func(*DecodeOption)
 
@@ -44,3 +128,20 @@ func DecodeFieldPriorityFirstWin() DecodeOptionFunc {
 		opt.Flags |= decoder.FirstWinOption
 	}
 }
+
+// DecodePreserveReferences restores the pointers to structs shared in the output of PreserveReferences.
+// An object with the "$id" member is registered by the id, and an object like {"$ref":"1"}
+// is decoded as the pointer to the registered struct. The reference must be of the same type as the struct.
+func DecodePreserveReferences() DecodeOptionFunc {
+	return func(opt *DecodeOption) {
+		opt.Flags |= decoder.PreserveReferencesOption
+	}
+}
+
+// DecodeIntAsString accepts both quoted and bare numbers for integer values,
+// so that the output of Int64AsString and IntAsString can be decoded into the original types.
+func DecodeIntAsString() DecodeOptionFunc {
+	return func(opt *DecodeOption) {
+		opt.Flags |= decoder.IntAsStringOption
+	}
+}
@@ -0,0 +1,199 @@
+package json
+
+import (
+	"reflect"
+
+	"github.com/goccy/go-json/internal/decoder"
+	"github.com/goccy/go-json/internal/encoder"
+)
+
+// OrderedMap is a JSON object that keeps the order of its keys.
+// It is encoded in the insertion order of the keys and decoded in the order of the document.
+// The nested objects in the values are decoded as *OrderedMap too.
+// The zero value is an empty map ready to use.
+type OrderedMap struct {
+	items []OrderedMapItem
+	index map[string]int
+}
+
+// OrderedMapItem is a member of OrderedMap.
+type OrderedMapItem struct {
+	Key   string
+	Value interface{}
+}
+
+// NewOrderedMap returns an empty OrderedMap.
+func NewOrderedMap() *OrderedMap {
+	return &OrderedMap{}
+}
+
+// Len returns the number of the members.
+func (m *OrderedMap) Len() int {
+	return len(m.items)
+}
+
+// Get returns the value of key and whether key exists.
+func (m *OrderedMap) Get(key string) (interface{}, bool) {
+	i, exists := m.index[key]
+	if !exists {
+		return nil, false
+	}
+	return m.items[i].Value, true
+}
+
+// Set sets value to key. An existing key keeps its position, and a new key is appended to the end.
+func (m *OrderedMap) Set(key string, value interface{}) {
+	if i, exists := m.index[key]; exists {
+		m.items[i].Value = value
+		return
+	}
+	if m.index == nil {
+		m.index = map[string]int{}
+	}
+	m.index[key] = len(m.items)
+	m.items = append(m.items, OrderedMapItem{Key: key, Value: value})
+}
+
+// Delete removes key and keeps the order of the other keys.
+func (m *OrderedMap) Delete(key string) {
+	i, exists := m.index[key]
+	if !exists {
+		return
+	}
+	delete(m.index, key)
+	m.items = append(m.items[:i], m.items[i+1:]...)
+	for j := i; j < len(m.items); j++ {
+		m.index[m.items[j].Key] = j
+	}
+}
+
+// Keys returns the keys in order.
+func (m *OrderedMap) Keys() []string {
+	keys := make([]string, 0, len(m.items))
+	for _, item := range m.items {
+		keys = append(keys, item.Key)
+	}
+	return keys
+}
+
+// Items returns a copy of the members in order.
+func (m *OrderedMap) Items() []OrderedMapItem {
+	return append([]OrderedMapItem{}, m.items...)
+}
+
+// MarshalJSON encodes the members in order.
+// The values in m are encoded with the options of the encoding of the value that contains m,
+// like EscapeHTML and Colorize, because Marshal and the other encoding functions don't call MarshalJSON of OrderedMap.
+func (m OrderedMap) MarshalJSON() ([]byte, error) {
+	return Marshal(&m)
+}
+
+func init() {
+	encoder.RegisterAppendMarshaler(reflect.TypeOf(OrderedMap{}), appendOrderedMap)
+	encoder.RegisterAppendMarshaler(reflect.TypeOf(&OrderedMap{}), appendOrderedMap)
+}
+
+// appendOrderedMap appends the members of the OrderedMap v in order, with the options of ctx.
+func appendOrderedMap(ctx *encoder.RuntimeContext, code *encoder.Opcode, b []byte, v interface{}, indent bool) ([]byte, error) {
+	var m *OrderedMap
+	switch v := v.(type) {
+	case OrderedMap:
+		m = &v
+	case *OrderedMap:
+		m = v
+	}
+	if m == nil {
+		return encoder.AppendNull(ctx, b), nil
+	}
+	if len(m.items) == 0 {
+		return append(b, '{', '}'), nil
+	}
+	b = append(b, '{')
+	for i, item := range m.items {
+		if i > 0 {
+			b = append(b, ',')
+		}
+		if indent {
+			b = append(b, '\n')
+			b = encoder.AppendIndent(ctx, b, code.Indent+1)
+		}
+		if (ctx.Option.Flag & encoder.ColorizeOption) != 0 {
+			format := ctx.Option.ColorScheme.ObjectKey
+			b = append(b, format.Header...)
+			b = encoder.AppendString(ctx, b, item.Key)
+			b = append(b, format.Footer...)
+		} else {
+			b = encoder.AppendString(ctx, b, item.Key)
+		}
+		b = append(b, ':')
+		if indent {
+			b = append(b, ' ')
+		}
+		var err error
+		b, err = appendOrderedMapValue(ctx, code, b, item.Value, indent)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if indent {
+		b = append(b, '\n')
+		b = encoder.AppendIndent(ctx, b, code.Indent)
+	}
+	return append(b, '}'), nil
+}
+
+// appendOrderedMapValue encodes v with a copy of the options of ctx, and appends it without the trailing separator.
+func appendOrderedMapValue(ctx *encoder.RuntimeContext, code *encoder.Opcode, b []byte, v interface{}, indent bool) ([]byte, error) {
+	rctx := encoder.TakeRuntimeContext()
+	defer encoder.ReleaseRuntimeContext(rctx)
+	*rctx.Option = *ctx.Option
+
+	if !indent {
+		buf, err := encode(rctx, v)
+		if err != nil {
+			return nil, err
+		}
+		return append(b, buf[:len(buf)-1]...), nil
+	}
+	// the lines of the value are indented from the depth of the member.
+	buf, err := encodeIndent(rctx, v, encoder.IndentPrefix(ctx, code.Indent+1), string(ctx.IndentStr))
+	if err != nil {
+		return nil, err
+	}
+	return append(b, buf[:len(buf)-2]...), nil
+}
+
+// UnmarshalJSON decodes a JSON object into m, replacing its members.
+func (m *OrderedMap) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := UnmarshalWithOption(data, &v, DecodeOrderedMap()); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case *OrderedMap:
+		*m = *v
+		return nil
+	case []interface{}:
+		return &UnmarshalTypeError{Value: "array", Type: reflect.TypeOf(m)}
+	case string:
+		return &UnmarshalTypeError{Value: "string", Type: reflect.TypeOf(m)}
+	case bool:
+		return &UnmarshalTypeError{Value: "bool", Type: reflect.TypeOf(m)}
+	}
+	return &UnmarshalTypeError{Value: "number", Type: reflect.TypeOf(m)}
+}
+
+// DecodeOrderedMap decodes the JSON objects into interface{} values as *OrderedMap
+// instead of map[string]interface{}, so that the order of the keys is kept.
+func DecodeOrderedMap() DecodeOptionFunc {
+	return func(opt *DecodeOption) {
+		opt.Flags |= decoder.OrderedMapOption
+		opt.NewOrderedObject = newOrderedObject
+	}
+}
+
+func newOrderedObject() decoder.OrderedObject {
+	return NewOrderedMap()
+}
@@ -0,0 +1,209 @@
+package json_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+)
+
+const orderedMapSrc = `{"zeta":1,"alpha":{"y":true,"x":null},"mid":[{"b":"2","a":"1"}]}`
+
+func TestOrderedMap(t *testing.T) {
+	t.Run("set and delete", func(t *testing.T) {
+		var m json.OrderedMap
+		m.Set("c", 1)
+		m.Set("a", 2)
+		m.Set("b", 3)
+		m.Set("c", 4)
+		m.Delete("a")
+		m.Delete("unknown")
+		assertEq(t, "keys", "c,b", strings.Join(m.Keys(), ","))
+		v, ok := m.Get("c")
+		assertEq(t, "exists", true, ok)
+		assertEq(t, "value", 4, v)
+		_, ok = m.Get("a")
+		assertEq(t, "deleted", false, ok)
+		b, err := json.Marshal(m)
+		assertErr(t, err)
+		assertEq(t, "marshal", `{"c":4,"b":3}`, string(b))
+	})
+	t.Run("round trip", func(t *testing.T) {
+		var m json.OrderedMap
+		assertErr(t, json.Unmarshal([]byte(orderedMapSrc), &m))
+		assertEq(t, "len", 3, m.Len())
+		alpha, _ := m.Get("alpha")
+		assertEq(t, "nested keys", "y,x", strings.Join(alpha.(*json.OrderedMap).Keys(), ","))
+		b, err := json.Marshal(&m)
+		assertErr(t, err)
+		assertEq(t, "marshal", orderedMapSrc, string(b))
+	})
+	t.Run("indent", func(t *testing.T) {
+		m := json.NewOrderedMap()
+		m.Set("b", 1)
+		m.Set("a", []int{1})
+		b, err := json.MarshalIndent(struct {
+			M *json.OrderedMap `json:"m"`
+		}{M: m}, "", "  ")
+		assertErr(t, err)
+		expected := `{
+  "m": {
+    "b": 1,
+    "a": [
+      1
+    ]
+  }
+}`
+		assertEq(t, "indent", expected, string(b))
+	})
+	t.Run("options", func(t *testing.T) {
+		type Inner struct {
+			UserName string
+			Password string `json:",redact"`
+			Count    int
+			Price    float64
+		}
+		inner := Inner{UserName: "<alice>", Password: "hunter2", Count: 1 << 60, Price: 0.000001}
+		m := json.NewOrderedMap()
+		m.Set("zeta", inner)
+		m.Set("alpha", []interface{}{inner, map[string]int{"b": 1, "a": 2}})
+		m.Set("<html>", json.NewOrderedMap())
+		// the struct that has the fields in the order of m is encoded like m.
+		type Expected struct {
+			Zeta  Inner         `json:"zeta"`
+			Alpha []interface{} `json:"alpha"`
+			HTML  struct{}      `json:"<html>"`
+		}
+		expected := Expected{Zeta: inner, Alpha: []interface{}{inner, map[string]int{"b": 1, "a": 2}}}
+		for _, tc := range []struct {
+			name string
+			opts []json.EncodeOptionFunc
+		}{
+			{"default", nil},
+			{"colorize", []json.EncodeOptionFunc{json.Colorize(json.DefaultColorScheme)}},
+			{"field naming", []json.EncodeOptionFunc{json.FieldNaming(json.SnakeCase)}},
+			{"redact", []json.EncodeOptionFunc{json.Redact()}},
+			{"int as string", []json.EncodeOptionFunc{json.IntAsString(1 << 53)}},
+			{"float format", []json.EncodeOptionFunc{json.FloatFormat(json.FloatStyle{Notation: 'e', Precision: 3})}},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				b, err := json.MarshalWithOption(struct {
+					M *json.OrderedMap `json:"m"`
+				}{M: m}, tc.opts...)
+				assertErr(t, err)
+				exp, err := json.MarshalWithOption(struct {
+					M Expected `json:"m"`
+				}{M: expected}, tc.opts...)
+				assertErr(t, err)
+				assertEq(t, "compact", string(exp), string(b))
+
+				b, err = json.MarshalIndentWithOption(struct {
+					M []*json.OrderedMap `json:"m"`
+				}{M: []*json.OrderedMap{m}}, ">", "\t", tc.opts...)
+				assertErr(t, err)
+				exp, err = json.MarshalIndentWithOption(struct {
+					M []Expected `json:"m"`
+				}{M: []Expected{expected}}, ">", "\t", tc.opts...)
+				assertErr(t, err)
+				assertEq(t, "indent", string(exp), string(b))
+			})
+		}
+		t.Run("canonical", func(t *testing.T) {
+			b, err := json.MarshalWithOption(m, json.Canonical())
+			assertErr(t, err)
+			exp, err := json.MarshalWithOption(expected, json.Canonical())
+			assertErr(t, err)
+			assertEq(t, "canonical", string(exp), string(b))
+		})
+		t.Run("escape html", func(t *testing.T) {
+			var buf, exp strings.Builder
+			enc := json.NewEncoder(&buf)
+			enc.SetEscapeHTML(false)
+			assertErr(t, enc.Encode(m))
+			enc = json.NewEncoder(&exp)
+			enc.SetEscapeHTML(false)
+			assertErr(t, enc.Encode(expected))
+			assertEq(t, "no escape", exp.String(), buf.String())
+		})
+	})
+	t.Run("indent", func(t *testing.T) {
+		inner := json.NewOrderedMap()
+		inner.Set("z", map[string]interface{}{"k": []interface{}{1}})
+		m := json.NewOrderedMap()
+		m.Set("x", 1)
+		m.Set("in", inner)
+		m.Set("list", []interface{}{inner})
+		for _, v := range []interface{}{
+			m,
+			[]interface{}{m},
+			[]*json.OrderedMap{m},
+			map[string]interface{}{"a": m},
+			struct {
+				A interface{}
+				B []interface{}
+			}{A: m, B: []interface{}{[]interface{}{m}}},
+		} {
+			b, err := json.MarshalIndent(v, ">", "  ")
+			assertErr(t, err)
+			compact, err := json.Marshal(v)
+			assertErr(t, err)
+			var expected bytes.Buffer
+			assertErr(t, json.Indent(&expected, compact, ">", "  "))
+			assertEq(t, "indent", expected.String(), string(b))
+		}
+	})
+	t.Run("not object", func(t *testing.T) {
+		var m json.OrderedMap
+		err := json.Unmarshal([]byte(`[1]`), &m)
+		if _, ok := err.(*json.UnmarshalTypeError); !ok {
+			t.Fatalf("expected UnmarshalTypeError but got %v", err)
+		}
+	})
+}
+
+func TestDecodeOrderedMap(t *testing.T) {
+	t.Run("unmarshal", func(t *testing.T) {
+		var v interface{}
+		assertErr(t, json.UnmarshalWithOption([]byte(orderedMapSrc), &v, json.DecodeOrderedMap()))
+		m, ok := v.(*json.OrderedMap)
+		if !ok {
+			t.Fatalf("expected *json.OrderedMap but got %T", v)
+		}
+		mid, _ := m.Get("mid")
+		assertEq(t, "object in array", "b,a", strings.Join(mid.([]interface{})[0].(*json.OrderedMap).Keys(), ","))
+		b, err := json.Marshal(v)
+		assertErr(t, err)
+		assertEq(t, "marshal", orderedMapSrc, string(b))
+	})
+	t.Run("stream", func(t *testing.T) {
+		dec := json.NewDecoder(strings.NewReader(`{ "b" : 1 , "a" : { } } {}`))
+		for _, expected := range []string{`{"b":1,"a":{}}`, `{}`} {
+			var v interface{}
+			assertErr(t, dec.DecodeWithOption(&v, json.DecodeOrderedMap()))
+			b, err := json.Marshal(v)
+			assertErr(t, err)
+			assertEq(t, "marshal", expected, string(b))
+		}
+	})
+	t.Run("struct field", func(t *testing.T) {
+		var v struct {
+			A interface{} `json:"a"`
+			B interface{} `json:"b"`
+		}
+		assertErr(t, json.UnmarshalWithOption([]byte(`{"a":{"z":1,"y":2},"b":"s"}`), &v, json.DecodeOrderedMap()))
+		assertEq(t, "keys", "z,y", strings.Join(v.A.(*json.OrderedMap).Keys(), ","))
+	})
+	t.Run("syntax error", func(t *testing.T) {
+		var v interface{}
+		assertNeq(t, "error", nil, json.UnmarshalWithOption([]byte(`{"a" 1}`), &v, json.DecodeOrderedMap()))
+		assertNeq(t, "error", nil, json.UnmarshalWithOption([]byte(`{"a":1`), &v, json.DecodeOrderedMap()))
+	})
+	t.Run("without option", func(t *testing.T) {
+		var v interface{}
+		assertErr(t, json.Unmarshal([]byte(orderedMapSrc), &v))
+		if _, ok := v.(map[string]interface{}); !ok {
+			t.Fatalf("expected map[string]interface{} but got %T", v)
+		}
+	})
+}
@@ -0,0 +1,227 @@
+package json
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unsafe"
+
+	"github.com/goccy/go-json/internal/decoder"
+	"github.com/goccy/go-json/internal/encoder"
+	"github.com/goccy/go-json/internal/errors"
+	"github.com/goccy/go-json/internal/runtime"
+)
+
+var (
+	unmarshalerType     = reflect.TypeOf((*Unmarshaler)(nil)).Elem()
+	unmarshalerCtxType  = reflect.TypeOf((*UnmarshalerContext)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// A PrecompileProblem is a part of a type that can't be encoded or decoded, or a struct field whose json tag is ignored by mistake.
+type PrecompileProblem struct {
+	// Type is the type passed to Precompile.
+	Type reflect.Type
+	// Path is the path to the part of Type, like ".Servers[].Port". It is empty for Type itself.
+	// The elements of the slices, the arrays and the maps are written as "[]".
+	Path string
+	Err  error
+}
+
+func (p *PrecompileProblem) Error() string {
+	return fmt.Sprintf("%s%s: %v", p.Type, p.Path, p.Err)
+}
+
+// PrecompileError reports all the problems found by Precompile.
+type PrecompileError struct {
+	Problems []*PrecompileProblem
+}
+
+func (e *PrecompileError) Error() string {
+	msgs := make([]string, 0, len(e.Problems))
+	for _, p := range e.Problems {
+		msgs = append(msgs, p.Error())
+	}
+	return "json: precompile: " + strings.Join(msgs, "; ")
+}
+
+// Precompile compiles and caches the encoders and the decoders of the types of the values, like Marshal and Unmarshal do
+// on the first use of the types, so that the first requests don't pay the cost of the compilation.
+// Each value can be a value of the type, like Config{} or (*Config)(nil), or the reflect.Type of it.
+//
+// For a type T, the encoders of T and *T, and the decoder for *T are compiled, which are used by
+// Marshal(v), Marshal(&v) and Unmarshal(data, &v) for v of T.
+// The codes compiled with the encode or decode options, like FieldNaming, are compiled on the first use as before.
+//
+// Precompile checks the whole types before the compilation, and returns *PrecompileError that has
+// all the unsupported types, like channels and complex numbers, the unsupported map keys, and
+// the invalid keys and the invalid values of the options of the json tags, like "precision=0",
+// which Marshal and Unmarshal ignore silently. The unknown options, like "inline" of the other
+// libraries, aren't reported because they don't change the encoding.
+func Precompile(types ...interface{}) error {
+	var problems []*PrecompileProblem
+	for _, v := range types {
+		typ, ok := v.(reflect.Type)
+		if !ok {
+			typ = reflect.TypeOf(v)
+		}
+		if typ == nil {
+			continue
+		}
+		c := &precompileChecker{root: typ, visited: map[precompileVisit]bool{}}
+		c.check(typ, "", true, true)
+		if len(c.problems) > 0 {
+			problems = append(problems, c.problems...)
+			continue
+		}
+		if err := precompile(typ); err != nil {
+			problems = append(problems, &PrecompileProblem{Type: typ, Err: err})
+		}
+	}
+	if len(problems) > 0 {
+		return &PrecompileError{Problems: problems}
+	}
+	return nil
+}
+
+// MustPrecompile is like Precompile but panics if any type has a problem.
+// It simplifies the precompilation in the initialization of the global variables or init functions.
+func MustPrecompile(types ...interface{}) {
+	if err := Precompile(types...); err != nil {
+		panic(err)
+	}
+}
+
+func precompile(typ reflect.Type) error {
+	rtype := runtime.Type2RType(typ)
+	ptrType := runtime.PtrTo(rtype)
+	if _, err := encoder.CompileToGetCodeSet(uintptr(unsafe.Pointer(rtype))); err != nil {
+		return err
+	}
+	if _, err := encoder.CompileToGetCodeSet(uintptr(unsafe.Pointer(ptrType))); err != nil {
+		return err
+	}
+	if _, err := decoder.CompileToGetDecoder(ptrType); err != nil {
+		return err
+	}
+	return nil
+}
+
+type precompileVisit struct {
+	typ      reflect.Type
+	encoding bool
+	decoding bool
+}
+
+// precompileChecker walks the type like the compilers of the encoder and the decoder, and collects the problems.
+type precompileChecker struct {
+	root     reflect.Type
+	visited  map[precompileVisit]bool
+	problems []*PrecompileProblem
+}
+
+func (c *precompileChecker) report(path string, err error) {
+	c.problems = append(c.problems, &PrecompileProblem{Type: c.root, Path: path, Err: err})
+}
+
+// check checks typ for the encoding if encoding is true, and for the decoding if decoding is true.
+func (c *precompileChecker) check(typ reflect.Type, path string, encoding, decoding bool) {
+	ptr := reflect.PtrTo(typ)
+	if encoding && (typ.Implements(marshalerType) || typ.Implements(marshalerCtxType) || typ.Implements(textMarshalerType) ||
+		ptr.Implements(marshalerType) || ptr.Implements(marshalerCtxType) || ptr.Implements(textMarshalerType)) {
+		encoding = false
+	}
+	if decoding && (ptr.Implements(unmarshalerType) || ptr.Implements(unmarshalerCtxType) || ptr.Implements(textUnmarshalerType)) {
+		decoding = false
+	}
+	if !encoding && !decoding {
+		return
+	}
+	visit := precompileVisit{typ: typ, encoding: encoding, decoding: decoding}
+	if c.visited[visit] {
+		return
+	}
+	c.visited[visit] = true
+
+	switch typ.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		elemPath := path + "[]"
+		if typ.Kind() == reflect.Ptr {
+			elemPath = path
+		}
+		c.check(typ.Elem(), elemPath, encoding, decoding)
+	case reflect.Map:
+		c.checkMapKey(typ.Key(), path, encoding, decoding)
+		c.check(typ.Elem(), path+"[]", encoding, decoding)
+	case reflect.Struct:
+		c.checkStruct(typ, path, encoding, decoding)
+	case reflect.Func:
+		// the decoder accepts only null for the functions.
+		if encoding {
+			c.report(path, &errors.UnsupportedTypeError{Type: typ})
+		}
+	case reflect.Chan, reflect.Complex64, reflect.Complex128, reflect.UnsafePointer:
+		c.report(path, &errors.UnsupportedTypeError{Type: typ})
+	}
+}
+
+func (c *precompileChecker) checkMapKey(key reflect.Type, path string, encoding, decoding bool) {
+	switch key.Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return
+	}
+	ptr := reflect.PtrTo(key)
+	if key.Kind() == reflect.Ptr && !key.Implements(textMarshalerType) && !ptr.Implements(textUnmarshalerType) {
+		c.checkMapKey(key.Elem(), path, encoding, decoding)
+		return
+	}
+	if encoding && !key.Implements(textMarshalerType) && !ptr.Implements(textMarshalerType) ||
+		decoding && !ptr.Implements(textUnmarshalerType) {
+		c.report(path, fmt.Errorf("json: unsupported map key type: %s", key))
+	}
+}
+
+func (c *precompileChecker) checkStruct(typ reflect.Type, path string, encoding, decoding bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if runtime.IsIgnoredStructField(field) {
+			continue
+		}
+		fieldPath := path + "." + field.Name
+		if err := checkStructTag(field); err != nil {
+			c.report(fieldPath, err)
+		}
+		c.check(field.Type, fieldPath, encoding, decoding)
+	}
+}
+
+// checkStructTag checks the json tag of the field that runtime.StructTagFromField parses.
+// It reports only the key and the options that are ignored because they are invalid,
+// and the unknown options are ignored like encoding/json.
+func checkStructTag(field reflect.StructField) error {
+	tag, exists := field.Tag.Lookup("json")
+	if !exists {
+		return nil
+	}
+	opts := strings.Split(tag, ",")
+	if opts[0] != "" && !runtime.IsValidTag(opts[0]) {
+		return fmt.Errorf("json: invalid key %q in the json tag", opts[0])
+	}
+	for _, opt := range opts[1:] {
+		switch {
+		case strings.HasPrefix(opt, "fmt="):
+			if opt != "fmt=f" && opt != "fmt=e" {
+				return fmt.Errorf("json: invalid option %q in the json tag", opt)
+			}
+		case strings.HasPrefix(opt, "precision="):
+			if prec, err := strconv.Atoi(opt[len("precision="):]); err != nil || prec <= 0 || prec > 255 {
+				return fmt.Errorf("json: invalid option %q in the json tag", opt)
+			}
+		}
+	}
+	return nil
+}
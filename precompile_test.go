@@ -0,0 +1,80 @@
+package json_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+)
+
+type precompileServer struct {
+	Host    string            `json:"host"`
+	Port    int               `json:"port,string"`
+	Started time.Time         `json:"started,omitzero"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Next    *precompileServer `json:"next"`
+}
+
+type precompileInvalid struct {
+	Name     string             `json:"name,omitempy"`
+	Inline   string             `json:"inline,inline"`
+	Format   float64            `json:"format,fmt=g"`
+	Events   chan string        `json:"events"`
+	Weights  []complex128       `json:"weights"`
+	Callback func()             `json:"-"`
+	Handler  func()             `json:"handler"`
+	Index    map[[2]int]string  `json:"index"`
+	Ratio    float64            `json:"ratio,precision=0"`
+	Quoted   string             `json:"a\"b"`
+	Servers  []precompileServer `json:"servers"`
+	Nested   *struct {
+		C chan int
+	}
+}
+
+func TestPrecompile(t *testing.T) {
+	assertErr(t, json.Precompile(precompileServer{}, (*precompileServer)(nil), reflect.TypeOf([]time.Duration{}), nil))
+	json.MustPrecompile(map[string]interface{}{})
+	// the unknown options don't change the encoding.
+	json.MustPrecompile(struct {
+		A string `json:"a,inline"`
+		B string `json:"b,omitempy"`
+	}{})
+
+	err := json.Precompile(precompileServer{}, precompileInvalid{})
+	precompileErr, ok := err.(*json.PrecompileError)
+	if !ok {
+		t.Fatalf("expected PrecompileError but got %v", err)
+	}
+	var paths []string
+	for _, p := range precompileErr.Problems {
+		assertEq(t, "type", reflect.TypeOf(precompileInvalid{}), p.Type)
+		paths = append(paths, p.Path)
+	}
+	assertEq(t, "paths",
+		".Format .Events .Weights[] .Handler .Index .Ratio .Quoted .Nested.C",
+		strings.Join(paths, " "),
+	)
+	assertEq(t, "message", `json_test.precompileInvalid.Events: json: unsupported type: chan string`, precompileErr.Problems[1].Error())
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic")
+		}
+	}()
+	json.MustPrecompile(struct{ C complex64 }{})
+}
+
+func TestPrecompileFunc(t *testing.T) {
+	// the functions are reported because the encoder doesn't support them, though the decoder accepts null.
+	type onlyNull struct {
+		F func() `json:"f"`
+	}
+	assertErr(t, json.Precompile(json.RawMessage{}, time.Time{}))
+	err := json.Precompile(onlyNull{})
+	assertNeq(t, "error", nil, err)
+	var v onlyNull
+	assertErr(t, json.Unmarshal([]byte(`{"f":null}`), &v))
+}
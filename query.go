@@ -0,0 +1,49 @@
+package json
+
+import (
+	"context"
+
+	"github.com/goccy/go-json/internal/encoder"
+)
+
+// A FieldQuery selects the object keys to encode, like the sparse fieldsets of JSON:API.
+// The selection is applied to the keys of struct fields, and passes through
+// slices, arrays, maps and pointers to the structs in them.
+//
+// Encoded types are compiled for every distinct selection, and the compiled codes are shared by
+// the queries that select the same keys. The queries must not be modified after they are used.
+type FieldQuery = encoder.FieldQuery
+
+// ParseFieldQuery parses a comma separated list of dotted key paths like "id,name,owner.email".
+// A path selects the whole value of the key, and "owner.email" selects only the email key of owner.
+func ParseFieldQuery(query string) (*FieldQuery, error) {
+	return encoder.ParseFieldQuery(query)
+}
+
+// SelectFields encodes only the object keys selected by query.
+// If query is nil, all keys are encoded.
+//
+// The MarshalJSON(context.Context) method of a value in the selection receives
+// the sub query of the value, so FieldQueryFromContext returns the selection for it.
+func SelectFields(query *FieldQuery) EncodeOptionFunc {
+	return func(opt *EncodeOption) {
+		if query == nil {
+			opt.Flag &^= encoder.FieldQueryOption
+			opt.FieldQuery = nil
+			return
+		}
+		opt.Flag |= encoder.FieldQueryOption
+		opt.FieldQuery = query
+	}
+}
+
+// FieldQueryFromContext returns the field query in ctx.
+// It returns nil if all keys are selected.
+func FieldQueryFromContext(ctx context.Context) *FieldQuery {
+	return encoder.FieldQueryFromContext(ctx)
+}
+
+// SetFieldQueryToContext returns a copy of ctx that holds query.
+func SetFieldQueryToContext(ctx context.Context, query *FieldQuery) context.Context {
+	return encoder.SetFieldQueryToContext(ctx, query)
+}
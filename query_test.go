@@ -0,0 +1,133 @@
+package json_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/goccy/go-json"
+)
+
+type queryUser struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type queryBase struct {
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+type queryProject struct {
+	queryBase
+	ID       int                   `json:"id"`
+	Name     string                `json:"name"`
+	Owner    *queryUser            `json:"owner"`
+	Members  []queryUser           `json:"members"`
+	Roles    map[string]*queryUser `json:"roles"`
+	Extra    interface{}           `json:"extra"`
+	Children []*queryProject       `json:"children,omitempty"`
+}
+
+type queryMarshaler struct {
+	User queryUser
+}
+
+func (m queryMarshaler) MarshalJSON(ctx context.Context) ([]byte, error) {
+	return json.MarshalWithOption(m.User, json.SelectFields(json.FieldQueryFromContext(ctx)))
+}
+
+func TestSelectFields(t *testing.T) {
+	user := &queryUser{ID: 1, Name: "alice", Email: "alice@example.com"}
+	project := &queryProject{
+		queryBase: queryBase{CreatedAt: "c", UpdatedAt: "u"},
+		ID:        10,
+		Name:      "go-json",
+		Owner:     user,
+		Members:   []queryUser{*user},
+		Roles:     map[string]*queryUser{"admin": user},
+		Extra:     queryUser{ID: 2, Name: "bob"},
+		Children:  []*queryProject{{ID: 11, Name: "child", Owner: user}},
+	}
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"id,name", `{"id":10,"name":"go-json"}`},
+		{"id,owner.email", `{"id":10,"owner":{"email":"alice@example.com"}}`},
+		{"owner.email,owner", `{"owner":{"id":1,"name":"alice","email":"alice@example.com"}}`},
+		{"members.name,roles.id", `{"members":[{"name":"alice"}],"roles":{"admin":{"id":1}}}`},
+		{"created_at", `{"created_at":"c"}`},
+		{"extra.name", `{"extra":{"name":"bob"}}`},
+		{"children.id,children.owner.id, id", `{"id":10,"children":[{"id":11,"owner":{"id":1}}]}`},
+		{"unknown", `{}`},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			query, err := json.ParseFieldQuery(test.query)
+			assertErr(t, err)
+			b, err := json.MarshalWithOption(project, json.SelectFields(query))
+			assertErr(t, err)
+			assertEq(t, "selected", test.expected, string(b))
+
+			b, err = json.MarshalIndentWithOption(project, "", "", json.SelectFields(query))
+			assertErr(t, err)
+			var compact []byte
+			compact, err = json.MarshalWithOption(json.RawMessage(b))
+			assertErr(t, err)
+			assertEq(t, "indent", test.expected, string(compact))
+		})
+	}
+	t.Run("slice of structs", func(t *testing.T) {
+		query, err := json.ParseFieldQuery("name")
+		assertErr(t, err)
+		b, err := json.MarshalWithOption([]queryUser{*user, {Name: "bob"}}, json.SelectFields(query))
+		assertErr(t, err)
+		assertEq(t, "slice", `[{"name":"alice"},{"name":"bob"}]`, string(b))
+	})
+	t.Run("marshaler context", func(t *testing.T) {
+		query, err := json.ParseFieldQuery("a.email,b")
+		assertErr(t, err)
+		v := struct {
+			A queryMarshaler `json:"a"`
+			B queryMarshaler `json:"b"`
+			C queryMarshaler `json:"c"`
+		}{A: queryMarshaler{User: *user}, B: queryMarshaler{User: *user}}
+		b, err := json.MarshalWithOption(v, json.SelectFields(query))
+		assertErr(t, err)
+		assertEq(t, "marshaler", `{"a":{"email":"alice@example.com"},"b":{"id":1,"name":"alice","email":"alice@example.com"}}`, string(b))
+
+		b, err = json.Marshal(v)
+		assertErr(t, err)
+		assertEq(t, "without query", `{"a":{"id":1,"name":"alice","email":"alice@example.com"},"b":{"id":1,"name":"alice","email":"alice@example.com"},"c":{"id":0,"name":"","email":""}}`, string(b))
+	})
+	t.Run("parse", func(t *testing.T) {
+		q1, err := json.ParseFieldQuery("id,owner.email,owner.name")
+		assertErr(t, err)
+		q2, err := json.ParseFieldQuery("owner.name, id, owner.email")
+		assertErr(t, err)
+		assertEq(t, "string", "id,owner.email,owner.name", q1.String())
+		assertEq(t, "key", q1.Key(), q2.Key())
+		built := &json.FieldQuery{Fields: []*json.FieldQuery{
+			{Name: "owner", Fields: []*json.FieldQuery{{Name: "name"}, {Name: "email"}}},
+			{Name: "id"},
+		}}
+		assertEq(t, "built key", q1.Key(), built.Key())
+		project := &queryProject{ID: 1, Name: "p", Owner: &queryUser{ID: 2, Name: "bob", Email: "bob@example.com"}}
+		for _, q := range []*json.FieldQuery{q1, q2, built} {
+			b, err := json.MarshalWithOption(project, json.SelectFields(q))
+			assertErr(t, err)
+			assertEq(t, "same selection", `{"id":1,"owner":{"name":"bob","email":"bob@example.com"}}`, string(b))
+		}
+		for _, invalid := range []string{"", "id,", "owner..email", ".id"} {
+			if _, err := json.ParseFieldQuery(invalid); err == nil {
+				t.Fatalf("expected error for %q", invalid)
+			}
+		}
+	})
+	t.Run("nil query", func(t *testing.T) {
+		b, err := json.MarshalWithOption(user, json.SelectFields(nil))
+		assertErr(t, err)
+		assertEq(t, "nil", `{"id":1,"name":"alice","email":"alice@example.com"}`, string(b))
+	})
+}
@@ -0,0 +1,50 @@
+package json
+
+import (
+	"reflect"
+
+	"github.com/goccy/go-json/internal/encoder"
+	"github.com/goccy/go-json/internal/runtime"
+)
+
+// RedactPlaceholder is the string that Redact encodes instead of the redacted fields.
+const RedactPlaceholder = "[REDACTED]"
+
+// Redact encodes the fields tagged with "redact" as RedactPlaceholder.
+// The values whose types have a redactor registered by RegisterRedactor are encoded
+// as the result of the redactor. Without this option, the fields are encoded normally.
+func Redact() EncodeOptionFunc {
+	return RedactWith(RedactPlaceholder)
+}
+
+// RedactWith is like Redact but encodes the redacted fields as placeholder.
+func RedactWith(placeholder string) EncodeOptionFunc {
+	return func(opt *EncodeOption) {
+		opt.Flag |= encoder.RedactOption
+		opt.RedactPlaceholder = placeholder
+		opt.RedactOmit = false
+	}
+}
+
+// RedactOmit is like Redact but omits the fields tagged with "redact".
+// The fields whose types have a redactor are still masked by the redactor.
+func RedactOmit() EncodeOptionFunc {
+	return func(opt *EncodeOption) {
+		opt.Flag |= encoder.RedactOption
+		opt.RedactOmit = true
+	}
+}
+
+// RegisterRedactor registers redactor to mask the values of typ partially,
+// for example to keep only the last four digits of a card number.
+// redactor receives the field value and its result is encoded as a JSON string.
+//
+// With the Redact options, all values of typ are redacted by redactor, like the struct fields
+// even if they aren't tagged with "redact", the elements of the slices and the arrays,
+// the values of the maps and the values in the interfaces. typ is matched exactly, so register *T
+// separately to redact the values of *T.
+// Register redactors before encoding the types that contain typ,
+// because the encoded types are compiled once and cached.
+func RegisterRedactor(typ reflect.Type, redactor func(v interface{}) string) {
+	encoder.RegisterRedactor(runtime.Type2RType(typ), redactor)
+}
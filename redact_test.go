@@ -0,0 +1,136 @@
+package json_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+)
+
+type redactCardNumber string
+
+func init() {
+	json.RegisterRedactor(reflect.TypeOf(redactCardNumber("")), func(v interface{}) string {
+		s := string(v.(redactCardNumber))
+		if len(s) <= 4 {
+			return s
+		}
+		return "************" + s[len(s)-4:]
+	})
+}
+
+func TestRedact(t *testing.T) {
+	type Account struct {
+		Name     string           `json:"name"`
+		Password string           `json:"password,redact"`
+		Token    *string          `json:"token,redact"`
+		Secrets  []int            `json:"secrets,omitempty,redact"`
+		Card     redactCardNumber `json:"card"`
+	}
+	token := "abc"
+	v := &Account{
+		Name:     "alice",
+		Password: "hunter2",
+		Token:    &token,
+		Secrets:  []int{1},
+		Card:     "4111111111111111",
+	}
+	t.Run("default", func(t *testing.T) {
+		b, err := json.Marshal(v)
+		assertErr(t, err)
+		assertEq(t, "redacted", `{"name":"alice","password":"hunter2","token":"abc","secrets":[1],"card":"4111111111111111"}`, string(b))
+	})
+	t.Run("placeholder", func(t *testing.T) {
+		b, err := json.MarshalWithOption(v, json.Redact())
+		assertErr(t, err)
+		assertEq(t, "redacted", `{"name":"alice","password":"[REDACTED]","token":"[REDACTED]","secrets":"[REDACTED]","card":"************1111"}`, string(b))
+
+		b, err = json.MarshalWithOption(Account{}, json.Redact())
+		assertErr(t, err)
+		assertEq(t, "redacted", `{"name":"","password":"[REDACTED]","token":null,"card":""}`, string(b))
+	})
+	t.Run("custom placeholder", func(t *testing.T) {
+		b, err := json.MarshalIndentWithOption(v, "", " ", json.RedactWith("***"))
+		assertErr(t, err)
+		assertEq(t, "redacted", "{\n \"name\": \"alice\",\n \"password\": \"***\",\n \"token\": \"***\",\n \"secrets\": \"***\",\n \"card\": \"************1111\"\n}", string(b))
+	})
+	t.Run("omit", func(t *testing.T) {
+		b, err := json.MarshalWithOption(v, json.RedactOmit())
+		assertErr(t, err)
+		assertEq(t, "redacted", `{"name":"alice","card":"************1111"}`, string(b))
+	})
+	t.Run("interface", func(t *testing.T) {
+		b, err := json.MarshalWithOption([]interface{}{v, map[string]interface{}{"a": *v}}, json.Redact())
+		assertErr(t, err)
+		expected := `{"name":"alice","password":"[REDACTED]","token":"[REDACTED]","secrets":"[REDACTED]","card":"************1111"}`
+		assertEq(t, "redacted", `[`+expected+`,{"a":`+expected+`}]`, string(b))
+	})
+	t.Run("single field", func(t *testing.T) {
+		type T struct {
+			Password string `json:"password,redact"`
+		}
+		b, err := json.MarshalWithOption(&T{Password: "hunter2"}, json.Redact())
+		assertErr(t, err)
+		assertEq(t, "redacted", `{"password":"[REDACTED]"}`, string(b))
+
+		b, err = json.MarshalWithOption((*T)(nil), json.Redact())
+		assertErr(t, err)
+		assertEq(t, "redacted", `null`, string(b))
+	})
+	t.Run("omitempty", func(t *testing.T) {
+		type T struct {
+			Password string            `json:"password,omitempty,redact"`
+			Secrets  map[string]string `json:"secrets,omitempty,redact"`
+			Card     redactCardNumber  `json:"card,omitempty"`
+			Value    interface{}       `json:"value,omitempty,redact"`
+		}
+		b, err := json.MarshalWithOption(&T{Secrets: map[string]string{}}, json.Redact())
+		assertErr(t, err)
+		assertEq(t, "redacted", `{}`, string(b))
+
+		b, err = json.MarshalWithOption(&T{Password: "hunter2", Secrets: map[string]string{"a": "b"}, Card: "4111111111111111", Value: 1}, json.Redact())
+		assertErr(t, err)
+		assertEq(t, "redacted", `{"password":"[REDACTED]","secrets":"[REDACTED]","card":"************1111","value":"[REDACTED]"}`, string(b))
+	})
+	t.Run("registered type in containers", func(t *testing.T) {
+		card := redactCardNumber("4111111111111111")
+		masked := `"************1111"`
+		for _, tc := range []struct {
+			name     string
+			v        interface{}
+			expected string
+		}{
+			{"slice", []redactCardNumber{card}, `[` + masked + `]`},
+			{"array", [2]redactCardNumber{card, "12"}, `[` + masked + `,"12"]`},
+			{"map", map[string]redactCardNumber{"a": card}, `{"a":` + masked + `}`},
+			{"interface", struct {
+				V interface{} `json:"v"`
+			}{V: card}, `{"v":` + masked + `}`},
+			{"slice field", struct {
+				Cards []redactCardNumber `json:"cards"`
+			}{Cards: []redactCardNumber{card}}, `{"cards":[` + masked + `]}`},
+			{"map field", struct {
+				Cards map[string]redactCardNumber `json:"cards"`
+			}{Cards: map[string]redactCardNumber{"a": card}}, `{"cards":{"a":` + masked + `}}`},
+			{"root", card, masked},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				b, err := json.MarshalWithOption(tc.v, json.Redact())
+				assertErr(t, err)
+				assertEq(t, "redacted", tc.expected, string(b))
+
+				b, err = json.MarshalIndentWithOption(tc.v, "", " ", json.Redact())
+				assertErr(t, err)
+				var buf bytes.Buffer
+				assertErr(t, json.Compact(&buf, b))
+				assertEq(t, "redacted", tc.expected, buf.String())
+
+				b, err = json.Marshal(tc.v)
+				assertErr(t, err)
+				assertEq(t, "not redacted", strings.Replace(tc.expected, masked, `"4111111111111111"`, -1), string(b))
+			})
+		}
+	})
+}
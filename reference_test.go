@@ -0,0 +1,137 @@
+package json_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+)
+
+type referenceNode struct {
+	Name     string           `json:"name"`
+	Parent   *referenceNode   `json:"parent,omitempty"`
+	Children []*referenceNode `json:"children,omitempty"`
+}
+
+type referenceEmpty struct{}
+
+type referenceOwner struct {
+	Node *referenceNode `json:"node"`
+}
+
+func newReferenceTree() *referenceNode {
+	root := &referenceNode{Name: "root"}
+	a := &referenceNode{Name: "a", Parent: root}
+	b := &referenceNode{Name: "b", Parent: root}
+	root.Children = []*referenceNode{a, b, a}
+	return root
+}
+
+func TestPreserveReferences(t *testing.T) {
+	t.Run("cycle", func(t *testing.T) {
+		b, err := json.MarshalWithOption(newReferenceTree(), json.PreserveReferences())
+		assertErr(t, err)
+		assertEq(t, "references",
+			`{"$id":"1","name":"root","children":[{"$id":"2","name":"a","parent":{"$ref":"1"}},{"$id":"3","name":"b","parent":{"$ref":"1"}},{"$ref":"2"}]}`,
+			string(b),
+		)
+	})
+	t.Run("indent", func(t *testing.T) {
+		root := &referenceNode{Name: "root"}
+		root.Children = []*referenceNode{root}
+		b, err := json.MarshalIndentWithOption(root, "", "  ", json.PreserveReferences())
+		assertErr(t, err)
+		expected := `{
+  "$id": "1",
+  "name": "root",
+  "children": [
+    {
+      "$ref": "1"
+    }
+  ]
+}`
+		assertEq(t, "references", expected, string(b))
+	})
+	t.Run("empty struct", func(t *testing.T) {
+		e := &referenceEmpty{}
+		v := struct {
+			A *referenceEmpty `json:"a"`
+			B *referenceEmpty `json:"b"`
+			C *referenceEmpty `json:"c"`
+		}{A: e, B: e}
+		b, err := json.MarshalWithOption(v, json.PreserveReferences())
+		assertErr(t, err)
+		assertEq(t, "references", `{"a":{"$id":"1"},"b":{"$ref":"1"},"c":null}`, string(b))
+	})
+	t.Run("struct holding only a pointer", func(t *testing.T) {
+		n := &referenceNode{Name: "n"}
+		b, err := json.MarshalWithOption([]interface{}{referenceOwner{Node: n}, n}, json.PreserveReferences())
+		assertErr(t, err)
+		assertEq(t, "references", `[{"node":{"$id":"1","name":"n"}},{"$ref":"1"}]`, string(b))
+	})
+	t.Run("default", func(t *testing.T) {
+		n := &referenceNode{Name: "n"}
+		b, err := json.Marshal([]*referenceNode{n, n})
+		assertErr(t, err)
+		assertEq(t, "references", `[{"name":"n"},{"name":"n"}]`, string(b))
+	})
+}
+
+func TestDecodePreserveReferences(t *testing.T) {
+	src, err := json.MarshalWithOption(newReferenceTree(), json.PreserveReferences())
+	assertErr(t, err)
+	assertTree := func(t *testing.T, root *referenceNode) {
+		t.Helper()
+		assertEq(t, "children", 3, len(root.Children))
+		a, b := root.Children[0], root.Children[1]
+		assertEq(t, "names", "root,a,b", strings.Join([]string{root.Name, a.Name, b.Name}, ","))
+		if a.Parent != root || b.Parent != root {
+			t.Fatal("failed to restore the references to root")
+		}
+		if root.Children[2] != a {
+			t.Fatal("failed to restore the shared pointer")
+		}
+	}
+	t.Run("unmarshal", func(t *testing.T) {
+		var root referenceNode
+		assertErr(t, json.UnmarshalWithOption(src, &root, json.DecodePreserveReferences()))
+		assertTree(t, &root)
+	})
+	t.Run("unmarshal pointer", func(t *testing.T) {
+		var root *referenceNode
+		assertErr(t, json.UnmarshalWithOption(src, &root, json.DecodePreserveReferences()))
+		assertTree(t, root)
+	})
+	t.Run("stream", func(t *testing.T) {
+		dec := json.NewDecoder(bytes.NewReader(append(append(src, '\n'), src...)))
+		for i := 0; i < 2; i++ {
+			var root referenceNode
+			assertErr(t, dec.DecodeWithOption(&root, json.DecodePreserveReferences()))
+			assertTree(t, &root)
+		}
+	})
+	t.Run("unknown reference", func(t *testing.T) {
+		var v []*referenceNode
+		err := json.UnmarshalWithOption([]byte(`[{"$ref":"1"}]`), &v, json.DecodePreserveReferences())
+		assertNeq(t, "error", nil, err)
+
+		err = json.NewDecoder(strings.NewReader(`[{"$ref":"1"}]`)).DecodeWithOption(&v, json.DecodePreserveReferences())
+		assertNeq(t, "error", nil, err)
+	})
+	t.Run("reference to another type", func(t *testing.T) {
+		var v struct {
+			A *referenceEmpty `json:"a"`
+			B *referenceNode  `json:"b"`
+		}
+		err := json.UnmarshalWithOption([]byte(`{"a":{"$id":"1"},"b":{"$ref":"1"}}`), &v, json.DecodePreserveReferences())
+		assertNeq(t, "error", nil, err)
+	})
+	t.Run("without option", func(t *testing.T) {
+		var root referenceNode
+		assertErr(t, json.Unmarshal(src, &root))
+		if root.Children[0].Parent == &root {
+			t.Fatal("unexpected reference")
+		}
+	})
+}
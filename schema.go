@@ -0,0 +1,498 @@
+package json
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-json/internal/decoder"
+	"github.com/goccy/go-json/internal/errors"
+	"github.com/goccy/go-json/internal/runtime"
+)
+
+// SchemaDialect is the JSON Schema dialect of the documents generated by Schema.
+const SchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// SchemaMarshaler is implemented by types that supply their own JSON Schema to Schema.
+// Types implementing Marshaler should implement it, because Schema can't derive their encoding
+// and describes them as any value otherwise.
+type SchemaMarshaler interface {
+	MarshalJSONSchema() ([]byte, error)
+}
+
+// SchemaOption is the option of Schema.
+type SchemaOption struct {
+	// NamingPolicy converts the keys of the untagged fields like FieldNaming.
+	NamingPolicy *NamingPolicy
+	// TypeSchemas overrides the schemas of the types, like SchemaMarshaler.
+	TypeSchemas map[reflect.Type]RawMessage
+}
+
+type SchemaOptionFunc func(*SchemaOption)
+
+// SchemaValidator validates JSON documents, like *jsonschema.Schema.
+type SchemaValidator interface {
+	Validate(data []byte) error
+}
+
+// ValidateSchema validates the document by schema before it is decoded,
+// so that the value isn't modified by the invalid documents.
+// Decoder validates each value of the stream, and discards the invalid value.
+// The validation is always a separate pass over the document: the validator parses the whole document,
+// and then the decoder reads it again, so the validated documents are read twice.
+func ValidateSchema(schema SchemaValidator) DecodeOptionFunc {
+	return func(opt *DecodeOption) {
+		opt.Flags |= decoder.ValidateOption
+		opt.Validate = schema.Validate
+	}
+}
+
+// SchemaFieldNaming derives the property names of the untagged fields by policy.
+// It should be the same policy as FieldNaming of the encoder.
+func SchemaFieldNaming(policy *NamingPolicy) SchemaOptionFunc {
+	return func(opt *SchemaOption) {
+		opt.NamingPolicy = policy
+	}
+}
+
+// TypeSchema uses schema for typ, for the types that can't implement SchemaMarshaler.
+func TypeSchema(typ reflect.Type, schema RawMessage) SchemaOptionFunc {
+	return func(opt *SchemaOption) {
+		if opt.TypeSchemas == nil {
+			opt.TypeSchemas = map[reflect.Type]RawMessage{}
+		}
+		opt.TypeSchemas[typ] = schema
+	}
+}
+
+var (
+	schemaMarshalerType = reflect.TypeOf((*SchemaMarshaler)(nil)).Elem()
+	marshalerType       = reflect.TypeOf((*Marshaler)(nil)).Elem()
+	marshalerCtxType    = reflect.TypeOf((*MarshalerContext)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// defaultTypeSchemas are the schemas of the types implementing Marshaler in the standard library and this package.
+var defaultTypeSchemas = map[reflect.Type]RawMessage{
+	reflect.TypeOf(time.Time{}):  RawMessage(`{"type":"string","format":"date-time"}`),
+	reflect.TypeOf(Number("")):   RawMessage(`{"type":"number"}`),
+	reflect.TypeOf(RawMessage{}): RawMessage(`{}`),
+	reflect.TypeOf(OrderedMap{}): RawMessage(`{"type":"object"}`),
+}
+
+// Schema returns the JSON Schema ( draft 2020-12 ) of the values of typ encoded by Marshal.
+// The properties of structs are derived by the same rules as the encoder:
+// the keys and options of the tags, the fields promoted from the embedded structs and their conflicts.
+// The properties without "omitempty" or "omitzero" are required, unless they are promoted
+// from the embedded pointers. The named struct types are defined in "$defs".
+func Schema(typ reflect.Type, optFuncs ...SchemaOptionFunc) ([]byte, error) {
+	opt := &SchemaOption{}
+	for _, optFunc := range optFuncs {
+		optFunc(opt)
+	}
+	g := &schemaGenerator{
+		opt:      opt,
+		root:     typ,
+		defNames: map[reflect.Type]string{},
+		usedDefs: map[string]bool{},
+		defs:     NewOrderedMap(),
+		visiting: map[reflect.Type]bool{},
+	}
+	var (
+		body interface{}
+		err  error
+	)
+	if typ.Kind() == reflect.Struct && !g.hasCustomSchema(typ) {
+		body, err = g.structSchema(typ)
+	} else {
+		body, err = g.schema(typ)
+	}
+	if err != nil {
+		return nil, err
+	}
+	root := NewOrderedMap()
+	root.Set("$schema", SchemaDialect)
+	if m, ok := body.(*OrderedMap); ok {
+		for _, item := range m.Items() {
+			root.Set(item.Key, item.Value)
+		}
+	} else {
+		root.Set("allOf", []interface{}{body})
+	}
+	if g.defs.Len() > 0 {
+		root.Set("$defs", g.defs)
+	}
+	return Marshal(root)
+}
+
+type schemaGenerator struct {
+	opt      *SchemaOption
+	root     reflect.Type
+	defNames map[reflect.Type]string
+	usedDefs map[string]bool
+	defs     *OrderedMap
+	// visiting is the struct types whose fields are being promoted, to stop the embedding cycles.
+	visiting map[reflect.Type]bool
+}
+
+// schemaObject returns the schema object of the keys and values in pairs.
+func schemaObject(pairs ...interface{}) *OrderedMap {
+	m := NewOrderedMap()
+	for i := 0; i < len(pairs); i += 2 {
+		m.Set(pairs[i].(string), pairs[i+1])
+	}
+	return m
+}
+
+func (g *schemaGenerator) hasCustomSchema(typ reflect.Type) bool {
+	if _, exists := g.opt.TypeSchemas[typ]; exists {
+		return true
+	}
+	if _, exists := defaultTypeSchemas[typ]; exists {
+		return true
+	}
+	ptr := reflect.PtrTo(typ)
+	return ptr.Implements(schemaMarshalerType) || ptr.Implements(marshalerType) ||
+		ptr.Implements(marshalerCtxType) || ptr.Implements(textMarshalerType)
+}
+
+func (g *schemaGenerator) schema(typ reflect.Type) (interface{}, error) {
+	if typ.Kind() == reflect.Ptr {
+		s, err := g.schema(typ.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return nullableSchema(s), nil
+	}
+	if s, exists := g.opt.TypeSchemas[typ]; exists {
+		return s, nil
+	}
+	if s, exists := defaultTypeSchemas[typ]; exists {
+		return s, nil
+	}
+	if s, ok, err := customSchema(typ); ok || err != nil {
+		return s, err
+	}
+	switch {
+	case implementsMarshaler(typ):
+		// the encoding is unknown.
+		return schemaObject(), nil
+	case typ.Implements(textMarshalerType) || reflect.PtrTo(typ).Implements(textMarshalerType):
+		return schemaObject("type", "string"), nil
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		return schemaObject("type", "boolean"), nil
+	case reflect.Int, reflect.Int64:
+		return schemaObject("type", "integer"), nil
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		bits := uint(typ.Bits())
+		return schemaObject("type", "integer", "minimum", -1<<(bits-1), "maximum", 1<<(bits-1)-1), nil
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		return schemaObject("type", "integer", "minimum", 0), nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return schemaObject("type", "integer", "minimum", 0, "maximum", uint64(1)<<uint(typ.Bits())-1), nil
+	case reflect.Float32, reflect.Float64:
+		return schemaObject("type", "number"), nil
+	case reflect.String:
+		return schemaObject("type", "string"), nil
+	case reflect.Interface:
+		return schemaObject(), nil
+	case reflect.Slice:
+		if isBytesType(typ) {
+			return schemaObject("type", []string{"string", "null"}, "contentEncoding", "base64"), nil
+		}
+		items, err := g.schema(typ.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return schemaObject("type", []string{"array", "null"}, "items", items), nil
+	case reflect.Array:
+		items, err := g.schema(typ.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return schemaObject("type", "array", "items", items, "minItems", typ.Len(), "maxItems", typ.Len()), nil
+	case reflect.Map:
+		if !isSchemaMapKeyType(typ.Key()) {
+			return nil, &errors.UnsupportedTypeError{Type: typ}
+		}
+		values, err := g.schema(typ.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return schemaObject("type", []string{"object", "null"}, "additionalProperties", values), nil
+	case reflect.Struct:
+		if typ.Name() == "" {
+			return g.structSchema(typ)
+		}
+		return g.structRef(typ)
+	}
+	return nil, &errors.UnsupportedTypeError{Type: typ}
+}
+
+// customSchema returns the schema supplied by SchemaMarshaler.
+func customSchema(typ reflect.Type) (interface{}, bool, error) {
+	var m SchemaMarshaler
+	switch {
+	case typ.Implements(schemaMarshalerType):
+		m = reflect.Zero(typ).Interface().(SchemaMarshaler)
+	case reflect.PtrTo(typ).Implements(schemaMarshalerType):
+		m = reflect.New(typ).Interface().(SchemaMarshaler)
+	default:
+		return nil, false, nil
+	}
+	b, err := m.MarshalJSONSchema()
+	if err != nil {
+		return nil, true, errors.ErrMarshaler(typ, err, "MarshalJSONSchema")
+	}
+	if !Valid(b) {
+		return nil, true, fmt.Errorf("json: invalid schema returned by MarshalJSONSchema of %s", typ)
+	}
+	return RawMessage(b), true, nil
+}
+
+func implementsMarshaler(typ reflect.Type) bool {
+	for _, t := range []reflect.Type{typ, reflect.PtrTo(typ)} {
+		if t.Implements(marshalerType) || t.Implements(marshalerCtxType) {
+			return true
+		}
+	}
+	return false
+}
+
+// isBytesType reports whether the slice is encoded as a base64 string.
+func isBytesType(typ reflect.Type) bool {
+	elem := typ.Elem()
+	if elem.Kind() != reflect.Uint8 {
+		return false
+	}
+	ptr := reflect.PtrTo(elem)
+	return !implementsMarshaler(elem) && !elem.Implements(textMarshalerType) && !ptr.Implements(textMarshalerType)
+}
+
+func isSchemaMapKeyType(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return typ.Implements(textMarshalerType) || reflect.PtrTo(typ).Implements(textMarshalerType)
+}
+
+// nullableSchema adds null to the values accepted by s.
+func nullableSchema(s interface{}) interface{} {
+	if m, ok := s.(*OrderedMap); ok {
+		if m.Len() == 0 {
+			return m
+		}
+		switch typ, _ := m.Get("type"); t := typ.(type) {
+		case string:
+			m.Set("type", []string{t, "null"})
+			return m
+		case []string:
+			return m
+		}
+	}
+	return schemaObject("anyOf", []interface{}{s, schemaObject("type", "null")})
+}
+
+// nonNullSchema removes null from the type of s, for the slices and maps of the "nonil" fields.
+func nonNullSchema(s interface{}) interface{} {
+	if m, ok := s.(*OrderedMap); ok {
+		if types, ok := m.Get("type"); ok {
+			if t, ok := types.([]string); ok && len(t) == 2 && t[1] == "null" {
+				m.Set("type", t[0])
+			}
+		}
+	}
+	return s
+}
+
+func (g *schemaGenerator) structRef(typ reflect.Type) (interface{}, error) {
+	if typ == g.root {
+		return schemaObject("$ref", "#"), nil
+	}
+	name, exists := g.defNames[typ]
+	if !exists {
+		name = typ.Name()
+		for i := 2; g.usedDefs[name]; i++ {
+			name = fmt.Sprintf("%s%d", typ.Name(), i)
+		}
+		g.defNames[typ] = name
+		g.usedDefs[name] = true
+		// reserve the position before the definitions referred by the struct.
+		g.defs.Set(name, nil)
+		s, err := g.structSchema(typ)
+		if err != nil {
+			return nil, err
+		}
+		g.defs.Set(name, s)
+	}
+	ref := strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+	return schemaObject("$ref", "#/$defs/"+ref), nil
+}
+
+func (g *schemaGenerator) structSchema(typ reflect.Type) (interface{}, error) {
+	fields, err := g.structFields(typ)
+	if err != nil {
+		return nil, err
+	}
+	properties := NewOrderedMap()
+	required := []string{}
+	for _, f := range fields {
+		properties.Set(f.key, f.schema)
+		if f.required {
+			required = append(required, f.key)
+		}
+	}
+	s := schemaObject("type", "object", "properties", properties)
+	if len(required) > 0 {
+		s.Set("required", required)
+	}
+	return s, nil
+}
+
+type schemaField struct {
+	key      string
+	tagged   bool
+	required bool
+	schema   interface{}
+}
+
+// structFields returns the properties of the struct in the order of the encoder.
+func (g *schemaGenerator) structFields(typ reflect.Type) ([]*schemaField, error) {
+	g.visiting[typ] = true
+	defer delete(g.visiting, typ)
+
+	tags := runtime.StructTags{}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if runtime.IsIgnoredStructField(field) {
+			continue
+		}
+		tags = append(tags, runtime.StructTagFromFieldWithNamingPolicy(field, g.opt.NamingPolicy))
+	}
+	fields := []*schemaField{}
+	promoted := map[string][]*schemaField{}
+	for _, tag := range tags {
+		if embedded, isPtr, ok := flattenedStructType(tag); ok {
+			if g.visiting[embedded] {
+				// recursive definition
+				continue
+			}
+			embeddedFields, err := g.structFields(embedded)
+			if err != nil {
+				return nil, err
+			}
+			for _, f := range embeddedFields {
+				if tags.ExistsKey(f.key) {
+					// the field of the struct hides the promoted field.
+					continue
+				}
+				if isPtr {
+					// the fields aren't encoded if the embedded pointer is nil.
+					f.required = false
+				}
+				fields = append(fields, f)
+				promoted[f.key] = append(promoted[f.key], f)
+			}
+			continue
+		}
+		s, err := g.fieldSchema(tag)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, &schemaField{
+			key:      tag.Key,
+			tagged:   tag.IsTaggedKey,
+			required: !tag.IsOmitEmpty && !tag.IsOmitZero,
+			schema:   s,
+		})
+	}
+
+	// the conflicted promoted fields are removed unless only one of them is tagged.
+	removed := map[*schemaField]bool{}
+	for _, conflicts := range promoted {
+		if len(conflicts) == 1 {
+			continue
+		}
+		tagged := []*schemaField{}
+		for _, f := range conflicts {
+			if f.tagged {
+				tagged = append(tagged, f)
+			} else {
+				removed[f] = true
+			}
+		}
+		for _, f := range tagged {
+			if len(tagged) > 1 {
+				removed[f] = true
+			} else {
+				f.tagged = false
+			}
+		}
+	}
+	if len(removed) == 0 {
+		return fields, nil
+	}
+	filtered := fields[:0]
+	for _, f := range fields {
+		if !removed[f] {
+			filtered = append(filtered, f)
+		}
+	}
+	return filtered, nil
+}
+
+// flattenedStructType returns the struct type whose fields are promoted to the parent.
+func flattenedStructType(tag *runtime.StructTag) (reflect.Type, bool, bool) {
+	if !tag.Field.Anonymous || tag.IsTaggedKey {
+		return nil, false, false
+	}
+	typ := tag.Field.Type
+	isPtr := typ.Kind() == reflect.Ptr
+	if isPtr {
+		typ = typ.Elem()
+	}
+	return typ, isPtr, typ.Kind() == reflect.Struct
+}
+
+func (g *schemaGenerator) fieldSchema(tag *runtime.StructTag) (interface{}, error) {
+	typ := tag.Field.Type
+	if tag.IsString && isQuotableType(typ) {
+		if typ.Kind() == reflect.Ptr {
+			return schemaObject("type", []string{"string", "null"}), nil
+		}
+		return schemaObject("type", "string"), nil
+	}
+	s, err := g.schema(typ)
+	if err != nil {
+		return nil, err
+	}
+	if tag.IsNoNil && (typ.Kind() == reflect.Slice && !isBytesType(typ) || typ.Kind() == reflect.Map) {
+		return nonNullSchema(s), nil
+	}
+	return s, nil
+}
+
+// isQuotableType reports whether the "string" option of the tag encodes the value as a string.
+func isQuotableType(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if implementsMarshaler(typ) || typ.Implements(textMarshalerType) || reflect.PtrTo(typ).Implements(textMarshalerType) {
+		return false
+	}
+	switch typ.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
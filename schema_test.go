@@ -0,0 +1,193 @@
+package json_test
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-json"
+	"github.com/goccy/go-json/jsonschema"
+)
+
+type schemaBase struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type schemaAudit struct {
+	Updated time.Time `json:"updated,omitzero"`
+	Name    string    `json:"name"`
+}
+
+type schemaA struct{ X, Y int }
+
+type schemaB struct {
+	X int
+	Y int `json:"Y"`
+}
+
+type schemaColor int
+
+func (schemaColor) MarshalJSON() ([]byte, error) { return []byte(`"red"`), nil }
+
+func (schemaColor) MarshalJSONSchema() ([]byte, error) {
+	return []byte(`{"enum":["red","green"]}`), nil
+}
+
+type schemaNode struct {
+	Value    string        `json:"value"`
+	Children []*schemaNode `json:"children,omitempty"`
+}
+
+type schemaDoc struct {
+	schemaBase
+	*schemaAudit
+	schemaA
+	schemaB
+	Title    string          `json:"title"`
+	Count    uint8           `json:"count,string"`
+	Ratio    *float64        `json:"ratio,omitempty"`
+	Tags     []string        `json:"tags,nonil"`
+	Data     []byte          `json:"data"`
+	Attrs    map[string]int  `json:"attrs,omitempty"`
+	Color    schemaColor     `json:"color"`
+	Raw      json.RawMessage `json:"raw"`
+	Root     *schemaNode     `json:"root"`
+	Point    struct{ X int } `json:"point"`
+	Pair     [2]bool         `json:"pair"`
+	Any      interface{}     `json:"any"`
+	Skip     string          `json:"-"`
+	unexport string
+}
+
+func TestSchema(t *testing.T) {
+	t.Run("struct", func(t *testing.T) {
+		got, err := json.Schema(reflect.TypeOf(schemaDoc{}))
+		assertErr(t, err)
+		expected := `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{` +
+			`"id":{"type":"integer"},` +
+			`"updated":{"type":"string","format":"date-time"},` +
+			`"Y":{"type":"integer"},` +
+			`"title":{"type":"string"},` +
+			`"count":{"type":"string"},` +
+			`"ratio":{"type":["number","null"]},` +
+			`"tags":{"type":"array","items":{"type":"string"}},` +
+			`"data":{"type":["string","null"],"contentEncoding":"base64"},` +
+			`"attrs":{"type":["object","null"],"additionalProperties":{"type":"integer"}},` +
+			`"color":{"enum":["red","green"]},` +
+			`"raw":{},` +
+			`"root":{"anyOf":[{"$ref":"#/$defs/schemaNode"},{"type":"null"}]},` +
+			`"point":{"type":"object","properties":{"X":{"type":"integer"}},"required":["X"]},` +
+			`"pair":{"type":"array","items":{"type":"boolean"},"minItems":2,"maxItems":2},` +
+			`"any":{}` +
+			`},"required":["id","Y","title","count","tags","data","color","raw","root","point","pair","any"],` +
+			`"$defs":{"schemaNode":{"type":"object","properties":{` +
+			`"value":{"type":"string"},` +
+			`"children":{"type":["array","null"],"items":{"anyOf":[{"$ref":"#/$defs/schemaNode"},{"type":"null"}]}}` +
+			`},"required":["value"]}}}`
+		assertEq(t, "schema", expected, string(got))
+	})
+	t.Run("properties are the keys of the encoder", func(t *testing.T) {
+		ratio := 1.5
+		v := schemaDoc{
+			schemaAudit: &schemaAudit{Updated: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+			Ratio:       &ratio,
+			Attrs:       map[string]int{"a": 1},
+			Raw:         json.RawMessage(`1`),
+		}
+		encoded, err := json.Marshal(v)
+		assertErr(t, err)
+		var members json.OrderedMap
+		assertErr(t, json.Unmarshal(encoded, &members))
+
+		schema, err := json.Schema(reflect.TypeOf(v))
+		assertErr(t, err)
+		var doc struct {
+			Properties json.OrderedMap `json:"properties"`
+		}
+		assertErr(t, json.Unmarshal(schema, &doc))
+		assertEq(t, "keys", strings.Join(members.Keys(), ","), strings.Join(doc.Properties.Keys(), ","))
+	})
+	t.Run("recursive root", func(t *testing.T) {
+		got, err := json.Schema(reflect.TypeOf(&schemaNode{}))
+		assertErr(t, err)
+		assertEq(t, "schema",
+			`{"$schema":"https://json-schema.org/draft/2020-12/schema","anyOf":[{"$ref":"#/$defs/schemaNode"},{"type":"null"}],`+
+				`"$defs":{"schemaNode":{"type":"object","properties":{"value":{"type":"string"},`+
+				`"children":{"type":["array","null"],"items":{"anyOf":[{"$ref":"#/$defs/schemaNode"},{"type":"null"}]}}},"required":["value"]}}}`,
+			string(got),
+		)
+		got, err = json.Schema(reflect.TypeOf(schemaNode{}))
+		assertErr(t, err)
+		assertEq(t, "schema",
+			`{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{"value":{"type":"string"},`+
+				`"children":{"type":["array","null"],"items":{"anyOf":[{"$ref":"#"},{"type":"null"}]}}},"required":["value"]}`,
+			string(got),
+		)
+	})
+	t.Run("options", func(t *testing.T) {
+		type T struct {
+			FirstName string
+			Color     schemaColor
+			Duration  time.Duration `json:"duration"`
+		}
+		got, err := json.Schema(
+			reflect.TypeOf(T{}),
+			json.SchemaFieldNaming(json.SnakeCase),
+			json.TypeSchema(reflect.TypeOf(time.Duration(0)), json.RawMessage(`{"type":"integer","minimum":0}`)),
+		)
+		assertErr(t, err)
+		assertEq(t, "schema",
+			`{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{`+
+				`"first_name":{"type":"string"},"color":{"enum":["red","green"]},"duration":{"type":"integer","minimum":0}},`+
+				`"required":["first_name","color","duration"]}`,
+			string(got),
+		)
+	})
+	t.Run("scalar", func(t *testing.T) {
+		got, err := json.Schema(reflect.TypeOf(int8(0)))
+		assertErr(t, err)
+		assertEq(t, "schema", `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"integer","minimum":-128,"maximum":127}`, string(got))
+		got, err = json.Schema(reflect.TypeOf(schemaColor(0)))
+		assertErr(t, err)
+		assertEq(t, "schema", `{"$schema":"https://json-schema.org/draft/2020-12/schema","allOf":[{"enum":["red","green"]}]}`, string(got))
+	})
+	t.Run("unsupported type", func(t *testing.T) {
+		_, err := json.Schema(reflect.TypeOf(struct{ C chan int }{}))
+		if _, ok := err.(*json.UnsupportedTypeError); !ok {
+			t.Fatalf("expected UnsupportedTypeError but got %v", err)
+		}
+	})
+}
+
+func TestValidateSchema(t *testing.T) {
+	type T struct {
+		A int `json:"a"`
+	}
+	schema := jsonschema.MustCompile([]byte(`{"type":"object","properties":{"a":{"type":"integer","minimum":1}},"required":["a"]}`))
+	t.Run("unmarshal", func(t *testing.T) {
+		var v T
+		assertErr(t, json.UnmarshalWithOption([]byte(`{"a":2}`), &v, json.ValidateSchema(schema)))
+		assertEq(t, "a", 2, v.A)
+
+		err := json.UnmarshalWithOption([]byte(`{"a":0}`), &v, json.ValidateSchema(schema))
+		if _, ok := err.(*jsonschema.ValidationError); !ok {
+			t.Fatalf("expected ValidationError but got %v", err)
+		}
+		assertEq(t, "a", 2, v.A)
+	})
+	t.Run("decoder", func(t *testing.T) {
+		dec := json.NewDecoder(strings.NewReader(`{"a":1} {"b":1} {"a":3}`))
+		var v T
+		assertErr(t, dec.DecodeWithOption(&v, json.ValidateSchema(schema)))
+		assertEq(t, "a", 1, v.A)
+		if err := dec.Decode(&v); err == nil {
+			t.Fatal("expected error")
+		}
+		assertErr(t, dec.Decode(&v))
+		assertEq(t, "a", 3, v.A)
+		assertEq(t, "eof", io.EOF, dec.Decode(&v))
+	})
+}
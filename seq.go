@@ -0,0 +1,170 @@
+package json
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/goccy/go-json/internal/encoder"
+)
+
+// recordSeparator is the RS byte that starts every record of a JSON text sequence ( RFC 7464 ).
+const recordSeparator = 0x1E
+
+// A SequenceError describes an invalid record of a JSON text sequence.
+type SequenceError struct {
+	Record    int  // 1-based record number
+	Truncated bool // the record lacks its trailing LF and may have been cut off
+	Err       error
+}
+
+func (e *SequenceError) Error() string {
+	if e.Truncated {
+		return fmt.Sprintf("json: record %d of JSON text sequence is truncated", e.Record)
+	}
+	return fmt.Sprintf("json: record %d of JSON text sequence: %s", e.Record, e.Err.Error())
+}
+
+// Unwrap returns the underlying error.
+func (e *SequenceError) Unwrap() error { return e.Err }
+
+// A SequenceDecoder reads a JSON text sequence ( RFC 7464, application/json-seq ) from an input stream.
+// Each record starts with an RS (0x1E) byte and ends with an LF.
+//
+// A malformed or truncated record is reported as *SequenceError by Decode;
+// the next call of Decode continues at the next RS.
+type SequenceDecoder struct {
+	// r splits the input into the records at RS before parsing, instead of the buffer of decoder.Stream,
+	// so that a malformed record is skipped without parsing into the next one, like LinesDecoder does with the lines.
+	r      *bufio.Reader
+	record int
+}
+
+// NewSequenceDecoder returns a new decoder that reads a JSON text sequence from r.
+func NewSequenceDecoder(r io.Reader) *SequenceDecoder {
+	return &SequenceDecoder{r: bufio.NewReader(r)}
+}
+
+// Decode reads the next record and stores the decoded value in the value pointed to by v.
+// It returns io.EOF when there are no more records.
+func (d *SequenceDecoder) Decode(v interface{}) error {
+	return d.DecodeWithOption(v)
+}
+
+// DecodeWithOption is like Decode but applies DecodeOption.
+func (d *SequenceDecoder) DecodeWithOption(v interface{}, optFuncs ...DecodeOptionFunc) error {
+	record, err := d.readRecord()
+	if err != nil {
+		return err
+	}
+	if isTruncatedRecord(record) {
+		return &SequenceError{Record: d.record, Truncated: true, Err: io.ErrUnexpectedEOF}
+	}
+	if err := unmarshal(record, v, optFuncs...); err != nil {
+		return &SequenceError{Record: d.record, Err: err}
+	}
+	return nil
+}
+
+// Record returns the number of the record that was read last.
+func (d *SequenceDecoder) Record() int {
+	return d.record
+}
+
+func (d *SequenceDecoder) readRecord() ([]byte, error) {
+	for {
+		c, err := d.r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		if c == recordSeparator {
+			break
+		}
+	}
+	for {
+		record, err := d.r.ReadBytes(recordSeparator)
+		if err == nil {
+			// keep the RS for the next record.
+			if uerr := d.r.UnreadByte(); uerr != nil {
+				return nil, uerr
+			}
+			record = record[:len(record)-1]
+		} else if err != io.EOF {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(record)) == 0 {
+			// consecutive RS bytes don't denote empty records.
+			if err == io.EOF {
+				return nil, io.EOF
+			}
+			if _, err := d.r.ReadByte(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		d.record++
+		return record, nil
+	}
+}
+
+// isTruncatedRecord reports whether record lacks its trailing LF and holds a top-level
+// number, true, false or null, whose truncation can't be detected by parsing.
+func isTruncatedRecord(record []byte) bool {
+	if record[len(record)-1] == '\n' {
+		return false
+	}
+	value := bytes.TrimLeft(record, " \t\r\n")
+	switch value[0] {
+	case '{', '[', '"':
+		return false
+	}
+	switch value[len(value)-1] {
+	case ' ', '\t', '\r':
+		return false
+	}
+	return true
+}
+
+// A SequenceEncoder writes a JSON text sequence ( RFC 7464, application/json-seq ) to an output stream.
+type SequenceEncoder struct {
+	w                 io.Writer
+	enabledHTMLEscape bool
+	buf               []byte
+}
+
+// NewSequenceEncoder returns a new encoder that writes a JSON text sequence to w.
+func NewSequenceEncoder(w io.Writer) *SequenceEncoder {
+	return &SequenceEncoder{w: w, enabledHTMLEscape: true}
+}
+
+// SetEscapeHTML specifies whether problematic HTML characters should be escaped inside JSON quoted strings.
+func (e *SequenceEncoder) SetEscapeHTML(on bool) {
+	e.enabledHTMLEscape = on
+}
+
+// Encode writes the JSON encoding of v as a record: RS, the compact JSON text and LF.
+func (e *SequenceEncoder) Encode(v interface{}) error {
+	return e.EncodeWithOption(v)
+}
+
+// EncodeWithOption call Encode with EncodeOption.
+func (e *SequenceEncoder) EncodeWithOption(v interface{}, optFuncs ...EncodeOptionFunc) error {
+	if !e.enabledHTMLEscape {
+		optFuncs = append(optFuncs, func(opt *EncodeOption) {
+			opt.Flag &^= encoder.HTMLEscapeOption
+		})
+	}
+	b, err := marshal(v, optFuncs...)
+	if err != nil {
+		return err
+	}
+	buf := append(e.buf[:0], recordSeparator)
+	buf = append(buf, b...)
+	buf = append(buf, '\n')
+	e.buf = buf
+	if _, err := e.w.Write(buf); err != nil {
+		return err
+	}
+	return nil
+}
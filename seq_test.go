@@ -0,0 +1,62 @@
+package json_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+)
+
+func TestSequenceDecoder(t *testing.T) {
+	src := "\x1e{\"a\":1}\n\x1e\x1e[1,2]\n\x1e123\x1e{\"a\":\n\x1e\"s\"\n\x1e456"
+	dec := json.NewSequenceDecoder(strings.NewReader(src))
+	type result struct {
+		value     interface{}
+		truncated bool
+		failed    bool
+	}
+	var results []result
+	for {
+		var v interface{}
+		err := dec.Decode(&v)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			seqErr, ok := err.(*json.SequenceError)
+			if !ok {
+				t.Fatalf("unexpected error type %T", err)
+			}
+			results = append(results, result{truncated: seqErr.Truncated, failed: true})
+			continue
+		}
+		results = append(results, result{value: v})
+	}
+	assertEq(t, "records", 6, len(results))
+	assertEq(t, "record count", 6, dec.Record())
+	assertEq(t, "object", float64(1), results[0].value.(map[string]interface{})["a"])
+	assertEq(t, "array", 2, len(results[1].value.([]interface{})))
+	assertEq(t, "truncated number", true, results[2].truncated)
+	assertEq(t, "broken object", true, results[3].failed)
+	assertEq(t, "broken object isn't truncated", false, results[3].truncated)
+	assertEq(t, "string", "s", results[4].value)
+	assertEq(t, "truncated last number", true, results[5].truncated)
+}
+
+func TestSequenceEncoder(t *testing.T) {
+	var buf bytes.Buffer
+	enc := json.NewSequenceEncoder(&buf)
+	assertErr(t, enc.Encode(struct {
+		A string
+	}{A: "<a>"}))
+	enc.SetEscapeHTML(false)
+	assertErr(t, enc.Encode([]string{"<b>"}))
+	assertEq(t, "sequence", "\x1e{\"A\":\"\\u003ca\\u003e\"}\n\x1e[\"<b>\"]\n", buf.String())
+
+	dec := json.NewSequenceDecoder(&buf)
+	var v struct{ A string }
+	assertErr(t, dec.Decode(&v))
+	assertEq(t, "roundtrip", "<a>", v.A)
+}
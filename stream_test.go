@@ -14,6 +14,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strconv"
 
 	"strings"
 	"testing"
@@ -502,3 +503,220 @@ func TestGzipStreaming(t *testing.T) {
 		t.Fatalf("Unexpected error: %v", err)
 	}
 }
+
+func TestDecoderEnterArray(t *testing.T) {
+	type elem struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	var src bytes.Buffer
+	src.WriteString("[")
+	for i := 0; i < 3000; i++ {
+		if i > 0 {
+			src.WriteString(",\n")
+		}
+		src.WriteString(`{"id":` + strconv.Itoa(i) + `,"name":"` + strings.Repeat("x", i%100) + `"}`)
+	}
+	src.WriteString("] 10")
+
+	dec := json.NewDecoder(&src)
+	if err := dec.EnterArray(); err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for dec.More() {
+		var e elem
+		if err := dec.Decode(&e); err != nil {
+			t.Fatal(err)
+		}
+		if e.ID != n || len(e.Name) != n%100 {
+			t.Fatalf("unexpected element %d: %+v", n, e)
+		}
+		n++
+	}
+	if err := dec.Leave(); err != nil {
+		t.Fatal(err)
+	}
+	assertEq(t, "element count", 3000, n)
+	var next int
+	if err := dec.Decode(&next); err != nil {
+		t.Fatal(err)
+	}
+	assertEq(t, "next value", 10, next)
+}
+
+func TestDecoderEnterObject(t *testing.T) {
+	dec := json.NewDecoder(strings.NewReader(`{"a": 1, "b": {"c": [1, 2]}, "d": "x"}`))
+	if err := dec.EnterObject(); err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for dec.More() {
+		key, err := dec.Key()
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, key)
+		if key == "b" {
+			if err := dec.Skip(); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := dec.Leave(); err != nil {
+		t.Fatal(err)
+	}
+	assertEq(t, "keys", "a,b,d", strings.Join(keys, ","))
+	if err := dec.EnterArray(); err != io.EOF {
+		t.Fatalf("expected io.EOF but got %v", err)
+	}
+}
+
+func TestDecoderElements(t *testing.T) {
+	src := `{"meta": {"count": 3}, "data": {"items": [{"v": 1}, {"v": 2}, {"v": 3}], "tail": [1, 2]}, "x": true}
+[10, 20]`
+	dec := json.NewDecoder(strings.NewReader(src))
+	var sum int
+	if err := dec.Elements("/data/items", func(dec *json.Decoder) error {
+		var v struct{ V int }
+		if err := dec.Decode(&v); err != nil {
+			return err
+		}
+		sum += v.V
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	assertEq(t, "sum", 6, sum)
+
+	var keys []string
+	if err := dec.Elements("", func(dec *json.Decoder) error {
+		keys = append(keys, strconv.Itoa(len(keys)))
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	assertEq(t, "skipped elements", "0,1", strings.Join(keys, ","))
+
+	dec = json.NewDecoder(strings.NewReader(`{"a~b": {"x": 1, "y": 2}}`))
+	var members []string
+	if err := dec.Elements("/a~0b", func(dec *json.Decoder) error {
+		members = append(members, dec.ElementKey())
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	assertEq(t, "members", "x,y", strings.Join(members, ","))
+
+	dec = json.NewDecoder(strings.NewReader(`{"a": 1}`))
+	if err := dec.Elements("/b", func(*json.Decoder) error { return nil }); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestDecoderContainerSeparators(t *testing.T) {
+	for _, src := range []string{`[1 2 3]`, `[,1]`, `[1,,2]`, `[1,]`, `[[1] [2]]`} {
+		dec := json.NewDecoder(strings.NewReader(src))
+		err := dec.Elements("", func(dec *json.Decoder) error {
+			var v interface{}
+			return dec.Decode(&v)
+		})
+		if _, ok := err.(*json.SyntaxError); !ok {
+			t.Errorf("%s: expected *json.SyntaxError but got %v", src, err)
+		}
+	}
+	for _, src := range []string{`{"a":1 "b":2}`, `{,"a":1}`, `{"a":1,}`, `{"a":{} "b":2}`} {
+		dec := json.NewDecoder(strings.NewReader(src))
+		err := dec.Elements("", func(*json.Decoder) error { return nil })
+		if _, ok := err.(*json.SyntaxError); !ok {
+			t.Errorf("%s: expected *json.SyntaxError but got %v", src, err)
+		}
+	}
+
+	dec := json.NewDecoder(strings.NewReader(`[[1, 2], {"a": [3]}, 4] [5]`))
+	var n int
+	if err := dec.Elements("", func(dec *json.Decoder) error {
+		n++
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	assertEq(t, "element count", 3, n)
+	var next []int
+	if err := dec.Decode(&next); err != nil {
+		t.Fatal(err)
+	}
+	assertEq(t, "next value", 5, next[0])
+}
+
+type countingWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+func TestEncoderFlushThreshold(t *testing.T) {
+	type elem struct {
+		ID    int               `json:"id"`
+		Name  string            `json:"name"`
+		Attrs map[string]string `json:"attrs,omitempty"`
+		Tags  []string          `json:"tags"`
+		Any   interface{}       `json:"any"`
+	}
+	v := make([]elem, 2000)
+	for i := range v {
+		v[i] = elem{ID: i, Name: strings.Repeat("n", i%50), Tags: []string{"a", "b"}, Any: []int{i}}
+		if i%100 == 0 {
+			v[i].Attrs = map[string]string{"z": "1", "a": "2", "m": strings.Repeat("x", 300)}
+		}
+	}
+	for _, tc := range []struct {
+		name   string
+		indent bool
+		opts   []json.EncodeOptionFunc
+	}{
+		{name: "compact"},
+		{name: "indent", indent: true},
+		{name: "color", opts: []json.EncodeOptionFunc{json.Colorize(json.DefaultColorScheme)}},
+		{name: "color indent", indent: true, opts: []json.EncodeOptionFunc{json.Colorize(json.DefaultColorScheme)}},
+		{name: "unordered map", opts: []json.EncodeOptionFunc{json.UnorderedMap()}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var expected bytes.Buffer
+			enc := json.NewEncoder(&expected)
+			if tc.indent {
+				enc.SetIndent("", "  ")
+			}
+			if err := enc.EncodeWithOption(v, tc.opts...); err != nil {
+				t.Fatal(err)
+			}
+
+			var got countingWriter
+			enc = json.NewEncoder(&got)
+			enc.SetFlushThreshold(4096)
+			if tc.indent {
+				enc.SetIndent("", "  ")
+			}
+			if err := enc.EncodeWithOption(v, tc.opts...); err != nil {
+				t.Fatal(err)
+			}
+			if got.writes < 10 {
+				t.Fatalf("expected incremental writes but got %d writes", got.writes)
+			}
+			if tc.name == "unordered map" {
+				assertEq(t, "length", expected.Len(), got.Len())
+				return
+			}
+			assertEq(t, "flushed output", expected.String(), got.String())
+		})
+	}
+}
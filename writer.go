@@ -0,0 +1,355 @@
+package json
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/goccy/go-json/internal/encoder"
+)
+
+// writerFlushSize is the buffered size at which Writer writes to the underlying io.Writer.
+const writerFlushSize = 4096
+
+type writerScopeKind uint8
+
+const (
+	writerScopeObject writerScopeKind = iota
+	writerScopeArray
+)
+
+type writerScope struct {
+	kind      writerScopeKind
+	count     int
+	afterKey  bool
+	keyNeeded bool
+}
+
+// A Writer writes a JSON text token by token to an output stream.
+// It inserts commas and colons, tracks nesting and rejects calls
+// that would produce malformed JSON.
+// Each completed top-level value is followed by a newline character, like Encoder.
+//
+// Writes are buffered; call Flush (or complete a top-level value) to
+// make sure all data has been passed to the underlying io.Writer.
+type Writer struct {
+	w         io.Writer
+	ctx       *encoder.RuntimeContext
+	buf       []byte
+	scopes    []writerScope
+	err       error
+	prefix    string
+	indentStr string
+}
+
+// NewWriter returns a new Writer that writes to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{
+		w: w,
+		ctx: &encoder.RuntimeContext{
+			Option: &encoder.Option{Flag: encoder.HTMLEscapeOption},
+		},
+		buf: make([]byte, 0, writerFlushSize),
+	}
+}
+
+// SetEscapeHTML specifies whether problematic HTML characters should be escaped inside JSON quoted strings.
+func (w *Writer) SetEscapeHTML(on bool) {
+	if on {
+		w.ctx.Option.Flag |= encoder.HTMLEscapeOption
+	} else {
+		w.ctx.Option.Flag &^= encoder.HTMLEscapeOption
+	}
+}
+
+// SetIndent instructs the writer to format each subsequent token as if indented by the package-level function Indent(dst, src, prefix, indent).
+// Calling SetIndent("", "") disables indentation.
+func (w *Writer) SetIndent(prefix, indent string) {
+	w.prefix = prefix
+	w.indentStr = indent
+}
+
+func (w *Writer) enabledIndent() bool {
+	return w.prefix != "" || w.indentStr != ""
+}
+
+// Depth returns the number of objects and arrays that are currently open.
+func (w *Writer) Depth() int {
+	return len(w.scopes)
+}
+
+// BeginObject writes '{' and opens a new object scope.
+func (w *Writer) BeginObject() error {
+	if err := w.beforeValue(); err != nil {
+		return err
+	}
+	w.buf = append(w.buf, '{')
+	w.scopes = append(w.scopes, writerScope{kind: writerScopeObject, keyNeeded: true})
+	return nil
+}
+
+// BeginArray writes '[' and opens a new array scope.
+func (w *Writer) BeginArray() error {
+	if err := w.beforeValue(); err != nil {
+		return err
+	}
+	w.buf = append(w.buf, '[')
+	w.scopes = append(w.scopes, writerScope{kind: writerScopeArray})
+	return nil
+}
+
+// End closes the innermost object or array.
+func (w *Writer) End() error {
+	if w.err != nil {
+		return w.err
+	}
+	if len(w.scopes) == 0 {
+		return w.fail("json: Writer.End called without open object or array")
+	}
+	scope := &w.scopes[len(w.scopes)-1]
+	if scope.afterKey {
+		return w.fail("json: Writer.End called after Key without value")
+	}
+	w.scopes = w.scopes[:len(w.scopes)-1]
+	if scope.count > 0 && w.enabledIndent() {
+		w.appendNewline()
+	}
+	if scope.kind == writerScopeObject {
+		w.buf = append(w.buf, '}')
+	} else {
+		w.buf = append(w.buf, ']')
+	}
+	return w.afterValue()
+}
+
+// Key writes an object key. It must be followed by exactly one value.
+func (w *Writer) Key(k string) error {
+	if w.err != nil {
+		return w.err
+	}
+	if len(w.scopes) == 0 || w.scopes[len(w.scopes)-1].kind != writerScopeObject {
+		return w.fail("json: Writer.Key called outside of object")
+	}
+	scope := &w.scopes[len(w.scopes)-1]
+	if !scope.keyNeeded {
+		return w.fail("json: Writer.Key called twice without value")
+	}
+	if scope.count > 0 {
+		w.buf = append(w.buf, ',')
+	}
+	if w.enabledIndent() {
+		w.appendNewline()
+	}
+	w.buf = encoder.AppendString(w.ctx, w.buf, k)
+	w.buf = append(w.buf, ':')
+	if w.enabledIndent() {
+		w.buf = append(w.buf, ' ')
+	}
+	scope.keyNeeded = false
+	scope.afterKey = true
+	return nil
+}
+
+// String writes s as a JSON string.
+func (w *Writer) String(s string) error {
+	if err := w.beforeValue(); err != nil {
+		return err
+	}
+	w.buf = encoder.AppendString(w.ctx, w.buf, s)
+	return w.afterValue()
+}
+
+// Int writes v as a JSON number.
+func (w *Writer) Int(v int64) error {
+	if err := w.beforeValue(); err != nil {
+		return err
+	}
+	w.buf = strconv.AppendInt(w.buf, v, 10)
+	return w.afterValue()
+}
+
+// Uint writes v as a JSON number.
+func (w *Writer) Uint(v uint64) error {
+	if err := w.beforeValue(); err != nil {
+		return err
+	}
+	w.buf = strconv.AppendUint(w.buf, v, 10)
+	return w.afterValue()
+}
+
+// Float writes v as a JSON number using the same formatting as Marshal.
+// NaN and infinities are rejected with an UnsupportedValueError.
+func (w *Writer) Float(v float64) error {
+	if math.IsInf(v, 0) || math.IsNaN(v) {
+		return w.setErr(encoder.ErrUnsupportedFloat(v))
+	}
+	if err := w.beforeValue(); err != nil {
+		return err
+	}
+	w.buf = encoder.AppendFloat64(w.ctx, w.buf, v)
+	return w.afterValue()
+}
+
+// Bool writes v as a JSON boolean.
+func (w *Writer) Bool(v bool) error {
+	if err := w.beforeValue(); err != nil {
+		return err
+	}
+	w.buf = encoder.AppendBool(w.ctx, w.buf, v)
+	return w.afterValue()
+}
+
+// Null writes a JSON null.
+func (w *Writer) Null() error {
+	if err := w.beforeValue(); err != nil {
+		return err
+	}
+	w.buf = encoder.AppendNull(w.ctx, w.buf)
+	return w.afterValue()
+}
+
+// Raw writes an already encoded JSON value.
+// raw is validated and compacted (or re-indented when indentation is enabled).
+func (w *Writer) Raw(raw []byte) error {
+	if w.err != nil {
+		return w.err
+	}
+	var formatted bytes.Buffer
+	if w.enabledIndent() {
+		if err := encoder.Indent(&formatted, raw, w.valuePrefix(), w.indentStr); err != nil {
+			return w.setErr(err)
+		}
+	} else {
+		if err := encoder.Compact(&formatted, raw, false); err != nil {
+			return w.setErr(err)
+		}
+	}
+	if err := w.beforeValue(); err != nil {
+		return err
+	}
+	w.buf = append(w.buf, bytes.TrimRight(formatted.Bytes(), " \t\r\n")...)
+	return w.afterValue()
+}
+
+// Value writes the JSON encoding of v as produced by Marshal.
+func (w *Writer) Value(v interface{}) error {
+	if w.err != nil {
+		return w.err
+	}
+	var (
+		b   []byte
+		err error
+	)
+	escape := func(opt *EncodeOption) {
+		if (w.ctx.Option.Flag & encoder.HTMLEscapeOption) == 0 {
+			opt.Flag &^= encoder.HTMLEscapeOption
+		}
+	}
+	if w.enabledIndent() {
+		b, err = MarshalIndentWithOption(v, w.valuePrefix(), w.indentStr, escape)
+	} else {
+		b, err = MarshalWithOption(v, escape)
+	}
+	if err != nil {
+		return w.setErr(err)
+	}
+	if err := w.beforeValue(); err != nil {
+		return err
+	}
+	w.buf = append(w.buf, b...)
+	return w.afterValue()
+}
+
+// Flush writes any buffered data to the underlying io.Writer.
+func (w *Writer) Flush() error {
+	if w.err != nil {
+		return w.err
+	}
+	if len(w.buf) == 0 {
+		return nil
+	}
+	if _, err := w.w.Write(w.buf); err != nil {
+		return w.setErr(err)
+	}
+	w.buf = w.buf[:0]
+	return nil
+}
+
+// Close checks that every object and array has been closed and flushes buffered data.
+func (w *Writer) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	if len(w.scopes) != 0 {
+		return w.fail("json: Writer.Close called with unclosed object or array")
+	}
+	return w.Flush()
+}
+
+func (w *Writer) beforeValue() error {
+	if w.err != nil {
+		return w.err
+	}
+	if len(w.scopes) == 0 {
+		return nil
+	}
+	scope := &w.scopes[len(w.scopes)-1]
+	if scope.kind == writerScopeObject {
+		if !scope.afterKey {
+			return w.fail("json: Writer value in object must be preceded by Key")
+		}
+		return nil
+	}
+	if scope.count > 0 {
+		w.buf = append(w.buf, ',')
+	}
+	if w.enabledIndent() {
+		w.appendNewline()
+	}
+	return nil
+}
+
+func (w *Writer) afterValue() error {
+	if len(w.scopes) == 0 {
+		w.buf = append(w.buf, '\n')
+		return w.Flush()
+	}
+	scope := &w.scopes[len(w.scopes)-1]
+	scope.count++
+	if scope.kind == writerScopeObject {
+		scope.afterKey = false
+		scope.keyNeeded = true
+	}
+	if len(w.buf) >= writerFlushSize {
+		return w.Flush()
+	}
+	return nil
+}
+
+func (w *Writer) appendNewline() {
+	w.buf = append(w.buf, '\n')
+	w.buf = append(w.buf, w.prefix...)
+	for i := 0; i < len(w.scopes); i++ {
+		w.buf = append(w.buf, w.indentStr...)
+	}
+}
+
+// valuePrefix returns the prefix to indent a nested value at the current depth.
+func (w *Writer) valuePrefix() string {
+	prefix := w.prefix
+	for i := 0; i < len(w.scopes); i++ {
+		prefix += w.indentStr
+	}
+	return prefix
+}
+
+func (w *Writer) fail(msg string) error {
+	return w.setErr(errors.New(msg))
+}
+
+func (w *Writer) setErr(err error) error {
+	w.err = err
+	return err
+}
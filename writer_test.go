@@ -0,0 +1,103 @@
+package json_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/goccy/go-json"
+)
+
+func TestWriter(t *testing.T) {
+	t.Run("compact", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := json.NewWriter(&buf)
+		assertErr(t, w.BeginObject())
+		assertErr(t, w.Key("id"))
+		assertErr(t, w.Int(-10))
+		assertErr(t, w.Key("name"))
+		assertErr(t, w.String("<go>"))
+		assertErr(t, w.Key("values"))
+		assertErr(t, w.BeginArray())
+		assertErr(t, w.Float(1.5))
+		assertErr(t, w.Uint(2))
+		assertErr(t, w.Bool(true))
+		assertErr(t, w.Null())
+		assertErr(t, w.Raw([]byte(` { "a" : [ 1 , 2 ] } `)))
+		assertErr(t, w.Value(struct{ A int }{A: 1}))
+		assertErr(t, w.End())
+		assertErr(t, w.Key("empty"))
+		assertErr(t, w.BeginObject())
+		assertErr(t, w.End())
+		assertErr(t, w.End())
+		assertErr(t, w.Close())
+		expected := `{"id":-10,"name":"\u003cgo\u003e","values":[1.5,2,true,null,{"a":[1,2]},{"A":1}],"empty":{}}` + "\n"
+		assertEq(t, "writer", expected, buf.String())
+	})
+	t.Run("indent", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := json.NewWriter(&buf)
+		w.SetIndent("", "  ")
+		w.SetEscapeHTML(false)
+		assertErr(t, w.BeginObject())
+		assertErr(t, w.Key("a"))
+		assertErr(t, w.BeginArray())
+		assertErr(t, w.String("<x>"))
+		assertErr(t, w.Raw([]byte(`{"b":1}`)))
+		assertErr(t, w.Value(map[string]int{"c": 2}))
+		assertErr(t, w.End())
+		assertErr(t, w.Key("e"))
+		assertErr(t, w.BeginArray())
+		assertErr(t, w.End())
+		assertErr(t, w.End())
+		expected := `{
+  "a": [
+    "<x>",
+    {
+      "b": 1
+    },
+    {
+      "c": 2
+    }
+  ],
+  "e": []
+}
+`
+		assertEq(t, "writer", expected, buf.String())
+	})
+	t.Run("ill-formed", func(t *testing.T) {
+		w := json.NewWriter(&bytes.Buffer{})
+		assertErr(t, w.BeginObject())
+		if err := w.String("missing key"); err == nil {
+			t.Fatal("expected error")
+		}
+		if err := w.End(); err == nil {
+			t.Fatal("expected sticky error")
+		}
+		w = json.NewWriter(&bytes.Buffer{})
+		if err := w.Key("k"); err == nil {
+			t.Fatal("expected error")
+		}
+		w = json.NewWriter(&bytes.Buffer{})
+		assertErr(t, w.BeginArray())
+		if err := w.Close(); err == nil {
+			t.Fatal("expected error")
+		}
+	})
+	t.Run("sticky value error", func(t *testing.T) {
+		w := json.NewWriter(&bytes.Buffer{})
+		assertErr(t, w.BeginArray())
+		if err := w.Raw([]byte(`[1,`)); err == nil {
+			t.Fatal("expected error")
+		}
+		if err := w.Int(1); err == nil {
+			t.Fatal("expected sticky error after Raw")
+		}
+		w = json.NewWriter(&bytes.Buffer{})
+		if err := w.Value(make(chan int)); err == nil {
+			t.Fatal("expected error")
+		}
+		if err := w.Close(); err == nil {
+			t.Fatal("expected sticky error after Value")
+		}
+	})
+}